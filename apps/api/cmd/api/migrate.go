@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the pgx database/sql driver
+
+	"github.com/yourorg/nous/internal/database"
+	"github.com/yourorg/nous/internal/store/sqlite"
+)
+
+// migrateUsage documents the migrate subcommand
+const migrateUsage = `usage: nous [-store=postgres|sqlite] migrate <command>
+
+commands:
+  up             apply all pending migrations
+  down [N|all]   roll back the last N migrations (default 1), or all of them
+  status         list migrations and whether each is applied
+  version        print the applied migration version
+  force V        set the version to V without running migrations, clearing
+                 the dirty flag (V=-1 means none applied)`
+
+// runMigrate runs a `nous migrate` subcommand against the backend's database
+func runMigrate(ctx context.Context, backend, databaseURL string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate command\n\n%s", migrateUsage)
+	}
+
+	m, err := openMigrator(ctx, backend, databaseURL)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	switch command := args[0]; command {
+	case "up":
+		return m.Up()
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if args[1] == "all" {
+				steps = 0
+			} else if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of migrations %q", args[1])
+			}
+		}
+		return m.Down(steps)
+
+	case "status":
+		statuses, err := m.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied"
+			}
+			fmt.Printf("%-8s %d %s\n", state, status.Version, status.Name)
+		}
+		return printVersion(m)
+
+	case "version":
+		return printVersion(m)
+
+	case "force":
+		if len(args) < 2 {
+			return fmt.Errorf("missing version to force\n\n%s", migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		if err := m.Force(version); err != nil {
+			return err
+		}
+		return printVersion(m)
+
+	default:
+		return fmt.Errorf("unknown migrate command %q\n\n%s", command, migrateUsage)
+	}
+}
+
+// printVersion prints the applied migration version
+func printVersion(m *database.Migrator) error {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return fmt.Errorf("failed to read migration version: %w", err)
+	}
+
+	switch {
+	case version == 0:
+		fmt.Println("No migrations applied")
+	case dirty:
+		fmt.Printf("Version %d (dirty: the last migration failed, fix it and run force)\n", version)
+	default:
+		fmt.Printf("Version %d\n", version)
+	}
+	return nil
+}
+
+// openMigrator opens the backend's database for running migrations
+func openMigrator(ctx context.Context, backend, databaseURL string) (*database.Migrator, error) {
+	switch backend {
+	case "postgres":
+		db, err := sql.Open("pgx", databaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to database: %w", err)
+		}
+		if err := db.PingContext(ctx); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to connect to database: %w", err)
+		}
+
+		m, err := database.NewPostgresMigrator(db)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		return m, nil
+
+	case "sqlite":
+		path, ok := sqlite.PathFromURL(databaseURL)
+		if !ok {
+			return nil, fmt.Errorf("DATABASE_URL must be %s<path> for the sqlite store", sqlite.URLScheme)
+		}
+		db, err := sqlite.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open database: %w", err)
+		}
+
+		m, err := database.NewSQLiteMigrator(db)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		return m, nil
+
+	case "memory":
+		return nil, fmt.Errorf("the memory store has no migrations")
+
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q (want postgres or sqlite)", backend)
+	}
+}
+
+// migratePostgres applies pending migrations at startup, or only checks
+// the schema when auto-migrate is disabled
+func migratePostgres(ctx context.Context, db *pgxpool.Pool, autoMigrate bool) error {
+	if autoMigrate {
+		if err := database.Migrate(ctx, db); err != nil {
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
+		return nil
+	}
+
+	sqlDB, err := database.OpenPostgres(ctx, db)
+	if err != nil {
+		return err
+	}
+	m, err := database.NewPostgresMigrator(sqlDB)
+	if err != nil {
+		sqlDB.Close()
+		return err
+	}
+	defer m.Close()
+
+	return checkSchema(m)
+}
+
+// migrateSQLite is migratePostgres for the SQLite store
+func migrateSQLite(db *sql.DB, autoMigrate bool) error {
+	if autoMigrate {
+		if err := database.MigrateSQLite(db); err != nil {
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
+		return nil
+	}
+
+	// Not closed: that would close the store's database
+	m, err := database.NewSQLiteMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	return checkSchema(m)
+}
+
+// checkSchema is run at startup when auto-migrate is disabled. It refuses
+// a database left dirty by a failed migration and warns when migrations
+// are pending.
+func checkSchema(m *database.Migrator) error {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return fmt.Errorf("failed to read migration version: %w", err)
+	}
+	if dirty {
+		return fmt.Errorf("database is dirty at version %d: fix the failed migration and run `nous migrate force`", version)
+	}
+
+	pending, err := m.Pending()
+	if err != nil {
+		return err
+	}
+	if pending > 0 {
+		log.Printf("Auto-migrate disabled: %d migrations pending, run `nous migrate up`", pending)
+	}
+	return nil
+}
@@ -60,7 +60,7 @@ export default function () {
   const response = http.post(`${API_URL}/events`, payload, params);
 
   check(response, {
-    'status is 201': (r) => r.status === 201,
+    'status is 202': (r) => r.status === 202,
     'response time < 500ms': (r) => r.timings.duration < 500,
   });
 
@@ -87,7 +87,7 @@ send_agent_events() {
         
         local http_code=$(echo "$response" | tail -n1)
         
-        if [ "$http_code" = "201" ]; then
+        if [ "$http_code" = "202" ]; then
             echo -e "${GREEN}Agent $agent_id: Event $i sent ($tool_name, ${duration}ms, $status)${NC}"
         else
             echo -e "${RED}Agent $agent_id: Event $i failed (HTTP $http_code)${NC}"
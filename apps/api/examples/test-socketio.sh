@@ -66,7 +66,7 @@ for i in {1..5}; do
     HTTP_CODE=$(echo "$RESPONSE" | tail -n1)
     BODY=$(echo "$RESPONSE" | head -n-1)
     
-    if [ "$HTTP_CODE" -eq 201 ]; then
+    if [ "$HTTP_CODE" -eq 202 ]; then
         echo -e "${GREEN}✅ Event $i sent successfully (${TOOL_NAME} - ${STATUS})${NC}"
     else
         echo -e "${RED}❌ Event $i failed: HTTP $HTTP_CODE${NC}"
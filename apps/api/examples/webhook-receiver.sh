@@ -0,0 +1,53 @@
+#!/bin/bash
+
+# Local stand-in for an incident tool receiving Nous webhooks
+# Prints every delivery, checks its X-Nous-Signature and answers with
+# RESPONSE_STATUS, so failures and retries can be tried out
+
+PORT="${PORT:-9000}"
+WEBHOOK_SECRET="${WEBHOOK_SECRET:-}"
+RESPONSE_STATUS="${RESPONSE_STATUS:-200}"
+
+echo "Listening for webhooks on http://localhost:$PORT (responding $RESPONSE_STATUS)"
+if [ -z "$WEBHOOK_SECRET" ]; then
+  echo "WEBHOOK_SECRET is not set, signatures won't be checked"
+fi
+
+PORT="$PORT" WEBHOOK_SECRET="$WEBHOOK_SECRET" RESPONSE_STATUS="$RESPONSE_STATUS" python3 - <<'EOF'
+import hashlib
+import hmac
+import json
+import os
+from http.server import BaseHTTPRequestHandler, HTTPServer
+
+secret = os.environ["WEBHOOK_SECRET"]
+status = int(os.environ["RESPONSE_STATUS"])
+
+
+class Receiver(BaseHTTPRequestHandler):
+    def do_POST(self):
+        body = self.rfile.read(int(self.headers.get("Content-Length", 0)))
+        event = self.headers.get("X-Nous-Event")
+        delivery = self.headers.get("X-Nous-Delivery")
+        timestamp = self.headers.get("X-Nous-Timestamp", "")
+
+        verdict = "unchecked"
+        if secret:
+            expected = "sha256=" + hmac.new(
+                secret.encode(), timestamp.encode() + b"." + body, hashlib.sha256
+            ).hexdigest()
+            valid = hmac.compare_digest(expected, self.headers.get("X-Nous-Signature", ""))
+            verdict = "valid" if valid else "INVALID"
+
+        print(f"--- {event} {delivery} (signature {verdict})")
+        print(json.dumps(json.loads(body), indent=2))
+
+        self.send_response(status)
+        self.end_headers()
+
+    def log_message(self, format, *args):
+        pass
+
+
+HTTPServer(("", int(os.environ["PORT"])), Receiver).serve_forever()
+EOF
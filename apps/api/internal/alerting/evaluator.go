@@ -0,0 +1,210 @@
+// Package alerting evaluates alert rules against the store's metrics and
+// tracks their pending, firing and resolved states.
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+// DefaultEvalInterval is how often rules are evaluated
+const DefaultEvalInterval = 30 * time.Second
+
+// ruleTimeout bounds the store queries of a single rule, so a slow rule
+// doesn't leave the others without time
+const ruleTimeout = 10 * time.Second
+
+// Store provides the rules and the metrics they are evaluated against
+type Store interface {
+	ListAllAlertRules(ctx context.Context) ([]models.AlertRule, error)
+	SetAlertState(ctx context.Context, rule models.AlertRule, previous string, event *models.AlertEvent) (bool, error)
+	GetMetricsTotals(ctx context.Context, q models.MetricsQuery) (*models.MetricsTotals, error)
+	GetLatencyTotals(ctx context.Context, q models.MetricsQuery) (*models.LatencyDataPoint, error)
+}
+
+// Notifier is called for each state change of a rule
+type Notifier func(rule models.AlertRule, event models.AlertEvent)
+
+// Evaluator periodically evaluates every enabled rule. The state is kept
+// in the store, so evaluators on several replicas record each state change
+// once.
+type Evaluator struct {
+	store  Store
+	notify Notifier
+}
+
+// New creates an evaluator reporting state changes to notify, which may be
+// nil
+func New(store Store, notify Notifier) *Evaluator {
+	return &Evaluator{store: store, notify: notify}
+}
+
+// Run evaluates the rules every interval
+func (e *Evaluator) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := e.EvaluateAll(context.Background(), time.Now()); err != nil {
+			log.Printf("Error evaluating alert rules: %v", err)
+		}
+	}
+}
+
+// EvaluateAll evaluates every rule at now, each with its own timeout. A
+// rule failing to evaluate doesn't stop the others.
+func (e *Evaluator) EvaluateAll(ctx context.Context, now time.Time) error {
+	listCtx, cancel := context.WithTimeout(ctx, ruleTimeout)
+	rules, err := e.store.ListAllAlertRules(listCtx)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		ruleCtx, cancel := context.WithTimeout(ctx, ruleTimeout)
+		if err := e.Evaluate(ruleCtx, rule, now); err != nil {
+			log.Printf("Error evaluating alert rule %s: %v", rule.ID, err)
+		}
+		cancel()
+	}
+	return nil
+}
+
+// Evaluate computes a rule's metric over the window ending at now and
+// moves the rule to its next state. Disabled rules go back to ok.
+func (e *Evaluator) Evaluate(ctx context.Context, rule models.AlertRule, now time.Time) error {
+	var value *float64
+	breached := false
+	if rule.Enabled {
+		var err error
+		if value, err = Value(ctx, e.store, rule, now); err != nil {
+			return err
+		}
+		breached = value != nil && rule.Breached(*value)
+	}
+
+	previous := rule.State
+	next := Next(rule, breached, now)
+	next.LastValue = value
+	evaluatedAt := now.UTC()
+	next.LastEvaluatedAt = &evaluatedAt
+
+	var event *models.AlertEvent
+	if next.State != previous {
+		event = &models.AlertEvent{
+			ID:            uuid.New(),
+			RuleID:        rule.ID,
+			ProjectID:     rule.ProjectID,
+			State:         next.State,
+			PreviousState: previous,
+			Value:         value,
+			CreatedAt:     now.UTC(),
+		}
+		if next.State == models.AlertStateOK {
+			event.State = models.AlertStateResolved
+		}
+	} else if !rule.Enabled {
+		// Nothing to record for disabled rules at rest
+		return nil
+	}
+
+	applied, err := e.store.SetAlertState(ctx, next, previous, event)
+	if err != nil {
+		return err
+	}
+	if !applied || event == nil {
+		return nil
+	}
+
+	log.Printf("Alert %q of project %s: %s -> %s (value %s)", rule.Name, rule.ProjectID, previous, event.State, formatValue(value))
+	if e.notify != nil {
+		e.notify(next, *event)
+	}
+	return nil
+}
+
+// Next returns the rule with the state following its current one, given
+// whether the metric breaches the threshold at now
+func Next(rule models.AlertRule, breached bool, now time.Time) models.AlertRule {
+	since := now.UTC()
+	switch {
+	case !breached:
+		if rule.State != models.AlertStateOK {
+			rule.State, rule.StateSince = models.AlertStateOK, &since
+		}
+	case rule.State == models.AlertStateOK && rule.For() > 0:
+		rule.State, rule.StateSince = models.AlertStatePending, &since
+	case rule.State == models.AlertStateOK,
+		rule.State == models.AlertStatePending && (rule.StateSince == nil || now.Sub(*rule.StateSince) >= rule.For()):
+		rule.State, rule.StateSince = models.AlertStateFiring, &since
+	}
+	return rule
+}
+
+// Value computes a rule's metric over the window ending at now; nil means
+// the metric is undefined because no calls matched (latency metrics only:
+// counts and rates are zero). Latency percentiles are taken across all
+// matching calls, whatever their tool.
+func Value(ctx context.Context, store Store, rule models.AlertRule, now time.Time) (*float64, error) {
+	q := models.MetricsQuery{
+		ProjectID: rule.ProjectID,
+		From:      now.Add(-rule.Window()),
+		To:        now,
+		Filter:    rule.Filter.MetricsFilter(),
+	}
+
+	switch rule.Metric {
+	case models.AlertMetricP50Latency, models.AlertMetricP95Latency, models.AlertMetricP99Latency:
+		latency, err := store.GetLatencyTotals(ctx, q)
+		if err != nil || latency == nil {
+			return nil, err
+		}
+
+		value := latency.P99
+		switch rule.Metric {
+		case models.AlertMetricP50Latency:
+			value = latency.P50
+		case models.AlertMetricP95Latency:
+			value = latency.P95
+		}
+		return &value, nil
+	}
+
+	totals, err := store.GetMetricsTotals(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var value float64
+	switch rule.Metric {
+	case models.AlertMetricFailureRate:
+		value = totals.FailureRate
+	case models.AlertMetricCallCount:
+		value = float64(totals.TotalCalls)
+	case models.AlertMetricTotalTokens:
+		value = float64(totals.TotalTokens)
+	case models.AlertMetricAvgLatency:
+		if totals.TotalCalls == 0 {
+			return nil, nil
+		}
+		value = totals.AvgLatencyMs
+	default:
+		return nil, fmt.Errorf("unknown alert metric %q", rule.Metric)
+	}
+	return &value, nil
+}
+
+// formatValue formats a metric value for logs
+func formatValue(value *float64) string {
+	if value == nil {
+		return "no data"
+	}
+	return fmt.Sprintf("%g", *value)
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/auth"
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+)
+
+const (
+	// Default and maximum number of alert events returned
+	defaultAlertEventLimit = 50
+	maxAlertEventLimit     = 1000
+)
+
+// ListAlertRules returns the project's alert rules with their state
+func (h *Handlers) ListAlertRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.repo.ListAlertRules(r.Context(), auth.ProjectID(r.Context()))
+	if err != nil {
+		log.Printf("Error listing alert rules: %v", err)
+		http.Error(w, "Failed to list alert rules", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// GetAlertRule returns one of the project's alert rules with its state
+func (h *Handlers) GetAlertRule(w http.ResponseWriter, r *http.Request) {
+	rule, ok := h.alertRule(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// alertRule loads the rule named by the ruleId URL parameter, writing the
+// error response when that fails
+func (h *Handlers) alertRule(w http.ResponseWriter, r *http.Request) (*models.AlertRule, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "ruleId"))
+	if err != nil {
+		http.Error(w, "Invalid alert rule ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	rule, err := h.repo.GetAlertRule(r.Context(), auth.ProjectID(r.Context()), id)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Alert rule not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error fetching alert rule: %v", err)
+		http.Error(w, "Failed to fetch alert rule", http.StatusInternalServerError)
+		return nil, false
+	}
+	return rule, true
+}
+
+// CreateAlertRule adds an alert rule to the project
+func (h *Handlers) CreateAlertRule(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeAlertRuleRequest(w, r)
+	if !ok {
+		return
+	}
+
+	rule := models.AlertRule{
+		ID:        uuid.New(),
+		ProjectID: auth.ProjectID(r.Context()),
+		State:     models.AlertStateOK,
+		CreatedAt: time.Now().UTC(),
+	}
+	applyAlertRuleRequest(&rule, req)
+
+	if err := h.repo.CreateAlertRule(r.Context(), rule); err != nil {
+		log.Printf("Error creating alert rule: %v", err)
+		http.Error(w, "Failed to create alert rule", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Alert rule %q created in project %s: %s %s %g over %ds", rule.Name, rule.ProjectID,
+		rule.Metric, rule.Operator, rule.Threshold, rule.WindowSeconds)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+// UpdateAlertRule replaces the definition of one of the project's alert
+// rules. Its state carries over and follows the new definition from the
+// next evaluation.
+func (h *Handlers) UpdateAlertRule(w http.ResponseWriter, r *http.Request) {
+	rule, ok := h.alertRule(w, r)
+	if !ok {
+		return
+	}
+	req, ok := decodeAlertRuleRequest(w, r)
+	if !ok {
+		return
+	}
+	applyAlertRuleRequest(rule, req)
+
+	err := h.repo.UpdateAlertRule(r.Context(), *rule)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Alert rule not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating alert rule: %v", err)
+		http.Error(w, "Failed to update alert rule", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// decodeAlertRuleRequest reads and validates an alert rule body, writing
+// the error response when that fails
+func decodeAlertRuleRequest(w http.ResponseWriter, r *http.Request) (models.AlertRuleRequest, bool) {
+	var req models.AlertRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return req, false
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return req, false
+	}
+	for key := range req.Filter.Metadata {
+		if !isMetadataKey(key) {
+			http.Error(w, fmt.Sprintf("invalid metadata key %q", key), http.StatusBadRequest)
+			return req, false
+		}
+	}
+	return req, true
+}
+
+// applyAlertRuleRequest sets a rule's definition from a validated request
+func applyAlertRuleRequest(rule *models.AlertRule, req models.AlertRuleRequest) {
+	rule.Name = req.Name
+	rule.Metric = req.Metric
+	rule.Operator = req.Operator
+	rule.Threshold = *req.Threshold
+	rule.WindowSeconds = req.WindowSeconds
+	rule.ForSeconds = req.ForSeconds
+	rule.Filter = req.Filter
+	rule.Enabled = req.Enabled == nil || *req.Enabled
+}
+
+// DeleteAlertRule removes one of the project's alert rules and its history
+func (h *Handlers) DeleteAlertRule(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "ruleId"))
+	if err != nil {
+		http.Error(w, "Invalid alert rule ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.repo.DeleteAlertRule(r.Context(), auth.ProjectID(r.Context()), id)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Alert rule not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error deleting alert rule: %v", err)
+		http.Error(w, "Failed to delete alert rule", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListAlertEvents returns the project's most recent alert state changes,
+// newest first (limit parameter, default 50)
+func (h *Handlers) ListAlertEvents(w http.ResponseWriter, r *http.Request) {
+	h.listAlertEvents(w, r, nil)
+}
+
+// ListAlertRuleEvents returns the most recent state changes of one of the
+// project's alert rules, newest first
+func (h *Handlers) ListAlertRuleEvents(w http.ResponseWriter, r *http.Request) {
+	rule, ok := h.alertRule(w, r)
+	if !ok {
+		return
+	}
+	h.listAlertEvents(w, r, &rule.ID)
+}
+
+// listAlertEvents writes the project's alert events, optionally of one rule
+func (h *Handlers) listAlertEvents(w http.ResponseWriter, r *http.Request, ruleID *uuid.UUID) {
+	limit := defaultAlertEventLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxAlertEventLimit {
+			http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxAlertEventLimit), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	events, err := h.repo.ListAlertEvents(r.Context(), auth.ProjectID(r.Context()), ruleID, limit)
+	if err != nil {
+		log.Printf("Error listing alert events: %v", err)
+		http.Error(w, "Failed to list alert events", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
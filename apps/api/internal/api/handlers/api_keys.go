@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/auth"
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+)
+
+// Maximum length of an API key name
+const maxAPIKeyNameLength = 255
+
+// CreateAPIKey creates an API key for the request's project with the
+// requested scopes. The secret key is only ever returned in this response.
+func (h *Handlers) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > maxAPIKeyNameLength {
+		http.Error(w, "name is required and must be at most 255 characters", http.StatusBadRequest)
+		return
+	}
+	if len(req.Scopes) == 0 {
+		http.Error(w, "scopes must list at least one of 'ingest', 'read' or 'admin'", http.StatusBadRequest)
+		return
+	}
+	for _, scope := range req.Scopes {
+		if !models.IsValidScope(scope) {
+			http.Error(w, "scopes must only contain 'ingest', 'read' or 'admin'", http.StatusBadRequest)
+			return
+		}
+	}
+
+	projectID := auth.ProjectID(r.Context())
+	if _, err := h.repo.GetProject(r.Context(), projectID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			http.Error(w, "Project not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("Error fetching project: %v", err)
+		http.Error(w, "Failed to create API key", http.StatusInternalServerError)
+		return
+	}
+
+	secret, prefix, err := auth.GenerateKey()
+	if err != nil {
+		log.Printf("Error generating API key: %v", err)
+		http.Error(w, "Failed to create API key", http.StatusInternalServerError)
+		return
+	}
+
+	key := models.APIKey{
+		ID:        uuid.New(),
+		ProjectID: projectID,
+		Name:      req.Name,
+		Prefix:    prefix,
+		Scopes:    req.Scopes,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := h.repo.CreateAPIKey(r.Context(), &key, auth.HashKey(secret)); err != nil {
+		log.Printf("Error creating API key: %v", err)
+		http.Error(w, "Failed to create API key", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.CreatedAPIKey{APIKey: key, Key: secret})
+}
+
+// ListAPIKeys returns the project's API keys without their secrets
+func (h *Handlers) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.repo.ListAPIKeys(r.Context(), auth.ProjectID(r.Context()))
+	if err != nil {
+		log.Printf("Error listing API keys: %v", err)
+		http.Error(w, "Failed to list API keys", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(keys)
+}
+
+// RevokeAPIKey revokes one of the project's API keys; requests using it are
+// rejected from then on
+func (h *Handlers) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "keyId"))
+	if err != nil {
+		http.Error(w, "Invalid key ID", http.StatusBadRequest)
+		return
+	}
+
+	key, err := h.repo.RevokeAPIKey(r.Context(), auth.ProjectID(r.Context()), id)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "API key not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error revoking API key: %v", err)
+		http.Error(w, "Failed to revoke API key", http.StatusInternalServerError)
+		return
+	}
+
+	if h.auth != nil {
+		h.auth.Forget(id)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(key)
+}
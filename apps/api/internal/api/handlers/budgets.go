@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/auth"
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+)
+
+// ListBudgets returns the project's budgets with their current usage
+func (h *Handlers) ListBudgets(w http.ResponseWriter, r *http.Request) {
+	budgets, err := h.repo.ListBudgets(r.Context(), auth.ProjectID(r.Context()))
+	if err != nil {
+		log.Printf("Error listing budgets: %v", err)
+		http.Error(w, "Failed to list budgets", http.StatusInternalServerError)
+		return
+	}
+
+	statuses := make([]models.BudgetStatus, len(budgets))
+	for i, b := range budgets {
+		statuses[i] = h.budgetStatus(b)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(statuses)
+}
+
+// GetBudget returns one of the project's budgets with its current usage
+func (h *Handlers) GetBudget(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "budgetId"))
+	if err != nil {
+		http.Error(w, "Invalid budget ID", http.StatusBadRequest)
+		return
+	}
+
+	b, err := h.repo.GetBudget(r.Context(), auth.ProjectID(r.Context()), id)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Budget not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching budget: %v", err)
+		http.Error(w, "Failed to fetch budget", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.budgetStatus(*b))
+}
+
+// budgetStatus returns a budget with its usage, which is unknown without
+// a tracker
+func (h *Handlers) budgetStatus(b models.Budget) models.BudgetStatus {
+	if h.budgets == nil {
+		return models.BudgetStatus{Budget: b, Usage: []models.BudgetUsage{}}
+	}
+	return h.budgets.Status(b)
+}
+
+// CreateBudget adds a budget to the project
+func (h *Handlers) CreateBudget(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateBudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.MetadataKey != "" && !isMetadataKey(req.MetadataKey) {
+		http.Error(w, fmt.Sprintf("invalid metadata key %q", req.MetadataKey), http.StatusBadRequest)
+		return
+	}
+
+	b := models.Budget{
+		ID:          uuid.New(),
+		ProjectID:   auth.ProjectID(r.Context()),
+		Name:        req.Name,
+		Metric:      req.Metric,
+		Limit:       *req.Limit,
+		WindowHours: req.WindowHours,
+		ToolName:    req.ToolName,
+		MetadataKey: req.MetadataKey,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := h.repo.CreateBudget(r.Context(), b); err != nil {
+		log.Printf("Error creating budget: %v", err)
+		http.Error(w, "Failed to create budget", http.StatusInternalServerError)
+		return
+	}
+	if h.budgets != nil {
+		// The periodic sync picks the budget up if this fails
+		if err := h.budgets.Add(r.Context(), b); err != nil {
+			log.Printf("Error computing usage of budget %s: %v", b.ID, err)
+		}
+	}
+	log.Printf("Budget %q created in project %s: %g %s per %dh", b.Name, b.ProjectID, b.Limit, b.Metric, b.WindowHours)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(h.budgetStatus(b))
+}
+
+// DeleteBudget removes one of the project's budgets
+func (h *Handlers) DeleteBudget(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "budgetId"))
+	if err != nil {
+		http.Error(w, "Invalid budget ID", http.StatusBadRequest)
+		return
+	}
+
+	projectID := auth.ProjectID(r.Context())
+	err = h.repo.DeleteBudget(r.Context(), projectID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Budget not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error deleting budget: %v", err)
+		http.Error(w, "Failed to delete budget", http.StatusInternalServerError)
+		return
+	}
+	if h.budgets != nil {
+		h.budgets.Remove(projectID, id)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CheckBudgets tells an agent whether it is over budget. Budgets of other
+// tools are skipped when tool_name is given, and per-key budgets apply
+// when their key is given as metadata.<key>=<value>, e.g.
+// metadata.agent_id=agent-7.
+func (h *Handlers) CheckBudgets(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	metadata := make(map[string]string)
+	for name, values := range params {
+		key, ok := strings.CutPrefix(name, models.GroupByMetadataPrefix)
+		if !ok {
+			continue
+		}
+		if !isMetadataKey(key) {
+			http.Error(w, fmt.Sprintf("invalid metadata key %q", key), http.StatusBadRequest)
+			return
+		}
+		metadata[key] = values[0]
+	}
+
+	check := models.BudgetCheck{Budgets: []models.BudgetStatus{}}
+	if h.budgets != nil {
+		check = h.budgets.Check(auth.ProjectID(r.Context()), params.Get("tool_name"), metadata)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(check)
+}
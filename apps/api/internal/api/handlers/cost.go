@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+)
+
+// GetCostMetrics returns the cost of tool calls by tool, model and time
+// bucket, and by group_by when given. Costs are fixed at ingest from the
+// prices then in the catalog.
+func (h *Handlers) GetCostMetrics(w http.ResponseWriter, r *http.Request) {
+	q, err := parseMetricsQuery(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.repo.GetCostMetrics(r.Context(), q)
+	if err != nil {
+		log.Printf("Error fetching cost metrics: %v", err)
+		http.Error(w, "Failed to fetch cost metrics", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("X-Bucket-Interval", formatInterval(q.Interval))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// ListModelPrices returns the model price catalog
+func (h *Handlers) ListModelPrices(w http.ResponseWriter, r *http.Request) {
+	prices, err := h.repo.ListModelPrices(r.Context())
+	if err != nil {
+		log.Printf("Error listing model prices: %v", err)
+		http.Error(w, "Failed to list model prices", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prices)
+}
+
+// SetModelPrice adds a price to the catalog, or replaces the model's price
+// with the same effective_from. Tool calls ingested from then on are
+// priced with it; stored costs don't change.
+func (h *Handlers) SetModelPrice(w http.ResponseWriter, r *http.Request) {
+	var req models.SetModelPriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Model = strings.TrimSpace(req.Model)
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	price := models.ModelPrice{
+		ID:                    uuid.New(),
+		Model:                 req.Model,
+		InputPerMillion:       *req.InputPerMillion,
+		OutputPerMillion:      *req.OutputPerMillion,
+		CachedInputPerMillion: req.CachedInputPerMillion,
+		EffectiveFrom:         time.Unix(0, 0).UTC(),
+		CreatedAt:             time.Now().UTC(),
+	}
+	if req.EffectiveFrom != nil {
+		price.EffectiveFrom = req.EffectiveFrom.UTC()
+	}
+
+	if err := h.repo.SetModelPrice(r.Context(), &price); err != nil {
+		log.Printf("Error setting model price: %v", err)
+		http.Error(w, "Failed to set model price", http.StatusInternalServerError)
+		return
+	}
+	if h.pricing != nil {
+		h.pricing.Invalidate()
+	}
+	log.Printf("Price of %s from %s set to %g/%g per million input/output tokens",
+		price.Model, price.EffectiveFrom.Format(time.RFC3339), price.InputPerMillion, price.OutputPerMillion)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(price)
+}
+
+// DeleteModelPrice removes a price from the catalog
+func (h *Handlers) DeleteModelPrice(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "priceId"))
+	if err != nil {
+		http.Error(w, "Invalid price ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.repo.DeleteModelPrice(r.Context(), id)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Model price not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error deleting model price: %v", err)
+		http.Error(w, "Failed to delete model price", http.StatusInternalServerError)
+		return
+	}
+	if h.pricing != nil {
+		h.pricing.Invalidate()
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1,38 +1,135 @@
 package handlers
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"math"
 	"net/http"
 	"strconv"
+	"time"
+	"unicode"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
+	"github.com/yourorg/nous/internal/auth"
+	"github.com/yourorg/nous/internal/budget"
+	"github.com/yourorg/nous/internal/errortypes"
+	"github.com/yourorg/nous/internal/ingest"
 	"github.com/yourorg/nous/internal/models"
-	"github.com/yourorg/nous/internal/repository"
+	"github.com/yourorg/nous/internal/pricing"
+	"github.com/yourorg/nous/internal/store"
+	"github.com/yourorg/nous/internal/webhook"
 	"github.com/yourorg/nous/internal/websocket"
 )
 
+const (
+	// Maximum number of events accepted in a single batch request
+	maxBatchSize = 1000
+
+	// Maximum size of a batch request body
+	maxBatchBodyBytes = 10 * 1024 * 1024 // 10MB
+
+	// Maximum length of span_id and parent_span_id
+	maxSpanIDLength = 64
+
+	// Maximum length of a client-supplied event_id
+	maxEventIDLength = 255
+
+	// Maximum length of tool_name, the size of its column
+	maxToolNameLength = 255
+
+	// Largest duration_ms and token count, the range of their INTEGER
+	// columns
+	maxEventInteger = math.MaxInt32
+)
+
 type Handlers struct {
-	repo *repository.Repository
-	hub  *websocket.Hub
+	repo       store.Store
+	hub        *websocket.Hub
+	pipeline   *ingest.Pipeline
+	auth       *auth.Authenticator
+	pricing    *pricing.Catalog
+	budgets    *budget.Tracker
+	webhooks   *webhook.Dispatcher
+	errorTypes *errortypes.Detector
 }
 
-func New(repo *repository.Repository) *Handlers {
+func New(repo store.Store) *Handlers {
 	return &Handlers{
 		repo: repo,
 		hub:  nil, // Will be set by main
 	}
 }
 
-func NewWithHub(repo *repository.Repository, hub *websocket.Hub) *Handlers {
+func NewWithHub(repo store.Store, hub *websocket.Hub) *Handlers {
 	return &Handlers{
 		repo: repo,
 		hub:  hub,
 	}
 }
 
+// NewWithPipeline creates handlers that hand single events to an
+// asynchronous ingestion pipeline instead of writing them synchronously
+func NewWithPipeline(repo store.Store, hub *websocket.Hub, pipeline *ingest.Pipeline) *Handlers {
+	return &Handlers{
+		repo:     repo,
+		hub:      hub,
+		pipeline: pipeline,
+	}
+}
+
+// SetAuthenticator lets key management evict revoked keys from the
+// authenticator's cache
+func (h *Handlers) SetAuthenticator(authenticator *auth.Authenticator) {
+	h.auth = authenticator
+}
+
+// SetPricing computes the cost of ingested tool calls from the catalog,
+// which price changes through the API invalidate
+func (h *Handlers) SetPricing(catalog *pricing.Catalog) {
+	h.pricing = catalog
+}
+
+// SetBudgets counts ingested tool calls against the tracker's budgets. The
+// tracker follows the ingestion pipeline's buffered calls until they are
+// written or dropped.
+func (h *Handlers) SetBudgets(tracker *budget.Tracker) {
+	h.budgets = tracker
+	if h.pipeline != nil {
+		h.pipeline.SetListener(tracker)
+	}
+}
+
+// SetWebhooks sends webhook test events through the dispatcher
+func (h *Handlers) SetWebhooks(dispatcher *webhook.Dispatcher) {
+	h.webhooks = dispatcher
+}
+
+// SetErrorTypes looks for new error types among ingested tool calls
+func (h *Handlers) SetErrorTypes(detector *errortypes.Detector) {
+	h.errorTypes = detector
+}
+
+// newToolCall builds the record of a validated event, priced from the
+// catalog when one is set
+func (h *Handlers) newToolCall(ctx context.Context, projectID uuid.UUID, event models.ToolCallEvent) (models.ToolCall, error) {
+	call, err := models.NewToolCall(projectID, event)
+	if err != nil {
+		return call, err
+	}
+	if h.pricing != nil {
+		h.pricing.Price(ctx, &call)
+	}
+	return call, nil
+}
+
 // IngestEvent handles incoming tool call events from agents
 func (h *Handlers) IngestEvent(w http.ResponseWriter, r *http.Request) {
 	var event models.ToolCallEvent
@@ -42,45 +139,266 @@ func (h *Handlers) IngestEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate required fields
-	if event.RequestID == "" || event.ToolName == "" || event.Status == "" {
-		log.Printf("Missing required fields: request_id=%q, tool_name=%q, status=%q", event.RequestID, event.ToolName, event.Status)
-		http.Error(w, "Missing required fields", http.StatusBadRequest)
+	// The Idempotency-Key header is an alternative to event_id in the body
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		if event.EventID != nil && *event.EventID != key {
+			http.Error(w, "event_id does not match Idempotency-Key header", http.StatusBadRequest)
+			return
+		}
+		event.EventID = &key
+	}
+
+	if err := validateEvent(event); err != nil {
+		log.Printf("Invalid event: %v (request_id=%q, tool_name=%q, status=%q)", err, event.RequestID, event.ToolName, event.Status)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Validate request_id is a valid UUID
-	if _, err := uuid.Parse(event.RequestID); err != nil {
-		log.Printf("Invalid request_id format: %q (error: %v)", event.RequestID, err)
-		http.Error(w, "request_id must be a valid UUID", http.StatusBadRequest)
+	call, err := h.newToolCall(r.Context(), auth.ProjectID(r.Context()), event)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	if event.Status != "success" && event.Status != "failed" {
-		http.Error(w, "Status must be 'success' or 'failed'", http.StatusBadRequest)
+	// Replays of an already ingested event get the original record back
+	if call.EventID != nil {
+		originals, err := h.repo.ClaimEventIDs(r.Context(), call.ProjectID, []models.ToolCall{call})
+		if err != nil {
+			log.Printf("Error claiming event ID: %v", err)
+			http.Error(w, "Failed to ingest event", http.StatusInternalServerError)
+			return
+		}
+		if original := originals[0]; original != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(original)
+			return
+		}
+	}
+
+	if h.pipeline != nil {
+		h.enqueueEvent(w, r, event, call)
 		return
 	}
 
-	if err := h.repo.IngestToolCall(r.Context(), event); err != nil {
+	if err := h.repo.IngestToolCall(r.Context(), call); err != nil {
 		log.Printf("Error ingesting event: %v", err)
+		h.releaseEventIDs(r.Context(), call.ProjectID, []models.ToolCall{call})
 		http.Error(w, "Failed to ingest event", http.StatusInternalServerError)
 		return
 	}
 
-	// Broadcast event to WebSocket clients
+	// Broadcast event to the project's WebSocket clients
 	if h.hub != nil {
-		h.hub.BroadcastMessage("tool_call", event)
+		h.hub.BroadcastMessage(call.ProjectID, websocket.MessageTypeToolCall, event)
 	}
+	h.recordAccepted(call.ProjectID, []models.ToolCall{call}, false)
 
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
 
+// enqueueEvent buffers a validated event in the ingestion pipeline,
+// responding 429 with Retry-After when the buffer is full
+func (h *Handlers) enqueueEvent(w http.ResponseWriter, r *http.Request, event models.ToolCallEvent, call models.ToolCall) {
+	if err := h.pipeline.Enqueue(call); err != nil {
+		// The event wasn't stored, so its retry must not look like a replay
+		h.releaseEventIDs(r.Context(), call.ProjectID, []models.ToolCall{call})
+
+		if errors.Is(err, ingest.ErrQueueFull) {
+			retryAfter := int(h.pipeline.RetryAfter().Round(time.Second) / time.Second)
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			http.Error(w, "Ingestion queue is full, retry later", http.StatusTooManyRequests)
+			return
+		}
+		http.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Broadcast event to the project's WebSocket clients
+	if h.hub != nil {
+		h.hub.BroadcastMessage(call.ProjectID, websocket.MessageTypeToolCall, event)
+	}
+	h.recordAccepted(call.ProjectID, []models.ToolCall{call}, true)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"status": "accepted"})
+}
+
+// IngestEventsBatch handles a batch of tool call events sent as a JSON array
+// or as newline-delimited JSON. Each event is validated independently and
+// the response reports a result per event, so one bad event doesn't reject
+// the whole batch.
+func (h *Handlers) IngestEventsBatch(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBatchBodyBytes)
+
+	raw, err := readBatch(r)
+	if err != nil {
+		log.Printf("Error reading batch body: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if len(raw) == 0 {
+		http.Error(w, "Batch must contain at least one event", http.StatusBadRequest)
+		return
+	}
+	if len(raw) > maxBatchSize {
+		http.Error(w, fmt.Sprintf("Batch must not exceed %d events", maxBatchSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	projectID := auth.ProjectID(r.Context())
+	response := models.BatchIngestResponse{
+		Results: make([]models.BatchEventResult, len(raw)),
+	}
+	events := make([]models.ToolCallEvent, 0, len(raw))
+	calls := make([]models.ToolCall, 0, len(raw))
+	indexes := make([]int, 0, len(raw))
+
+	for i, item := range raw {
+		response.Results[i] = models.BatchEventResult{Index: i, Status: "rejected"}
+
+		var event models.ToolCallEvent
+		if err := json.Unmarshal(item, &event); err != nil {
+			response.Results[i].Error = "Invalid event body"
+			continue
+		}
+		if err := validateEvent(event); err != nil {
+			response.Results[i].Error = err.Error()
+			continue
+		}
+		call, err := h.newToolCall(r.Context(), projectID, event)
+		if err != nil {
+			response.Results[i].Error = err.Error()
+			continue
+		}
+
+		events = append(events, event)
+		calls = append(calls, call)
+		indexes = append(indexes, i)
+	}
+
+	if len(calls) > 0 {
+		originals, err := h.storeCalls(r.Context(), projectID, events, calls)
+		if err != nil {
+			log.Printf("Error ingesting batch of %d events: %v", len(calls), err)
+			http.Error(w, "Failed to ingest events", http.StatusInternalServerError)
+			return
+		}
+
+		for j, i := range indexes {
+			if original := originals[j]; original != nil {
+				response.Results[i].Status = "duplicate"
+				response.Results[i].ID = &original.ID
+				response.Duplicates++
+				continue
+			}
+			response.Results[i].Status = "accepted"
+			response.Results[i].ID = &calls[j].ID
+			response.Accepted++
+		}
+	}
+	response.Rejected = len(raw) - len(calls)
+
+	status := http.StatusCreated
+	if response.Rejected > 0 {
+		status = http.StatusMultiStatus
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// storeCalls claims event IDs, bulk-writes a project's calls that aren't
+// replays and broadcasts them. The result is aligned with calls: nil for
+// stored calls, otherwise the original record the call duplicates.
+func (h *Handlers) storeCalls(ctx context.Context, projectID uuid.UUID, events []models.ToolCallEvent, calls []models.ToolCall) ([]*models.ToolCall, error) {
+	originals, err := h.repo.ClaimEventIDs(ctx, projectID, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	fresh := make([]models.ToolCall, 0, len(calls))
+	for i, call := range calls {
+		if originals[i] == nil {
+			fresh = append(fresh, call)
+		}
+	}
+
+	if len(fresh) > 0 {
+		if err := h.repo.IngestToolCalls(ctx, fresh); err != nil {
+			h.releaseEventIDs(ctx, projectID, fresh)
+			return nil, err
+		}
+	}
+
+	// Broadcast stored events to the project's WebSocket clients
+	if h.hub != nil {
+		for i, event := range events {
+			if originals[i] == nil {
+				h.hub.BroadcastMessage(projectID, websocket.MessageTypeToolCall, event)
+			}
+		}
+	}
+	h.recordAccepted(projectID, fresh, false)
+
+	return originals, nil
+}
+
+// recordAccepted counts accepted calls against the project's budgets and
+// looks for new error types among them. Buffered calls are still in the
+// ingestion pipeline.
+func (h *Handlers) recordAccepted(projectID uuid.UUID, calls []models.ToolCall, buffered bool) {
+	if len(calls) == 0 {
+		return
+	}
+	if h.budgets != nil {
+		if buffered {
+			h.budgets.RecordBuffered(projectID, calls)
+		} else {
+			h.budgets.Record(projectID, calls)
+		}
+	}
+	if h.errorTypes != nil {
+		h.errorTypes.Observe(projectID, calls)
+	}
+}
+
+// releaseEventIDs forgets the event IDs of calls that failed to be stored
+func (h *Handlers) releaseEventIDs(ctx context.Context, projectID uuid.UUID, calls []models.ToolCall) {
+	var ids []string
+	for _, call := range calls {
+		if call.EventID != nil {
+			ids = append(ids, *call.EventID)
+		}
+	}
+
+	// Release even if the request itself was cancelled
+	if err := h.repo.ReleaseEventIDs(context.WithoutCancel(ctx), projectID, ids); err != nil {
+		log.Printf("Error releasing event IDs: %v", err)
+	}
+}
+
 // GetMetricsOverview returns aggregated overview metrics
 func (h *Handlers) GetMetricsOverview(w http.ResponseWriter, r *http.Request) {
-	hours := parseHours(r)
-	overview, err := h.repo.GetMetricsOverview(r.Context(), hours)
+	q, err := parseMetricsQuery(r, false)
 	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	compareOffset, err := parseCompareTo(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	overview, err := h.repo.GetMetricsOverview(r.Context(), q, compareOffset)
+	if err != nil {
+		log.Printf("Error fetching metrics overview: %v", err)
 		http.Error(w, "Failed to fetch metrics", http.StatusInternalServerError)
 		return
 	}
@@ -89,23 +407,39 @@ func (h *Handlers) GetMetricsOverview(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(overview)
 }
 
-// GetToolCallsMetrics returns tool calls aggregated by hour
+// GetToolCallsMetrics returns tool calls aggregated per time bucket
 func (h *Handlers) GetToolCallsMetrics(w http.ResponseWriter, r *http.Request) {
-	hours := parseHours(r)
-	metrics, err := h.repo.GetToolCallsMetrics(r.Context(), hours)
+	q, err := parseMetricsQuery(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	metrics, err := h.repo.GetToolCallsMetrics(r.Context(), q)
 	if err != nil {
+		log.Printf("Error fetching metrics: %v", err)
 		http.Error(w, "Failed to fetch tool calls metrics", http.StatusInternalServerError)
 		return
 	}
 
+	w.Header().Set("X-Bucket-Interval", formatInterval(q.Interval))
 	w.Header().Set("Content-Type", "application/json")
+	if q.GroupBy != "" {
+		json.NewEncoder(w).Encode(models.GroupSeries(metrics, func(p models.ToolCallDataPoint) string { return p.Group }))
+		return
+	}
 	json.NewEncoder(w).Encode(metrics)
 }
 
 // GetLatencyMetrics returns latency percentiles per tool
 func (h *Handlers) GetLatencyMetrics(w http.ResponseWriter, r *http.Request) {
-	hours := parseHours(r)
-	metrics, err := h.repo.GetLatencyMetrics(r.Context(), hours)
+	q, err := parseMetricsQuery(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	metrics, err := h.repo.GetLatencyMetrics(r.Context(), q)
 	if err != nil {
 		log.Printf("Error fetching latency metrics: %v", err)
 		http.Error(w, "Failed to fetch latency metrics", http.StatusInternalServerError)
@@ -116,29 +450,51 @@ func (h *Handlers) GetLatencyMetrics(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(metrics)
 }
 
-// GetTokenUsageMetrics returns token usage aggregated by hour
+// GetTokenUsageMetrics returns token usage aggregated per time bucket
 func (h *Handlers) GetTokenUsageMetrics(w http.ResponseWriter, r *http.Request) {
-	hours := parseHours(r)
-	metrics, err := h.repo.GetTokenUsageMetrics(r.Context(), hours)
+	q, err := parseMetricsQuery(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	metrics, err := h.repo.GetTokenUsageMetrics(r.Context(), q)
 	if err != nil {
+		log.Printf("Error fetching metrics: %v", err)
 		http.Error(w, "Failed to fetch token usage metrics", http.StatusInternalServerError)
 		return
 	}
 
+	w.Header().Set("X-Bucket-Interval", formatInterval(q.Interval))
 	w.Header().Set("Content-Type", "application/json")
+	if q.GroupBy != "" {
+		json.NewEncoder(w).Encode(models.GroupSeries(metrics, func(p models.TokenUsageDataPoint) string { return p.Group }))
+		return
+	}
 	json.NewEncoder(w).Encode(metrics)
 }
 
-// GetFailureRateMetrics returns failure rate aggregated by hour
+// GetFailureRateMetrics returns failure rate aggregated per time bucket
 func (h *Handlers) GetFailureRateMetrics(w http.ResponseWriter, r *http.Request) {
-	hours := parseHours(r)
-	metrics, err := h.repo.GetFailureRateMetrics(r.Context(), hours)
+	q, err := parseMetricsQuery(r, true)
 	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	metrics, err := h.repo.GetFailureRateMetrics(r.Context(), q)
+	if err != nil {
+		log.Printf("Error fetching metrics: %v", err)
 		http.Error(w, "Failed to fetch failure rate metrics", http.StatusInternalServerError)
 		return
 	}
 
+	w.Header().Set("X-Bucket-Interval", formatInterval(q.Interval))
 	w.Header().Set("Content-Type", "application/json")
+	if q.GroupBy != "" {
+		json.NewEncoder(w).Encode(models.GroupSeries(metrics, func(p models.FailureRateDataPoint) string { return p.Group }))
+		return
+	}
 	json.NewEncoder(w).Encode(metrics)
 }
 
@@ -151,7 +507,7 @@ func (h *Handlers) GetRecentToolCalls(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	calls, err := h.repo.GetRecentToolCalls(r.Context(), limit)
+	calls, err := h.repo.GetRecentToolCalls(r.Context(), auth.ProjectID(r.Context()), limit)
 	if err != nil {
 		http.Error(w, "Failed to fetch recent tool calls", http.StatusInternalServerError)
 		return
@@ -170,7 +526,7 @@ func (h *Handlers) GetToolCallChain(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	calls, err := h.repo.GetToolCallChain(r.Context(), requestID)
+	calls, err := h.repo.GetToolCallChain(r.Context(), auth.ProjectID(r.Context()), requestID)
 	if err != nil {
 		http.Error(w, "Failed to fetch tool call chain", http.StatusInternalServerError)
 		return
@@ -180,13 +536,121 @@ func (h *Handlers) GetToolCallChain(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(calls)
 }
 
-// parseHours extracts hours parameter from query string, defaults to 24
-func parseHours(r *http.Request) int {
-	hours := 24
-	if hoursStr := r.URL.Query().Get("hours"); hoursStr != "" {
-		if parsed, err := strconv.Atoi(hoursStr); err == nil && parsed > 0 {
-			hours = parsed
+// GetToolCallTree returns the tool calls for a request ID reconstructed
+// into a tree from their span_id/parent_span_id relationships
+func (h *Handlers) GetToolCallTree(w http.ResponseWriter, r *http.Request) {
+	requestIDStr := chi.URLParam(r, "requestId")
+	requestID, err := uuid.Parse(requestIDStr)
+	if err != nil {
+		http.Error(w, "Invalid request ID", http.StatusBadRequest)
+		return
+	}
+
+	tree, err := h.repo.GetToolCallTree(r.Context(), auth.ProjectID(r.Context()), requestID)
+	if err != nil {
+		log.Printf("Error fetching tool call tree: %v", err)
+		http.Error(w, "Failed to fetch tool call tree", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tree)
+}
+
+// validateEvent checks the required fields of an incoming event
+func validateEvent(event models.ToolCallEvent) error {
+	if event.RequestID == "" || event.ToolName == "" || event.Status == "" {
+		return errors.New("Missing required fields")
+	}
+
+	if _, err := uuid.Parse(event.RequestID); err != nil {
+		return errors.New("request_id must be a valid UUID")
+	}
+
+	if event.Status != "success" && event.Status != "failed" {
+		return errors.New("Status must be 'success' or 'failed'")
+	}
+
+	if len(event.ToolName) > maxToolNameLength {
+		return fmt.Errorf("tool_name must not exceed %d characters", maxToolNameLength)
+	}
+
+	if event.DurationMs < 0 || event.DurationMs > maxEventInteger {
+		return fmt.Errorf("duration_ms must be between 0 and %d", maxEventInteger)
+	}
+
+	for _, tokens := range []*int{event.InputTokens, event.OutputTokens, event.CachedTokens} {
+		if tokens != nil && (*tokens < 0 || *tokens > maxEventInteger) {
+			return fmt.Errorf("token counts must be between 0 and %d", maxEventInteger)
 		}
 	}
-	return hours
+
+	if event.EventID != nil && (*event.EventID == "" || len(*event.EventID) > maxEventIDLength) {
+		return fmt.Errorf("event_id must be between 1 and %d characters", maxEventIDLength)
+	}
+
+	if (event.SpanID != nil && len(*event.SpanID) > maxSpanIDLength) ||
+		(event.ParentSpanID != nil && len(*event.ParentSpanID) > maxSpanIDLength) {
+		return fmt.Errorf("span_id and parent_span_id must not exceed %d characters", maxSpanIDLength)
+	}
+
+	if event.Model != nil && (*event.Model == "" || len(*event.Model) > models.MaxModelLength) {
+		return fmt.Errorf("model must be between 1 and %d characters", models.MaxModelLength)
+	}
+
+	if event.CachedTokens != nil {
+		input := 0
+		if event.InputTokens != nil {
+			input = *event.InputTokens
+		}
+		if *event.CachedTokens < 0 || *event.CachedTokens > input {
+			return errors.New("cached_tokens must be between 0 and input_tokens")
+		}
+	}
+
+	return nil
+}
+
+// readBatch splits a batch body into raw events. A body starting with '['
+// is decoded as a JSON array, anything else as newline-delimited JSON.
+func readBatch(r *http.Request) ([]json.RawMessage, error) {
+	reader := bufio.NewReader(r.Body)
+
+	// Peek past leading whitespace to detect the encoding
+	for {
+		b, err := reader.Peek(1)
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !unicode.IsSpace(rune(b[0])) {
+			break
+		}
+		reader.ReadByte()
+	}
+
+	var raw []json.RawMessage
+	if b, _ := reader.Peek(1); b[0] == '[' {
+		if err := json.NewDecoder(reader).Decode(&raw); err != nil {
+			return nil, err
+		}
+		return raw, nil
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), maxBatchBodyBytes)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		raw = append(raw, json.RawMessage(append([]byte(nil), line...)))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return raw, nil
 }
@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/auth"
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store/memory"
+)
+
+// newTestServer serves the ingestion and metrics routes from an in-memory
+// store, with authentication disabled so X-Project-ID selects the project
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	repo := memory.New()
+	h := New(repo)
+	authn := auth.New(repo, false)
+
+	r := chi.NewRouter()
+	r.Group(func(r chi.Router) {
+		r.Use(authn.Require(models.ScopeIngest))
+		r.Post("/events", h.IngestEvent)
+		r.Post("/events/batch", h.IngestEventsBatch)
+		r.Post("/v1/traces", h.IngestOTLPTraces)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(authn.Require(models.ScopeRead))
+		r.Get("/metrics/overview", h.GetMetricsOverview)
+		r.Get("/tool-calls/recent", h.GetRecentToolCalls)
+	})
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// do sends a request to the test server in project, decoding the JSON
+// response into out when it's not nil
+func do(t *testing.T, server *httptest.Server, method, path string, project uuid.UUID, body string, out any) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(auth.ProjectHeader, project.String())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: decoding response: %v", method, path, err)
+		}
+	}
+	return resp
+}
+
+// event returns the JSON body of a tool call event
+func event(tool, status, eventID string) string {
+	body := map[string]any{
+		"request_id":  uuid.NewString(),
+		"tool_name":   tool,
+		"duration_ms": 120,
+		"status":      status,
+	}
+	if eventID != "" {
+		body["event_id"] = eventID
+	}
+	encoded, _ := json.Marshal(body)
+	return string(encoded)
+}
+
+func TestIngestEvent(t *testing.T) {
+	server := newTestServer(t)
+	project := uuid.New()
+
+	resp := do(t, server, http.MethodPost, "/events", project, event("search_web", "success", ""), nil)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+
+	var calls []models.ToolCall
+	do(t, server, http.MethodGet, "/tool-calls/recent", project, "", &calls)
+	if len(calls) != 1 || calls[0].ToolName != "search_web" {
+		t.Fatalf("recent calls = %+v, want the search_web call", calls)
+	}
+}
+
+func TestIngestEventRejectsInvalidEvent(t *testing.T) {
+	server := newTestServer(t)
+
+	resp := do(t, server, http.MethodPost, "/events", uuid.New(), `{"tool_name": "search_web"}`, nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestIngestEventsBatch(t *testing.T) {
+	server := newTestServer(t)
+	project := uuid.New()
+
+	body := "[" + event("search_web", "success", "") + "," +
+		`{"tool_name": "read_file"}` + "," +
+		event("read_file", "failed", "") + "]"
+
+	var result models.BatchIngestResponse
+	resp := do(t, server, http.MethodPost, "/events/batch", project, body, &result)
+	if resp.StatusCode != http.StatusMultiStatus {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusMultiStatus)
+	}
+	if result.Accepted != 2 || result.Rejected != 1 || result.Duplicates != 0 {
+		t.Fatalf("accepted/rejected/duplicates = %d/%d/%d, want 2/1/0", result.Accepted, result.Rejected, result.Duplicates)
+	}
+	for i, want := range []string{"accepted", "rejected", "accepted"} {
+		if got := result.Results[i].Status; got != want {
+			t.Errorf("results[%d].status = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestIngestEventsBatchNDJSON(t *testing.T) {
+	server := newTestServer(t)
+	project := uuid.New()
+
+	body := event("search_web", "success", "") + "\n" + event("grep", "success", "") + "\n"
+
+	var result models.BatchIngestResponse
+	resp := do(t, server, http.MethodPost, "/events/batch", project, body, &result)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	if result.Accepted != 2 {
+		t.Fatalf("accepted = %d, want 2", result.Accepted)
+	}
+}
+
+func TestIngestEventIdempotentReplay(t *testing.T) {
+	server := newTestServer(t)
+	project := uuid.New()
+	body := event("search_web", "success", "evt-1")
+
+	resp := do(t, server, http.MethodPost, "/events", project, body, nil)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("first status = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+
+	var original models.ToolCall
+	resp = do(t, server, http.MethodPost, "/events", project, body, &original)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("replay status = %d (Idempotent-Replayed %q), want 200 replayed", resp.StatusCode, resp.Header.Get("Idempotent-Replayed"))
+	}
+
+	// A batch resending the event reports it as a duplicate of the original
+	var result models.BatchIngestResponse
+	do(t, server, http.MethodPost, "/events/batch", project, "["+body+"]", &result)
+	if result.Duplicates != 1 || result.Results[0].ID == nil || *result.Results[0].ID != original.ID {
+		t.Fatalf("batch replay = %+v, want a duplicate of %s", result, original.ID)
+	}
+
+	var calls []models.ToolCall
+	do(t, server, http.MethodGet, "/tool-calls/recent", project, "", &calls)
+	if len(calls) != 1 {
+		t.Fatalf("stored %d calls, want 1", len(calls))
+	}
+}
+
+func TestIngestEventsBatchRepeatedReplay(t *testing.T) {
+	server := newTestServer(t)
+	project := uuid.New()
+	body := event("search_web", "success", "evt-1")
+
+	do(t, server, http.MethodPost, "/events", project, body, nil)
+	var original models.ToolCall
+	do(t, server, http.MethodPost, "/events", project, body, &original)
+
+	// Both copies of a stored event are duplicates of the stored record,
+	// not of the first copy, which was never stored
+	var result models.BatchIngestResponse
+	do(t, server, http.MethodPost, "/events/batch", project, "["+body+","+body+"]", &result)
+	if result.Duplicates != 2 {
+		t.Fatalf("duplicates = %d, want 2", result.Duplicates)
+	}
+	for i, item := range result.Results {
+		if item.ID == nil || *item.ID != original.ID {
+			t.Errorf("results[%d] = %+v, want a duplicate of %s", i, item, original.ID)
+		}
+	}
+
+	var calls []models.ToolCall
+	do(t, server, http.MethodGet, "/tool-calls/recent", project, "", &calls)
+	if len(calls) != 1 {
+		t.Fatalf("stored %d calls, want 1", len(calls))
+	}
+}
+
+func TestProjectIsolation(t *testing.T) {
+	server := newTestServer(t)
+	first, second := uuid.New(), uuid.New()
+
+	do(t, server, http.MethodPost, "/events", first, event("search_web", "success", "evt-1"), nil)
+
+	// The same event ID is independent in another project
+	resp := do(t, server, http.MethodPost, "/events", second, event("read_file", "success", "evt-1"), nil)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status in second project = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+
+	for project, tool := range map[uuid.UUID]string{first: "search_web", second: "read_file"} {
+		var calls []models.ToolCall
+		do(t, server, http.MethodGet, "/tool-calls/recent", project, "", &calls)
+		if len(calls) != 1 || calls[0].ToolName != tool {
+			t.Errorf("recent calls of project %s = %+v, want only the %s call", project, calls, tool)
+		}
+
+		var overview models.MetricsOverview
+		do(t, server, http.MethodGet, "/metrics/overview", project, "", &overview)
+		if overview.TotalCalls != 1 {
+			t.Errorf("total calls of project %s = %d, want 1", project, overview.TotalCalls)
+		}
+	}
+}
+
+func TestGetMetricsOverview(t *testing.T) {
+	server := newTestServer(t)
+	project := uuid.New()
+
+	body := "[" + event("search_web", "success", "") + "," +
+		event("search_web", "success", "") + "," +
+		event("search_web", "success", "") + "," +
+		event("read_file", "failed", "") + "]"
+	do(t, server, http.MethodPost, "/events/batch", project, body, nil)
+
+	var overview models.MetricsOverview
+	resp := do(t, server, http.MethodGet, "/metrics/overview?hours=1", project, "", &overview)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if overview.TotalCalls != 4 {
+		t.Errorf("total_calls = %d, want 4", overview.TotalCalls)
+	}
+	if overview.AvgLatencyMs != 120 {
+		t.Errorf("avg_latency_ms = %g, want 120", overview.AvgLatencyMs)
+	}
+	if overview.FailureRate != 25 {
+		t.Errorf("failure_rate = %g, want 25", overview.FailureRate)
+	}
+
+	resp = do(t, server, http.MethodGet, "/metrics/overview?from=yesterday", project, "", nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status for an invalid from = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
+
+// span returns the OTLP/JSON of a GenAI span lasting duration
+func span(name string, duration time.Duration) string {
+	start := time.Now().Add(-time.Minute).UnixNano()
+	encoded, _ := json.Marshal(map[string]any{
+		"traceId":           strings.ReplaceAll(uuid.NewString(), "-", ""),
+		"spanId":            strings.ReplaceAll(uuid.NewString(), "-", "")[:16],
+		"name":              name,
+		"startTimeUnixNano": strconv.FormatInt(start, 10),
+		"endTimeUnixNano":   strconv.FormatUint(uint64(start)+uint64(duration), 10),
+		"attributes": []map[string]any{
+			{"key": "gen_ai.operation.name", "value": map[string]any{"stringValue": "execute_tool"}},
+		},
+	})
+	return string(encoded)
+}
+
+func TestIngestOTLPTracesRejectsInvalidSpans(t *testing.T) {
+	server := newTestServer(t)
+	project := uuid.New()
+
+	body := `{"resourceSpans": [{"scopeSpans": [{"spans": [` +
+		span("search_web", 250*time.Millisecond) + "," +
+		span(strings.Repeat("x", 300), time.Second) + "," +
+		span("read_file", 1000*time.Hour) +
+		`]}]}]}`
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/v1/traces", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(auth.ProjectHeader, project.String())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	var result struct {
+		PartialSuccess struct {
+			RejectedSpans string `json:"rejectedSpans"`
+			ErrorMessage  string `json:"errorMessage"`
+		} `json:"partialSuccess"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if result.PartialSuccess.RejectedSpans != "2" || result.PartialSuccess.ErrorMessage == "" {
+		t.Errorf("partial success = %+v, want 2 rejected spans with a message", result.PartialSuccess)
+	}
+
+	var calls []models.ToolCall
+	do(t, server, http.MethodGet, "/tool-calls/recent", project, "", &calls)
+	if len(calls) != 1 || calls[0].ToolName != "search_web" || calls[0].DurationMs != 250 {
+		t.Errorf("recent calls = %+v, want only the valid search_web span", calls)
+	}
+}
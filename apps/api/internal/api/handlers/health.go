@@ -42,23 +42,33 @@ func (h *Handlers) ReadinessCheck(w http.ResponseWriter, r *http.Request) {
 
 	if wantsJSON {
 		// Detailed JSON format for webhooks and monitoring systems
+		services := map[string]interface{}{
+			"database": map[string]interface{}{
+				"status": func() string {
+					if dbHealthy {
+						return "connected"
+					}
+					return "disconnected"
+				}(),
+			},
+			"websocket": map[string]interface{}{
+				"status":      "active",
+				"connections": h.hub.GetClientCount(),
+			},
+		}
+		// Without the broker, events only reach this replica's clients
+		if err := h.hub.PingBroker(ctx); err != nil {
+			log.Printf("Readiness check: broadcast broker ping error: %v", err)
+			services["websocket"].(map[string]interface{})["status"] = "degraded"
+		}
+		if h.pipeline != nil {
+			services["ingest"] = h.pipeline.Stats()
+		}
+
 		response := map[string]interface{}{
 			"status":    status,
 			"timestamp": time.Now().Format(time.RFC3339),
-			"services": map[string]interface{}{
-				"database": map[string]interface{}{
-					"status": func() string {
-						if dbHealthy {
-							return "connected"
-						}
-						return "disconnected"
-					}(),
-				},
-				"websocket": map[string]interface{}{
-					"status":      "active",
-					"connections": h.hub.GetClientCount(),
-				},
-			},
+			"services":  services,
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/yourorg/nous/internal/auth"
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/otlp"
+)
+
+// Maximum size of a decompressed OTLP export request
+const maxOTLPBodyBytes = 16 * 1024 * 1024 // 16MB
+
+// IngestOTLPTraces implements the OTLP/HTTP trace receiver (POST /v1/traces).
+// Spans carrying GenAI/tool semantic-convention attributes are stored as tool
+// calls; other spans are accepted and ignored.
+func (h *Handlers) IngestOTLPTraces(w http.ResponseWriter, r *http.Request) {
+	encoding := otlp.Encoding(r.Header.Get("Content-Type"))
+	if encoding == "" {
+		http.Error(w, "Content-Type must be application/x-protobuf or application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var body io.Reader = r.Body
+	switch r.Header.Get("Content-Encoding") {
+	case "", "identity":
+	case "gzip":
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, "Invalid gzip body", http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+		body = gz
+	default:
+		http.Error(w, "Unsupported Content-Encoding", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxOTLPBodyBytes+1))
+	if err != nil {
+		log.Printf("Error reading OTLP request body: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(data) > maxOTLPBodyBytes {
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	traces, err := otlp.DecodeTraces(encoding, data)
+	if err != nil {
+		log.Printf("Error decoding OTLP traces: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result := otlp.Translate(traces)
+
+	// Spans failing the validation of ingested events are rejected alone,
+	// so one bad span doesn't fail the export and get it retried forever
+	projectID := auth.ProjectID(r.Context())
+	events := make([]models.ToolCallEvent, 0, len(result.Events))
+	calls := make([]models.ToolCall, 0, len(result.Events))
+	for _, event := range result.Events {
+		err := validateEvent(event)
+		var call models.ToolCall
+		if err == nil {
+			call, err = h.newToolCall(r.Context(), projectID, event)
+		}
+		if err != nil {
+			result.RejectedSpans++
+			if result.ErrorMessage == "" {
+				result.ErrorMessage = fmt.Sprintf("span of tool %.64q: %v", event.ToolName, err)
+			}
+			continue
+		}
+		events = append(events, event)
+		calls = append(calls, call)
+	}
+
+	// Spans re-sent by a retrying exporter are deduplicated by their event ID
+	if len(calls) > 0 {
+		if _, err := h.storeCalls(r.Context(), projectID, events, calls); err != nil {
+			log.Printf("Error ingesting %d OTLP spans: %v", len(calls), err)
+			http.Error(w, "Failed to ingest spans", http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", encoding)
+	w.WriteHeader(http.StatusOK)
+	w.Write(otlp.EncodeResponse(encoding, result.RejectedSpans, result.ErrorMessage))
+}
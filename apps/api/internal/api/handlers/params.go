@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourorg/nous/internal/auth"
+	"github.com/yourorg/nous/internal/models"
+)
+
+// Bucket widths accepted by the interval parameter, smallest first
+var bucketIntervals = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	time.Hour,
+	6 * time.Hour,
+	24 * time.Hour,
+}
+
+const (
+	// Limits on metadata keys used in filters and group_by
+	maxMetadataKeyLength = 128
+
+	// Automatic intervals pick the smallest width yielding at most this
+	// many buckets, so the default 24 hour window stays hourly
+	maxAutoBuckets = 60
+
+	// Upper bound on buckets for an explicitly requested interval
+	maxBuckets = 5000
+)
+
+// parseMetricsQuery extracts the time range, filters and grouping shared by
+// all metrics endpoints, scoped to the request's project. The range is
+// given by from/to (RFC3339) or, as before, by hours back from now. When
+// bucketed is set, interval (1m, 5m, 15m, 1h, 6h, 1d or auto) and tz (IANA
+// timezone, default UTC) control how the range is bucketed.
+func parseMetricsQuery(r *http.Request, bucketed bool) (models.MetricsQuery, error) {
+	params := r.URL.Query()
+	q := models.MetricsQuery{ProjectID: auth.ProjectID(r.Context()), Location: time.UTC}
+
+	hours := time.Duration(parseHours(r)) * time.Hour
+	now := time.Now()
+
+	q.To = now
+	if value := params.Get("to"); value != "" {
+		to, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return q, fmt.Errorf("to must be an RFC3339 timestamp")
+		}
+		q.To = to
+	}
+
+	q.From = q.To.Add(-hours)
+	if value := params.Get("from"); value != "" {
+		from, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return q, fmt.Errorf("from must be an RFC3339 timestamp")
+		}
+		q.From = from
+	}
+
+	if !q.From.Before(q.To) {
+		return q, fmt.Errorf("from must be before to")
+	}
+
+	if value := params.Get("tz"); value != "" {
+		loc, err := time.LoadLocation(value)
+		if err != nil {
+			return q, fmt.Errorf("tz must be an IANA timezone such as Europe/Athens")
+		}
+		q.Location = loc
+	}
+
+	if err := parseMetricsFilter(r, &q); err != nil {
+		return q, err
+	}
+
+	if !bucketed {
+		return q, nil
+	}
+
+	switch value := params.Get("interval"); value {
+	case "", "auto":
+		q.Interval = autoInterval(q.Duration())
+	default:
+		interval, err := parseDuration(value)
+		if err != nil || !isBucketInterval(interval) {
+			return q, fmt.Errorf("interval must be one of 1m, 5m, 15m, 1h, 6h, 1d or auto")
+		}
+		q.Interval = interval
+	}
+
+	// Even daily buckets can't cover very long ranges
+	if q.Duration()/q.Interval > maxBuckets {
+		return q, fmt.Errorf("interval %s yields more than %d buckets for this range", formatInterval(q.Interval), maxBuckets)
+	}
+
+	return q, nil
+}
+
+// parseMetricsFilter extracts the dimension filters and group_by parameter:
+// tool_name, exclude_tool_name and model (comma-separated or repeated),
+// status, min_duration_ms, max_duration_ms, metadata.<key>=<value> and
+// group_by (tool_name, model or metadata.<key>)
+func parseMetricsFilter(r *http.Request, q *models.MetricsQuery) error {
+	params := r.URL.Query()
+	f := &q.Filter
+
+	f.ToolNames = listParam(params["tool_name"])
+	f.ExcludeToolNames = listParam(params["exclude_tool_name"])
+	f.Models = listParam(params["model"])
+
+	switch status := params.Get("status"); status {
+	case "", "success", "failed":
+		f.Status = status
+	default:
+		return fmt.Errorf("status must be 'success' or 'failed'")
+	}
+
+	for _, bound := range []struct {
+		name  string
+		value **int
+	}{
+		{"min_duration_ms", &f.MinDurationMs},
+		{"max_duration_ms", &f.MaxDurationMs},
+	} {
+		value := params.Get(bound.name)
+		if value == "" {
+			continue
+		}
+		ms, err := strconv.Atoi(value)
+		if err != nil || ms < 0 {
+			return fmt.Errorf("%s must be a non-negative integer", bound.name)
+		}
+		*bound.value = &ms
+	}
+	if f.MinDurationMs != nil && f.MaxDurationMs != nil && *f.MinDurationMs > *f.MaxDurationMs {
+		return fmt.Errorf("min_duration_ms must not exceed max_duration_ms")
+	}
+
+	for name, values := range params {
+		key, ok := strings.CutPrefix(name, models.GroupByMetadataPrefix)
+		if !ok {
+			continue
+		}
+		if !isMetadataKey(key) {
+			return fmt.Errorf("invalid metadata key %q", key)
+		}
+		if f.Metadata == nil {
+			f.Metadata = make(map[string]string)
+		}
+		f.Metadata[key] = values[0]
+	}
+
+	switch groupBy := params.Get("group_by"); {
+	case groupBy == "", groupBy == models.GroupByToolName, groupBy == models.GroupByModel:
+		q.GroupBy = groupBy
+	case strings.HasPrefix(groupBy, models.GroupByMetadataPrefix) &&
+		isMetadataKey(strings.TrimPrefix(groupBy, models.GroupByMetadataPrefix)):
+		q.GroupBy = groupBy
+	default:
+		return fmt.Errorf("group_by must be tool_name, model or metadata.<key>")
+	}
+
+	return nil
+}
+
+// listParam splits repeated and comma-separated parameter values, dropping
+// empty entries
+func listParam(values []string) []string {
+	var list []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+// isMetadataKey reports whether key is usable as a metadata filter or
+// group_by key: letters, digits, '_', '-' and '.'
+func isMetadataKey(key string) bool {
+	if key == "" || len(key) > maxMetadataKeyLength {
+		return false
+	}
+	for _, c := range key {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '_', c == '-', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// autoInterval picks the smallest bucket width that keeps the number of
+// buckets within maxAutoBuckets
+func autoInterval(span time.Duration) time.Duration {
+	for _, interval := range bucketIntervals {
+		if span/interval <= maxAutoBuckets {
+			return interval
+		}
+	}
+	return bucketIntervals[len(bucketIntervals)-1]
+}
+
+// isBucketInterval reports whether interval is an accepted bucket width
+func isBucketInterval(interval time.Duration) bool {
+	for _, allowed := range bucketIntervals {
+		if interval == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// formatInterval renders a bucket width the way the interval parameter accepts it
+func formatInterval(interval time.Duration) string {
+	switch {
+	case interval%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", interval/(24*time.Hour))
+	case interval%time.Hour == 0:
+		return fmt.Sprintf("%dh", interval/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", interval/time.Minute)
+	}
+}
+
+// parseCompareTo extracts the compare_to parameter: "previous" (the default,
+// the immediately preceding window) or an offset such as "1w", "7d" or "24h"
+func parseCompareTo(r *http.Request) (time.Duration, error) {
+	value := r.URL.Query().Get("compare_to")
+	if value == "" || value == "previous" {
+		return 0, nil
+	}
+
+	offset, err := parseDuration(value)
+	if err != nil || offset <= 0 {
+		return 0, fmt.Errorf("compare_to must be \"previous\" or a positive offset such as 1w, 7d or 24h")
+	}
+	return offset, nil
+}
+
+// parseDuration parses a Go duration, additionally accepting whole days
+// ("7d") and weeks ("1w")
+func parseDuration(value string) (time.Duration, error) {
+	if n := len(value); n > 1 {
+		unit := time.Duration(0)
+		switch value[n-1] {
+		case 'd':
+			unit = 24 * time.Hour
+		case 'w':
+			unit = 7 * 24 * time.Hour
+		}
+		if unit != 0 {
+			count, err := strconv.ParseInt(value[:n-1], 10, 64)
+			if err != nil {
+				return 0, err
+			}
+			if count > math.MaxInt64/int64(unit) || count < math.MinInt64/int64(unit) {
+				return 0, fmt.Errorf("duration %q is out of range", value)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	return time.ParseDuration(value)
+}
+
+// parseHours extracts hours parameter from query string, defaults to 24
+func parseHours(r *http.Request) int {
+	hours := 24
+	if hoursStr := r.URL.Query().Get("hours"); hoursStr != "" {
+		if parsed, err := strconv.Atoi(hoursStr); err == nil && parsed > 0 && int64(parsed) <= math.MaxInt64/int64(time.Hour) {
+			hours = parsed
+		}
+	}
+	return hours
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "90m", want: 90 * time.Minute},
+		{value: "24h", want: 24 * time.Hour},
+		{value: "7d", want: 7 * 24 * time.Hour},
+		{value: "1w", want: 7 * 24 * time.Hour},
+		{value: "0d", want: 0},
+		{value: "-1d", want: -24 * time.Hour},
+		{value: "106751d", want: 106751 * 24 * time.Hour},
+
+		// Days and weeks overflowing a Duration
+		{value: "106752d", wantErr: true},
+		{value: "15251w", wantErr: true},
+		{value: "-106752d", wantErr: true},
+		{value: "9223372036854775807w", wantErr: true},
+		{value: "9999999h", wantErr: true},
+
+		{value: "", wantErr: true},
+		{value: "d", wantErr: true},
+		{value: "1.5d", wantErr: true},
+		{value: "week", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDuration(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseDuration(%q) = %s, want an error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("parseDuration(%q) = %s, %v, want %s", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseCompareTo(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "", want: 0},
+		{value: "previous", want: 0},
+		{value: "1w", want: 7 * 24 * time.Hour},
+		{value: "24h", want: 24 * time.Hour},
+		{value: "0d", wantErr: true},
+		{value: "-24h", wantErr: true},
+		{value: "999999999w", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/metrics/overview?compare_to="+tt.value, nil)
+		got, err := parseCompareTo(r)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("compare_to=%s: got %s, want an error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("compare_to=%s: got %s, %v, want %s", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseMetricsQueryRange(t *testing.T) {
+	from := time.Date(2026, 3, 2, 10, 7, 30, 0, time.UTC)
+	at := func(offset time.Duration) string {
+		return from.Add(offset).Format(time.RFC3339)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		interval time.Duration
+		wantErr  bool
+	}{
+		{
+			name:     "at the bucket cap",
+			query:    "from=" + at(0) + "&to=" + at(maxBuckets*time.Minute) + "&interval=1m",
+			interval: time.Minute,
+		},
+		{
+			name:    "one bucket over the cap",
+			query:   "from=" + at(0) + "&to=" + at((maxBuckets+1)*time.Minute) + "&interval=1m",
+			wantErr: true,
+		},
+		{
+			name:     "wider interval under the cap",
+			query:    "from=" + at(0) + "&to=" + at((maxBuckets+1)*time.Minute) + "&interval=5m",
+			interval: 5 * time.Minute,
+		},
+		{
+			name:     "misaligned from and to",
+			query:    "from=" + at(0) + "&to=" + at(2*time.Hour+13*time.Second) + "&interval=15m",
+			interval: 15 * time.Minute,
+		},
+		{
+			name:     "automatic interval",
+			query:    "from=" + at(0) + "&to=" + at(24*time.Hour),
+			interval: time.Hour,
+		},
+		{name: "zero interval", query: "from=" + at(0) + "&to=" + at(time.Hour) + "&interval=0m", wantErr: true},
+		{name: "negative interval", query: "from=" + at(0) + "&to=" + at(time.Hour) + "&interval=-5m", wantErr: true},
+		{name: "unsupported interval", query: "from=" + at(0) + "&to=" + at(time.Hour) + "&interval=2m", wantErr: true},
+		{name: "overflowing interval", query: "from=" + at(0) + "&to=" + at(time.Hour) + "&interval=999999999999w", wantErr: true},
+		{name: "empty range", query: "from=" + at(0) + "&to=" + at(0), wantErr: true},
+		{name: "reversed range", query: "from=" + at(time.Hour) + "&to=" + at(0), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/metrics/timeseries?"+tt.query, nil)
+			q, err := parseMetricsQuery(r, true)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got interval %s, want an error", q.Interval)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if q.Interval != tt.interval {
+				t.Errorf("interval = %s, want %s", q.Interval, tt.interval)
+			}
+			if !q.From.Equal(from) {
+				t.Errorf("from = %s, want %s unaligned", q.From, from)
+			}
+		})
+	}
+}
+
+func TestParseHours(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{"", 24},
+		{"1", 1},
+		{"168", 168},
+		{"0", 24},
+		{"-3", 24},
+		{"abc", 24},
+
+		// More hours than a Duration holds
+		{"2562048", 24},
+		{"99999999999999", 24},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/metrics/overview?hours="+tt.value, nil)
+		if got := parseHours(r); got != tt.want {
+			t.Errorf("parseHours(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+// Maximum length of a project name
+const maxProjectNameLength = 255
+
+// CreateProject creates a project. Keys for it are then created with the
+// X-Project-ID header set to its ID.
+func (h *Handlers) CreateProject(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > maxProjectNameLength {
+		http.Error(w, "name is required and must be at most 255 characters", http.StatusBadRequest)
+		return
+	}
+
+	project := models.Project{
+		ID:        uuid.New(),
+		Name:      req.Name,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := h.repo.CreateProject(r.Context(), project); err != nil {
+		log.Printf("Error creating project: %v", err)
+		http.Error(w, "Failed to create project", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(project)
+}
+
+// ListProjects returns all projects
+func (h *Handlers) ListProjects(w http.ResponseWriter, r *http.Request) {
+	projects, err := h.repo.ListProjects(r.Context())
+	if err != nil {
+		log.Printf("Error listing projects: %v", err)
+		http.Error(w, "Failed to list projects", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(projects)
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/auth"
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+)
+
+// GetDataPolicies returns the deployment's retention and compression policies
+func (h *Handlers) GetDataPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.repo.GetDataPolicies(r.Context())
+	if errors.Is(err, store.ErrUnsupported) {
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching data policies: %v", err)
+		http.Error(w, "Failed to fetch data policies", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policies)
+}
+
+// UpdateDataPolicies changes the retention and compression policies given
+// in the body, keeping the others, and returns the resulting policies
+func (h *Handlers) UpdateDataPolicies(w http.ResponseWriter, r *http.Request) {
+	var req models.UpdateDataPoliciesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	current, err := h.repo.GetDataPolicies(r.Context())
+	if errors.Is(err, store.ErrUnsupported) {
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching data policies: %v", err)
+		http.Error(w, "Failed to update data policies", http.StatusInternalServerError)
+		return
+	}
+
+	policies := req.Apply(*current)
+	if err := policies.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.repo.SetDataPolicies(r.Context(), policies)
+	if errors.Is(err, store.ErrUnsupported) {
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating data policies: %v", err)
+		http.Error(w, "Failed to update data policies", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Data policies updated: raw retention %dd, rollup retention %dd, compress after %dd",
+		policies.RawRetentionDays, policies.RollupRetentionDays, policies.CompressAfterDays)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policies)
+}
+
+// DeleteToolCalls deletes the project's tool calls matching the request_id,
+// tool_name, from/to (RFC3339) and metadata.<key>=<value> parameters, e.g.
+// for data subject removal requests. At least one is required.
+func (h *Handlers) DeleteToolCalls(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseDeleteFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	projectID := auth.ProjectID(r.Context())
+	deleted, err := h.repo.DeleteToolCalls(r.Context(), projectID, filter)
+	if err != nil {
+		log.Printf("Error deleting tool calls: %v", err)
+		http.Error(w, "Failed to delete tool calls", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Deleted %d tool calls from project %s (%s)", deleted, projectID, r.URL.RawQuery)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int64{"deleted": deleted})
+}
+
+// parseDeleteFilter extracts a non-empty delete filter from query parameters
+func parseDeleteFilter(params url.Values) (models.DeleteFilter, error) {
+	var f models.DeleteFilter
+
+	if value := params.Get("request_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return f, fmt.Errorf("request_id must be a UUID")
+		}
+		f.RequestID = &id
+	}
+	f.ToolName = params.Get("tool_name")
+
+	for _, bound := range []struct {
+		name  string
+		value *time.Time
+	}{
+		{"from", &f.From},
+		{"to", &f.To},
+	} {
+		value := params.Get(bound.name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return f, fmt.Errorf("%s must be an RFC3339 timestamp", bound.name)
+		}
+		*bound.value = t
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return f, fmt.Errorf("from must be before to")
+	}
+
+	for name, values := range params {
+		key, ok := strings.CutPrefix(name, models.GroupByMetadataPrefix)
+		if !ok {
+			continue
+		}
+		if !isMetadataKey(key) {
+			return f, fmt.Errorf("invalid metadata key %q", key)
+		}
+		if f.Metadata == nil {
+			f.Metadata = make(map[string]string)
+		}
+		f.Metadata[key] = values[0]
+	}
+
+	if f.IsEmpty() {
+		return f, fmt.Errorf("at least one of request_id, tool_name, from, to or metadata.<key> is required")
+	}
+	return f, nil
+}
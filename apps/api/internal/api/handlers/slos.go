@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/auth"
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/slo"
+	"github.com/yourorg/nous/internal/store"
+)
+
+// defaultSLOWindowDays is the window of SLOs created without one
+const defaultSLOWindowDays = 28
+
+// ListSLOs returns the project's SLO definitions
+func (h *Handlers) ListSLOs(w http.ResponseWriter, r *http.Request) {
+	slos, err := h.repo.ListSLOs(r.Context(), auth.ProjectID(r.Context()))
+	if err != nil {
+		log.Printf("Error listing SLOs: %v", err)
+		http.Error(w, "Failed to list SLOs", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(slos)
+}
+
+// GetSLO returns one of the project's SLOs with its current attainment,
+// error budget and burn rates
+func (h *Handlers) GetSLO(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.slo(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := slo.Status(r.Context(), h.repo, *s, time.Now())
+	if err != nil {
+		log.Printf("Error computing SLO status: %v", err)
+		http.Error(w, "Failed to compute SLO status", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.SLOReport{SLO: *s, Status: *status})
+}
+
+// GetSLOBudget returns the error budget consumption of one of the project's
+// SLOs over its window, per bucket (interval: 1m, 5m, 15m, 1h, 6h, 1d or
+// auto; tz: IANA timezone)
+func (h *Handlers) GetSLOBudget(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.slo(w, r)
+	if !ok {
+		return
+	}
+
+	params := r.URL.Query()
+	to := time.Now()
+	q := models.MetricsQuery{From: to.Add(-s.Window()), To: to, Location: time.UTC}
+	if value := params.Get("tz"); value != "" {
+		loc, err := time.LoadLocation(value)
+		if err != nil {
+			http.Error(w, "tz must be an IANA timezone such as Europe/Athens", http.StatusBadRequest)
+			return
+		}
+		q.Location = loc
+	}
+	switch value := params.Get("interval"); value {
+	case "", "auto":
+		q.Interval = autoInterval(q.Duration())
+	default:
+		interval, err := parseDuration(value)
+		if err != nil || !isBucketInterval(interval) {
+			http.Error(w, "interval must be one of 1m, 5m, 15m, 1h, 6h, 1d or auto", http.StatusBadRequest)
+			return
+		}
+		if q.Duration()/interval > maxBuckets {
+			http.Error(w, fmt.Sprintf("interval %s yields more than %d buckets for this SLO's window", value, maxBuckets), http.StatusBadRequest)
+			return
+		}
+		q.Interval = interval
+	}
+
+	points, err := slo.Series(r.Context(), h.repo, *s, q)
+	if err != nil {
+		log.Printf("Error computing SLO budget series: %v", err)
+		http.Error(w, "Failed to compute SLO budget", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.SLOBudgetSeries{
+		SLOID:    s.ID,
+		From:     q.From.UTC(),
+		To:       q.To.UTC(),
+		Interval: formatInterval(q.Interval),
+		TimeZone: q.TimeZone(),
+		Points:   points,
+	})
+}
+
+// slo loads the SLO named by the sloId URL parameter, writing the error
+// response when that fails
+func (h *Handlers) slo(w http.ResponseWriter, r *http.Request) (*models.SLO, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "sloId"))
+	if err != nil {
+		http.Error(w, "Invalid SLO ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	s, err := h.repo.GetSLO(r.Context(), auth.ProjectID(r.Context()), id)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "SLO not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error fetching SLO: %v", err)
+		http.Error(w, "Failed to fetch SLO", http.StatusInternalServerError)
+		return nil, false
+	}
+	return s, true
+}
+
+// CreateSLO adds an SLO to the project
+func (h *Handlers) CreateSLO(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeSLORequest(w, r)
+	if !ok {
+		return
+	}
+
+	s := models.SLO{
+		ID:        uuid.New(),
+		ProjectID: auth.ProjectID(r.Context()),
+		CreatedAt: time.Now().UTC(),
+	}
+	applySLORequest(&s, req)
+
+	if err := h.repo.CreateSLO(r.Context(), s); err != nil {
+		log.Printf("Error creating SLO: %v", err)
+		http.Error(w, "Failed to create SLO", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("SLO %q created in project %s: %s %g%% over %d days", s.Name, s.ProjectID, s.Type, s.Objective, s.WindowDays)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(s)
+}
+
+// UpdateSLO replaces the definition of one of the project's SLOs
+func (h *Handlers) UpdateSLO(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.slo(w, r)
+	if !ok {
+		return
+	}
+	req, ok := decodeSLORequest(w, r)
+	if !ok {
+		return
+	}
+	applySLORequest(s, req)
+
+	err := h.repo.UpdateSLO(r.Context(), *s)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "SLO not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating SLO: %v", err)
+		http.Error(w, "Failed to update SLO", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
+
+// decodeSLORequest reads and validates an SLO body, writing the error
+// response when that fails
+func decodeSLORequest(w http.ResponseWriter, r *http.Request) (models.SLORequest, bool) {
+	var req models.SLORequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return req, false
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.WindowDays == 0 {
+		req.WindowDays = defaultSLOWindowDays
+	}
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return req, false
+	}
+	for key := range req.Filter.Metadata {
+		if !isMetadataKey(key) {
+			http.Error(w, fmt.Sprintf("invalid metadata key %q", key), http.StatusBadRequest)
+			return req, false
+		}
+	}
+	return req, true
+}
+
+// applySLORequest sets an SLO's definition from a validated request
+func applySLORequest(s *models.SLO, req models.SLORequest) {
+	s.Name = req.Name
+	s.Type = req.Type
+	s.Objective = req.Objective
+	s.LatencyThresholdMs = req.LatencyThresholdMs
+	s.WindowDays = req.WindowDays
+	s.Filter = req.Filter
+}
+
+// DeleteSLO removes one of the project's SLOs
+func (h *Handlers) DeleteSLO(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "sloId"))
+	if err != nil {
+		http.Error(w, "Invalid SLO ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.repo.DeleteSLO(r.Context(), auth.ProjectID(r.Context()), id)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "SLO not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error deleting SLO: %v", err)
+		http.Error(w, "Failed to delete SLO", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/auth"
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+	"github.com/yourorg/nous/internal/webhook"
+)
+
+const (
+	// Default and maximum number of webhook deliveries returned
+	defaultWebhookDeliveryLimit = 50
+	maxWebhookDeliveryLimit     = 1000
+)
+
+// ListWebhooks returns the project's webhooks, without their secrets
+func (h *Handlers) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	hooks, err := h.repo.ListWebhooks(r.Context(), auth.ProjectID(r.Context()))
+	if err != nil {
+		log.Printf("Error listing webhooks: %v", err)
+		http.Error(w, "Failed to list webhooks", http.StatusInternalServerError)
+		return
+	}
+	for i := range hooks {
+		hooks[i].Secret = ""
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hooks)
+}
+
+// GetWebhook returns one of the project's webhooks, without its secret
+func (h *Handlers) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	hook, ok := h.webhook(w, r)
+	if !ok {
+		return
+	}
+	hook.Secret = ""
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hook)
+}
+
+// webhook loads the webhook named by the webhookId URL parameter, writing
+// the error response when that fails
+func (h *Handlers) webhook(w http.ResponseWriter, r *http.Request) (*models.Webhook, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	hook, err := h.repo.GetWebhook(r.Context(), auth.ProjectID(r.Context()), id)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error fetching webhook: %v", err)
+		http.Error(w, "Failed to fetch webhook", http.StatusInternalServerError)
+		return nil, false
+	}
+	return hook, true
+}
+
+// CreateWebhook adds a webhook to the project. The response holds the
+// signing secret, which isn't returned again.
+func (h *Handlers) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		generated, err := webhook.GenerateSecret()
+		if err != nil {
+			log.Printf("Error generating webhook secret: %v", err)
+			http.Error(w, "Failed to create webhook", http.StatusInternalServerError)
+			return
+		}
+		secret = generated
+	}
+
+	hook := models.Webhook{
+		ID:        uuid.New(),
+		ProjectID: auth.ProjectID(r.Context()),
+		Name:      req.Name,
+		URL:       req.URL,
+		Events:    req.Events,
+		Secret:    secret,
+		Enabled:   req.Enabled == nil || *req.Enabled,
+		CreatedAt: time.Now().UTC(),
+	}
+	if hook.Events == nil {
+		hook.Events = []string{}
+	}
+
+	if err := h.repo.CreateWebhook(r.Context(), hook); err != nil {
+		log.Printf("Error creating webhook: %v", err)
+		http.Error(w, "Failed to create webhook", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Webhook %q created in project %s", hook.Name, hook.ProjectID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(hook)
+}
+
+// DeleteWebhook removes one of the project's webhooks and its delivery log
+func (h *Handlers) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.repo.DeleteWebhook(r.Context(), auth.ProjectID(r.Context()), id)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error deleting webhook: %v", err)
+		http.Error(w, "Failed to delete webhook", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries returns the most recent delivery attempts of one of
+// the project's webhooks, newest first (limit parameter, default 50)
+func (h *Handlers) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	hook, ok := h.webhook(w, r)
+	if !ok {
+		return
+	}
+
+	limit := defaultWebhookDeliveryLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxWebhookDeliveryLimit {
+			http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxWebhookDeliveryLimit), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	deliveries, err := h.repo.ListWebhookDeliveries(r.Context(), hook.ProjectID, hook.ID, limit)
+	if err != nil {
+		log.Printf("Error listing webhook deliveries: %v", err)
+		http.Error(w, "Failed to list webhook deliveries", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// TestWebhook sends a webhook.test event to one of the project's webhooks,
+// enabled or not, and returns the recorded attempt. Test events aren't
+// retried.
+func (h *Handlers) TestWebhook(w http.ResponseWriter, r *http.Request) {
+	if h.webhooks == nil {
+		http.Error(w, "Webhooks are not enabled", http.StatusServiceUnavailable)
+		return
+	}
+	hook, ok := h.webhook(w, r)
+	if !ok {
+		return
+	}
+
+	payload := webhook.NewPayload(hook.ProjectID, models.WebhookEventTest, map[string]interface{}{
+		"webhook_id": hook.ID,
+		"message":    "Test delivery from Nous",
+	})
+	delivery := h.webhooks.Deliver(r.Context(), *hook, payload, 1)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(delivery)
+}
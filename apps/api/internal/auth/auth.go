@@ -0,0 +1,328 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+)
+
+var (
+	// ErrMissingKey is returned when a request carries no API key
+	ErrMissingKey = errors.New("missing API key")
+
+	// ErrInvalidKey is returned for unknown or revoked API keys
+	ErrInvalidKey = errors.New("invalid API key")
+
+	// ErrForbidden is returned when a valid key lacks the required scope
+	ErrForbidden = errors.New("API key lacks the required scope")
+
+	// ErrWrongProject is returned when a request selects a project other
+	// than the one its key belongs to
+	ErrWrongProject = errors.New("API key does not belong to the selected project")
+
+	// ErrInvalidProject is returned for a malformed project selection
+	ErrInvalidProject = errors.New("project must be a project UUID")
+)
+
+// ProjectHeader selects the project of requests made without a
+// project-bound key (authentication disabled or the bootstrap admin key).
+// Clients that can't set headers use the project_id query parameter.
+const ProjectHeader = "X-Project-ID"
+
+const (
+	// Prefix of every generated key, so leaked keys are easy to recognize
+	keyPrefix = "nous_"
+
+	// Number of leading key characters stored for identification
+	displayPrefixLength = 12
+
+	// How long a looked-up key is trusted before it is checked again.
+	// Revocations through this process take effect immediately.
+	cacheTTL = 30 * time.Second
+)
+
+// KeyStore looks up API keys by the hash of their secret
+type KeyStore interface {
+	LookupAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error)
+}
+
+type contextKey struct{}
+
+// Access is the outcome of authorizing a request
+type Access struct {
+	// Key that authenticated the request, nil when authentication is disabled
+	Key *models.APIKey
+
+	// Project the request operates on
+	ProjectID uuid.UUID
+}
+
+// Global reports whether the request may act across projects, i.e. it
+// wasn't authenticated with a project-bound key
+func (a Access) Global() bool {
+	return a.Key == nil || a.Key.IsGlobal()
+}
+
+type cachedKey struct {
+	key     *models.APIKey
+	expires time.Time
+}
+
+// Authenticator checks API keys on incoming requests
+type Authenticator struct {
+	store   KeyStore
+	enabled bool
+
+	// Hash of the bootstrap admin key configured through the environment
+	adminHash string
+
+	mu    sync.Mutex
+	cache map[string]cachedKey
+}
+
+// New creates an authenticator backed by store. When enabled is false every
+// request is allowed, as before authentication existed.
+func New(store KeyStore, enabled bool) *Authenticator {
+	return &Authenticator{
+		store:   store,
+		enabled: enabled,
+		cache:   make(map[string]cachedKey),
+	}
+}
+
+// Enabled reports whether requests are authenticated
+func (a *Authenticator) Enabled() bool {
+	return a.enabled
+}
+
+// SetAdminKey configures a bootstrap key with the admin scope that isn't
+// stored in the database, used to create the first keys
+func (a *Authenticator) SetAdminKey(key string) {
+	if key == "" {
+		a.adminHash = ""
+		return
+	}
+	a.adminHash = HashKey(key)
+}
+
+// GenerateKey returns a new random API key and its display prefix
+func GenerateKey() (key, prefix string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	key = keyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	return key, key[:displayPrefixLength], nil
+}
+
+// HashKey returns the hex SHA-256 digest under which a key is stored. Keys
+// are long random strings, so a fast unsalted hash is sufficient.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Authenticate resolves the API key presented with r, taken from the
+// Authorization bearer token, the X-API-Key header or, for WebSocket and
+// EventSource clients that cannot set headers, the api_key query parameter
+func (a *Authenticator) Authenticate(r *http.Request) (*models.APIKey, error) {
+	secret := keyFromRequest(r)
+	if secret == "" {
+		return nil, ErrMissingKey
+	}
+	hash := HashKey(secret)
+
+	if a.adminHash != "" && subtle.ConstantTimeCompare([]byte(hash), []byte(a.adminHash)) == 1 {
+		return &models.APIKey{Name: "admin (environment)", Scopes: []string{models.ScopeAdmin}}, nil
+	}
+
+	a.mu.Lock()
+	cached, ok := a.cache[hash]
+	a.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.key, nil
+	}
+
+	key, err := a.store.LookupAPIKey(r.Context(), hash)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrInvalidKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	a.cache[hash] = cachedKey{key: key, expires: time.Now().Add(cacheTTL)}
+	a.mu.Unlock()
+
+	return key, nil
+}
+
+// Authorize authenticates r, checks that its key grants scope and resolves
+// the project it operates on: the key's project, or for global requests
+// the one selected through ProjectHeader (default project otherwise).
+// Scopes aren't checked when authentication is disabled.
+func (a *Authenticator) Authorize(r *http.Request, scope string) (Access, error) {
+	var access Access
+	if a.enabled {
+		key, err := a.Authenticate(r)
+		if err != nil {
+			return access, err
+		}
+		if !key.HasScope(scope) {
+			return access, ErrForbidden
+		}
+		access.Key = key
+	}
+
+	selected, err := projectFromRequest(r)
+	if err != nil {
+		return access, err
+	}
+
+	switch {
+	case !access.Global():
+		if selected != uuid.Nil && selected != access.Key.ProjectID {
+			return access, ErrWrongProject
+		}
+		access.ProjectID = access.Key.ProjectID
+	case selected != uuid.Nil:
+		access.ProjectID = selected
+	default:
+		access.ProjectID = models.DefaultProjectID
+	}
+
+	return access, nil
+}
+
+// Require returns middleware rejecting requests whose API key doesn't grant
+// scope, with 401 for missing or invalid keys and 403 for missing scopes.
+// The outcome is available to handlers through FromContext.
+func (a *Authenticator) Require(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			access, err := a.Authorize(r, scope)
+			if err != nil {
+				Error(w, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, access)))
+		})
+	}
+}
+
+// RequireGlobal is like Require, but additionally rejects keys bound to a
+// project, for operations spanning projects
+func (a *Authenticator) RequireGlobal(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return a.Require(scope)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !FromContext(r.Context()).Global() {
+				Error(w, ErrForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}))
+	}
+}
+
+// Guard returns a check for handlers that authorize requests themselves,
+// such as WebSocket upgrades. It writes the error response on failure and
+// returns the project the request operates on.
+func (a *Authenticator) Guard(scope string) func(w http.ResponseWriter, r *http.Request) (uuid.UUID, error) {
+	return func(w http.ResponseWriter, r *http.Request) (uuid.UUID, error) {
+		access, err := a.Authorize(r, scope)
+		if err != nil {
+			Error(w, err)
+			return uuid.Nil, err
+		}
+		return access.ProjectID, nil
+	}
+}
+
+// Forget drops a key from the lookup cache, e.g. after it was revoked
+func (a *Authenticator) Forget(id uuid.UUID) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for hash, cached := range a.cache {
+		if cached.key.ID == id {
+			delete(a.cache, hash)
+		}
+	}
+}
+
+// Error writes the HTTP response for an Authorize error
+func Error(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrMissingKey), errors.Is(err, ErrInvalidKey):
+		w.Header().Set("WWW-Authenticate", `Bearer realm="nous"`)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, ErrForbidden), errors.Is(err, ErrWrongProject):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, ErrInvalidProject):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("Error authenticating request: %v", err)
+		http.Error(w, "Failed to authenticate request", http.StatusInternalServerError)
+	}
+}
+
+// FromContext returns how the request was authorized. Requests that didn't
+// pass through Require operate on the default project.
+func FromContext(ctx context.Context) Access {
+	access, ok := ctx.Value(contextKey{}).(Access)
+	if !ok {
+		access.ProjectID = models.DefaultProjectID
+	}
+	return access
+}
+
+// ProjectID returns the project the request operates on
+func ProjectID(ctx context.Context) uuid.UUID {
+	return FromContext(ctx).ProjectID
+}
+
+// projectFromRequest returns the project selected by the request, or
+// uuid.Nil when none was selected
+func projectFromRequest(r *http.Request) (uuid.UUID, error) {
+	value := r.Header.Get(ProjectHeader)
+	if value == "" {
+		value = r.URL.Query().Get("project_id")
+	}
+	if value == "" {
+		return uuid.Nil, nil
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, ErrInvalidProject
+	}
+	return id, nil
+}
+
+// keyFromRequest extracts the presented API key, if any
+func keyFromRequest(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return r.URL.Query().Get("api_key")
+}
@@ -0,0 +1,401 @@
+// Package budget tracks token and cost usage against budgets as tool calls
+// are ingested, signalling when a budget is exceeded.
+package budget
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+)
+
+// DefaultSyncInterval is how often usage is recomputed from the database
+const DefaultSyncInterval = time.Minute
+
+// Store lists budgets and aggregates the usage they count
+type Store interface {
+	ListAllBudgets(ctx context.Context) ([]models.Budget, error)
+	GetCostTotals(ctx context.Context, q models.MetricsQuery) ([]models.CostGroup, error)
+}
+
+// Notifier is called when a budget's usage reaches its limit
+type Notifier func(projectID uuid.UUID, e models.BudgetExceeded)
+
+// Tracker keeps the usage of every budget in memory. Ingested calls are
+// added as they are accepted; the usage is recomputed from the database
+// periodically, which drops calls that left the rolling window and counts
+// calls ingested by other replicas. Calls the database doesn't have yet
+// (still buffered, or recorded while the recomputation runs) are added
+// back on top of it.
+type Tracker struct {
+	store  Store
+	notify Notifier
+
+	mu       sync.Mutex
+	projects map[uuid.UUID][]*tracked
+}
+
+// tracked is a budget with its usage per key ("" unless per-key)
+type tracked struct {
+	budget models.Budget
+	used   map[string]float64
+
+	// Usage of accepted calls not written to the database yet
+	buffered map[string]float64
+
+	// Usage of calls written since the current sync started, which its
+	// database totals may miss
+	sinceSync map[string]float64
+
+	// Keys whose usage reached the limit, so each crossing notifies once.
+	// Cleared by a sync finding the key back under the limit, i.e. once
+	// the spend left the rolling window or the limit was raised.
+	exceeded map[string]bool
+}
+
+// newTracked returns a budget without usage
+func newTracked(b models.Budget) *tracked {
+	return &tracked{
+		budget:    b,
+		used:      make(map[string]float64),
+		buffered:  make(map[string]float64),
+		sinceSync: make(map[string]float64),
+		exceeded:  make(map[string]bool),
+	}
+}
+
+// key returns the usage key a call counts against, and whether it counts
+// at all
+func (tb *tracked) key(call models.ToolCall) (string, bool) {
+	b := tb.budget
+	if b.ToolName != "" && call.ToolName != b.ToolName {
+		return "", false
+	}
+	if b.MetadataKey == "" {
+		return "", true
+	}
+	return store.MetadataText(call.Metadata, b.MetadataKey)
+}
+
+// New creates a tracker reporting exceeded budgets to notify, which may
+// be nil
+func New(store Store, notify Notifier) *Tracker {
+	return &Tracker{
+		store:    store,
+		notify:   notify,
+		projects: make(map[uuid.UUID][]*tracked),
+	}
+}
+
+// Run recomputes usage every interval, starting immediately
+func (t *Tracker) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		if err := t.Sync(ctx); err != nil {
+			log.Printf("Error syncing budget usage: %v", err)
+		}
+		cancel()
+
+		<-ticker.C
+	}
+}
+
+// Sync reloads the budgets and recomputes their usage from the database,
+// merged into the tracked usage: calls still buffered or written during
+// the sync are added to the database totals. Budgets found over their
+// limit don't notify: the crossing happened elsewhere or earlier.
+func (t *Tracker) Sync(ctx context.Context) error {
+	t.mu.Lock()
+	for _, tracked := range t.projects {
+		for _, tb := range tracked {
+			clear(tb.sinceSync)
+		}
+	}
+	t.mu.Unlock()
+
+	budgets, err := t.store.ListAllBudgets(ctx)
+	if err != nil {
+		return err
+	}
+
+	synced := make([]*tracked, 0, len(budgets))
+	for _, b := range budgets {
+		tb, err := t.load(ctx, b)
+		if err != nil {
+			return err
+		}
+		synced = append(synced, tb)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	projects := make(map[uuid.UUID][]*tracked)
+	for _, tb := range synced {
+		if current := t.find(tb.budget); current != nil {
+			current.merge(tb)
+			tb = current
+		}
+		projects[tb.budget.ProjectID] = append(projects[tb.budget.ProjectID], tb)
+	}
+	t.projects = projects
+	return nil
+}
+
+// merge replaces the usage with a sync's database totals plus the usage
+// the database doesn't have yet, keeping which keys already notified
+func (tb *tracked) merge(synced *tracked) {
+	tb.budget = synced.budget
+
+	used := synced.used
+	for key, amount := range tb.buffered {
+		used[key] += amount
+	}
+	for key, amount := range tb.sinceSync {
+		used[key] += amount
+	}
+	tb.used = used
+
+	for key := range tb.exceeded {
+		if used[key] < tb.budget.Limit {
+			delete(tb.exceeded, key)
+		}
+	}
+	for key, amount := range used {
+		if amount >= tb.budget.Limit {
+			tb.exceeded[key] = true
+		}
+	}
+}
+
+// Add starts tracking a new budget
+func (t *Tracker) Add(ctx context.Context, b models.Budget) error {
+	tb, err := t.load(ctx, b)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.projects[b.ProjectID] = append(t.projects[b.ProjectID], tb)
+	t.mu.Unlock()
+	return nil
+}
+
+// Remove stops tracking a deleted budget
+func (t *Tracker) Remove(projectID, id uuid.UUID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	kept := t.projects[projectID][:0]
+	for _, tb := range t.projects[projectID] {
+		if tb.budget.ID != id {
+			kept = append(kept, tb)
+		}
+	}
+	t.projects[projectID] = kept
+}
+
+// load computes a budget's usage over its current window
+func (t *Tracker) load(ctx context.Context, b models.Budget) (*tracked, error) {
+	now := time.Now()
+	q := models.MetricsQuery{
+		ProjectID: b.ProjectID,
+		From:      now.Add(-b.Window()),
+		To:        now,
+	}
+	if b.ToolName != "" {
+		q.Filter.ToolNames = []string{b.ToolName}
+	}
+	if b.MetadataKey != "" {
+		q.GroupBy = models.GroupByMetadataPrefix + b.MetadataKey
+	}
+
+	groups, err := t.store.GetCostTotals(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	tb := newTracked(b)
+	for _, g := range groups {
+		// Calls without the metadata key don't count
+		if b.MetadataKey != "" && g.Group == "" {
+			continue
+		}
+
+		used := g.CostUSD
+		if b.Metric == models.BudgetMetricTokens {
+			used = float64(g.InputTokens + g.OutputTokens)
+		}
+		tb.used[g.Group] = used
+		tb.exceeded[g.Group] = used >= b.Limit
+	}
+	return tb, nil
+}
+
+// Record adds written tool calls of a project to the usage of the budgets
+// they count against, notifying for each budget they push over its limit
+func (t *Tracker) Record(projectID uuid.UUID, calls []models.ToolCall) {
+	t.record(projectID, calls, false)
+}
+
+// RecordBuffered is Record for calls accepted into the ingestion pipeline
+// but not written yet. The pipeline reports their outcome to Flushed or
+// Dropped.
+func (t *Tracker) RecordBuffered(projectID uuid.UUID, calls []models.ToolCall) {
+	t.record(projectID, calls, true)
+}
+
+// Flushed moves buffered calls written by the ingestion pipeline to the
+// usage the database has
+func (t *Tracker) Flushed(calls []models.ToolCall) {
+	t.settle(calls, func(tb *tracked, key string, amount float64) {
+		tb.buffered[key] -= amount
+		tb.sinceSync[key] += amount
+	})
+}
+
+// Dropped removes buffered calls the ingestion pipeline failed to write
+// from the usage
+func (t *Tracker) Dropped(calls []models.ToolCall) {
+	t.settle(calls, func(tb *tracked, key string, amount float64) {
+		tb.buffered[key] -= amount
+		tb.used[key] -= amount
+	})
+}
+
+// settle applies the outcome of buffered calls to the usage of each budget
+// they count against
+func (t *Tracker) settle(calls []models.ToolCall, apply func(tb *tracked, key string, amount float64)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, call := range calls {
+		for _, tb := range t.projects[call.ProjectID] {
+			if key, ok := tb.key(call); ok {
+				apply(tb, key, tb.budget.Amount(call))
+			}
+		}
+	}
+}
+
+// record adds calls to the usage, and to the buffered usage when they
+// aren't written yet
+func (t *Tracker) record(projectID uuid.UUID, calls []models.ToolCall, buffered bool) {
+	var crossed []models.BudgetExceeded
+
+	t.mu.Lock()
+	for _, tb := range t.projects[projectID] {
+		b := tb.budget
+		for _, call := range calls {
+			key, ok := tb.key(call)
+			if !ok {
+				continue
+			}
+
+			amount := b.Amount(call)
+			tb.used[key] += amount
+			if buffered {
+				tb.buffered[key] += amount
+			} else {
+				tb.sinceSync[key] += amount
+			}
+			if tb.used[key] >= b.Limit && !tb.exceeded[key] {
+				tb.exceeded[key] = true
+				crossed = append(crossed, models.BudgetExceeded{
+					BudgetID:    b.ID,
+					Name:        b.Name,
+					Metric:      b.Metric,
+					Limit:       b.Limit,
+					Used:        tb.used[key],
+					WindowHours: b.WindowHours,
+					ToolName:    b.ToolName,
+					MetadataKey: b.MetadataKey,
+					Key:         key,
+					ExceededAt:  time.Now().UTC(),
+				})
+			}
+		}
+	}
+	t.mu.Unlock()
+
+	for _, e := range crossed {
+		log.Printf("Budget %q of project %s exceeded: %g/%g %s (key %q)", e.Name, projectID, e.Used, e.Limit, e.Metric, e.Key)
+		if t.notify != nil {
+			t.notify(projectID, e)
+		}
+	}
+}
+
+// Status returns a budget's current usage, for every key with usage
+func (t *Tracker) Status(b models.Budget) models.BudgetStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status := models.BudgetStatus{Budget: b, Usage: []models.BudgetUsage{}}
+	if tb := t.find(b); tb != nil {
+		for key, used := range tb.used {
+			status.Usage = append(status.Usage, models.NewBudgetUsage(b, key, used))
+		}
+	}
+	if b.MetadataKey == "" && len(status.Usage) == 0 {
+		status.Usage = append(status.Usage, models.NewBudgetUsage(b, "", 0))
+	}
+
+	// Most used first
+	sort.Slice(status.Usage, func(i, j int) bool {
+		if status.Usage[i].Used != status.Usage[j].Used {
+			return status.Usage[i].Used > status.Usage[j].Used
+		}
+		return status.Usage[i].Key < status.Usage[j].Key
+	})
+	return status
+}
+
+// Check reports the project's budgets applying to a caller and whether
+// any is exceeded. Tool budgets apply when toolName matches or is empty;
+// per-key budgets apply when metadata holds their key, and report only
+// that key's usage.
+func (t *Tracker) Check(projectID uuid.UUID, toolName string, metadata map[string]string) models.BudgetCheck {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	check := models.BudgetCheck{Budgets: []models.BudgetStatus{}}
+	for _, tb := range t.projects[projectID] {
+		b := tb.budget
+		if b.ToolName != "" && toolName != "" && b.ToolName != toolName {
+			continue
+		}
+		key := ""
+		if b.MetadataKey != "" {
+			value, ok := metadata[b.MetadataKey]
+			if !ok {
+				continue
+			}
+			key = value
+		}
+
+		usage := models.NewBudgetUsage(b, key, tb.used[key])
+		check.Budgets = append(check.Budgets, models.BudgetStatus{Budget: b, Usage: []models.BudgetUsage{usage}})
+		check.Exceeded = check.Exceeded || usage.Exceeded
+	}
+	return check
+}
+
+// find returns the tracked copy of a budget, or nil; t.mu must be held
+func (t *Tracker) find(b models.Budget) *tracked {
+	for _, tb := range t.projects[b.ProjectID] {
+		if tb.budget.ID == b.ID {
+			return tb
+		}
+	}
+	return nil
+}
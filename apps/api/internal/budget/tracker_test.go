@@ -0,0 +1,140 @@
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store/memory"
+)
+
+// call returns a tool call of project using tokens
+func call(projectID uuid.UUID, tokens int) models.ToolCall {
+	return models.ToolCall{
+		ID:          uuid.New(),
+		ProjectID:   projectID,
+		RequestID:   uuid.New(),
+		ToolName:    "search_web",
+		Status:      "success",
+		InputTokens: tokens,
+		CreatedAt:   time.Now().UTC(),
+	}
+}
+
+// used returns the usage the tracker reports for an unkeyed budget
+func used(t *Tracker, b models.Budget) float64 {
+	return t.Status(b).Usage[0].Used
+}
+
+func TestSyncKeepsUnwrittenUsageAndExceeded(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	projectID := uuid.New()
+
+	b := models.Budget{
+		ID:          uuid.New(),
+		ProjectID:   projectID,
+		Name:        "Tokens",
+		Metric:      models.BudgetMetricTokens,
+		Limit:       100,
+		WindowHours: 1,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := repo.CreateBudget(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+
+	var notified int
+	tracker := New(repo, func(uuid.UUID, models.BudgetExceeded) { notified++ })
+	if err := tracker.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// A written call, then a buffered one crossing the limit
+	written := call(projectID, 80)
+	if err := repo.IngestToolCalls(ctx, []models.ToolCall{written}); err != nil {
+		t.Fatal(err)
+	}
+	tracker.Record(projectID, []models.ToolCall{written})
+	buffered := call(projectID, 30)
+	tracker.RecordBuffered(projectID, []models.ToolCall{buffered})
+	if notified != 1 {
+		t.Fatalf("notified %d times, want 1", notified)
+	}
+
+	// The database lacks the buffered call, which still counts after a
+	// sync, and the crossing isn't signalled again
+	if err := tracker.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := used(tracker, b); got != 110 {
+		t.Errorf("used after sync = %g, want 110", got)
+	}
+	more := call(projectID, 5)
+	repo.IngestToolCalls(ctx, []models.ToolCall{more})
+	tracker.Record(projectID, []models.ToolCall{more})
+	if notified != 1 {
+		t.Errorf("notified %d times after sync, want 1", notified)
+	}
+
+	// Once written, the buffered call is counted from the database
+	repo.IngestToolCalls(ctx, []models.ToolCall{buffered})
+	tracker.Flushed([]models.ToolCall{buffered})
+	if err := tracker.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := used(tracker, b); got != 115 {
+		t.Errorf("used after flush and sync = %g, want 115", got)
+	}
+}
+
+func TestSyncClearsExceededWhenLimitRaised(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	projectID := uuid.New()
+
+	b := models.Budget{
+		ID:          uuid.New(),
+		ProjectID:   projectID,
+		Name:        "Tokens",
+		Metric:      models.BudgetMetricTokens,
+		Limit:       100,
+		WindowHours: 1,
+		CreatedAt:   time.Now().UTC(),
+	}
+	repo.CreateBudget(ctx, b)
+
+	var notified int
+	tracker := New(repo, func(uuid.UUID, models.BudgetExceeded) { notified++ })
+	tracker.Sync(ctx)
+
+	over := call(projectID, 120)
+	repo.IngestToolCalls(ctx, []models.ToolCall{over})
+	tracker.Record(projectID, []models.ToolCall{over})
+
+	// A dropped buffered call no longer counts
+	dropped := call(projectID, 50)
+	tracker.RecordBuffered(projectID, []models.ToolCall{dropped})
+	tracker.Dropped([]models.ToolCall{dropped})
+	if got := used(tracker, b); got != 120 {
+		t.Errorf("used after drop = %g, want 120", got)
+	}
+
+	// Raising the limit above the usage lets the budget be crossed again
+	repo.DeleteBudget(ctx, projectID, b.ID)
+	b.Limit = 200
+	repo.CreateBudget(ctx, b)
+	if err := tracker.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	again := call(projectID, 100)
+	repo.IngestToolCalls(ctx, []models.ToolCall{again})
+	tracker.Record(projectID, []models.ToolCall{again})
+	if notified != 2 {
+		t.Errorf("notified %d times, want 2", notified)
+	}
+}
@@ -2,57 +2,115 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"path/filepath"
+	"os"
 
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/yourorg/nous/migrations"
 )
 
-// Migrate runs database migrations using golang-migrate
-func Migrate(ctx context.Context, db *pgxpool.Pool) error {
-	// Get the connection config from pgxpool
-	config := db.Config()
+// Migrator runs one backend's embedded migrations against a database
+type Migrator struct {
+	m *migrate.Migrate
 
-	// Use stdlib to open a database/sql connection from pgx config
-	// This allows golang-migrate to work with pgxpool
-	sqlDB := stdlib.OpenDB(*config.ConnConfig)
-	defer sqlDB.Close()
+	// Directory of the backend's migrations in migrations.FS
+	dir string
+}
+
+// MigrationStatus is an embedded migration and whether it is applied
+type MigrationStatus struct {
+	Version uint   `json:"version"`
+	Name    string `json:"name"`
+	Applied bool   `json:"applied"`
+}
 
-	// Verify connection
-	if err := sqlDB.Ping(); err != nil {
-		return fmt.Errorf("failed to ping database: %w", err)
+// NewPostgresMigrator creates a migrator for a PostgreSQL database.
+// Closing the migrator closes db.
+func NewPostgresMigrator(db *sql.DB) (*Migrator, error) {
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create postgres driver: %w", err)
 	}
+	return newMigrator(migrations.PostgresDir, "postgres", driver)
+}
 
-	// Create postgres driver instance
-	driver, err := postgres.WithInstance(sqlDB, &postgres.Config{})
+// NewSQLiteMigrator creates a migrator for a SQLite database. Closing the
+// migrator closes db.
+func NewSQLiteMigrator(db *sql.DB) (*Migrator, error) {
+	driver, err := sqlite3.WithInstance(db, &sqlite3.Config{})
 	if err != nil {
-		return fmt.Errorf("failed to create postgres driver: %w", err)
+		return nil, fmt.Errorf("failed to create sqlite driver: %w", err)
 	}
+	return newMigrator(migrations.SQLiteDir, "sqlite3", driver)
+}
 
-	// Get absolute path to migrations directory
-	migrationsPath, err := filepath.Abs("migrations")
+// newMigrator creates a migrator reading the migrations in dir
+func newMigrator(dir, databaseName string, driver database.Driver) (*Migrator, error) {
+	source, err := iofs.New(migrations.FS, dir)
 	if err != nil {
-		return fmt.Errorf("failed to get migrations path: %w", err)
+		return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
 	}
 
-	// Create migrate instance
-	m, err := migrate.NewWithDatabaseInstance(
-		fmt.Sprintf("file://%s", migrationsPath),
-		"postgres",
-		driver,
-	)
+	m, err := migrate.NewWithInstance("iofs", source, databaseName, driver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
+	}
+
+	return &Migrator{m: m, dir: dir}, nil
+}
+
+// OpenPostgres opens a database/sql connection from a pgx pool's config,
+// which golang-migrate needs
+func OpenPostgres(ctx context.Context, db *pgxpool.Pool) (*sql.DB, error) {
+	sqlDB := stdlib.OpenDB(*db.Config().ConnConfig)
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	return sqlDB, nil
+}
+
+// Migrate runs the PostgreSQL migrations using golang-migrate
+func Migrate(ctx context.Context, db *pgxpool.Pool) error {
+	sqlDB, err := OpenPostgres(ctx, db)
 	if err != nil {
-		return fmt.Errorf("failed to create migrate instance: %w", err)
+		return err
 	}
 
-	// Run migrations
-	if err := m.Up(); err != nil {
+	m, err := NewPostgresMigrator(sqlDB)
+	if err != nil {
+		sqlDB.Close()
+		return err
+	}
+	defer m.Close()
+
+	return m.Up()
+}
+
+// MigrateSQLite runs the SQLite migrations. db stays open.
+func MigrateSQLite(db *sql.DB) error {
+	m, err := NewSQLiteMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	return m.Up()
+}
+
+// Up applies all pending migrations
+func (m *Migrator) Up() error {
+	if err := m.m.Up(); err != nil {
 		// ErrNoChange is not an error - it means we're already up to date
-		if err == migrate.ErrNoChange {
+		if errors.Is(err, migrate.ErrNoChange) {
 			fmt.Println("Database is up to date, no migrations to apply")
 			return nil
 		}
@@ -62,3 +120,99 @@ func Migrate(ctx context.Context, db *pgxpool.Pool) error {
 	fmt.Println("Migrations applied successfully")
 	return nil
 }
+
+// Down rolls back the given number of applied migrations, or all of them
+// when steps is 0
+func (m *Migrator) Down(steps int) error {
+	var err error
+	if steps > 0 {
+		err = m.m.Steps(-steps)
+	} else {
+		err = m.m.Down()
+	}
+	if err != nil {
+		if errors.Is(err, migrate.ErrNoChange) {
+			fmt.Println("No migrations to roll back")
+			return nil
+		}
+		return fmt.Errorf("failed to roll back migrations: %w", err)
+	}
+
+	fmt.Println("Migrations rolled back successfully")
+	return nil
+}
+
+// Force sets the migration version without running migrations and clears
+// the dirty flag, e.g. after fixing a failed migration by hand. Version -1
+// means no migration is applied.
+func (m *Migrator) Force(version int) error {
+	if err := m.m.Force(version); err != nil {
+		return fmt.Errorf("failed to force version %d: %w", version, err)
+	}
+	return nil
+}
+
+// Version returns the applied migration version, 0 when none is, and
+// whether the last migration failed part way (dirty)
+func (m *Migrator) Version() (uint, bool, error) {
+	version, dirty, err := m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Status lists the embedded migrations, oldest first, and whether each is
+// applied
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	current, _, err := m.Version()
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := iofs.New(migrations.FS, m.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+	defer source.Close()
+
+	var statuses []MigrationStatus
+	version, err := source.First()
+	for err == nil {
+		status := MigrationStatus{Version: version, Applied: current != 0 && version <= current}
+		if r, name, readErr := source.ReadUp(version); readErr == nil {
+			r.Close()
+			status.Name = name
+		}
+		statuses = append(statuses, status)
+
+		version, err = source.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	return statuses, nil
+}
+
+// Pending returns the number of embedded migrations not yet applied
+func (m *Migrator) Pending() (int, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		if !status.Applied {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// Close releases the migrator and the database it was created on
+func (m *Migrator) Close() error {
+	sourceErr, databaseErr := m.m.Close()
+	return errors.Join(sourceErr, databaseErr)
+}
@@ -0,0 +1,150 @@
+// Package errortypes groups failed tool calls into error types and reports
+// the first occurrence of each.
+package errortypes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+const (
+	// Maximum number of fingerprints remembered before the cache is reset
+	maxCached = 10000
+
+	// Length of the normalized message prefix identifying an error type
+	maxFingerprintLength = 200
+
+	// Length of the stored sample message
+	maxMessageLength = 1000
+)
+
+var (
+	uuidPattern   = regexp.MustCompile(`(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	hexPattern    = regexp.MustCompile(`(?i)\b0x[0-9a-f]+\b|\b[0-9a-f]{16,}\b`)
+	numberPattern = regexp.MustCompile(`\d+`)
+)
+
+// Store records the error types seen so far
+type Store interface {
+	RecordErrorType(ctx context.Context, errorType models.ErrorType) (bool, error)
+}
+
+// Notifier is called for the first occurrence of an error type
+type Notifier func(errorType models.ErrorType)
+
+// Detector finds the error types of accepted tool calls. Fingerprints
+// already seen are cached, so only unseen ones reach the store, which
+// decides whether they are new across restarts and replicas.
+type Detector struct {
+	store  Store
+	notify Notifier
+
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+// New creates a detector reporting new error types to notify, which may be
+// nil
+func New(store Store, notify Notifier) *Detector {
+	return &Detector{
+		store:  store,
+		notify: notify,
+		seen:   make(map[string]bool),
+	}
+}
+
+// Observe looks for unseen error types among a project's accepted calls and
+// records them in the background
+func (d *Detector) Observe(projectID uuid.UUID, calls []models.ToolCall) {
+	var unseen []models.ErrorType
+
+	d.mu.Lock()
+	for _, call := range calls {
+		if call.Status != "failed" || call.ErrorMessage == nil || *call.ErrorMessage == "" {
+			continue
+		}
+
+		fingerprint := Fingerprint(call.ToolName, *call.ErrorMessage)
+		key := projectID.String() + "/" + fingerprint
+		if d.seen[key] {
+			continue
+		}
+		if len(d.seen) >= maxCached {
+			d.seen = make(map[string]bool)
+		}
+		d.seen[key] = true
+
+		unseen = append(unseen, models.ErrorType{
+			ProjectID:   projectID,
+			ToolName:    call.ToolName,
+			Fingerprint: fingerprint,
+			Message:     truncate(*call.ErrorMessage, maxMessageLength),
+			FirstSeenAt: call.CreatedAt.UTC(),
+		})
+	}
+	d.mu.Unlock()
+
+	if len(unseen) > 0 {
+		go d.record(unseen)
+	}
+}
+
+// record stores unseen error types, notifying for those that are new.
+// Types failing to record are forgotten, so they are tried again.
+func (d *Detector) record(errorTypes []models.ErrorType) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, e := range errorTypes {
+		created, err := d.store.RecordErrorType(ctx, e)
+		if err != nil {
+			log.Printf("Error recording error type of %s: %v", e.ToolName, err)
+			d.mu.Lock()
+			delete(d.seen, e.ProjectID.String()+"/"+e.Fingerprint)
+			d.mu.Unlock()
+			continue
+		}
+		if !created {
+			continue
+		}
+
+		log.Printf("New error type for %s in project %s: %s", e.ToolName, e.ProjectID, e.Message)
+		if d.notify != nil {
+			d.notify(e)
+		}
+	}
+}
+
+// Fingerprint identifies the error type of a tool's error message: messages
+// differing only in numbers, IDs, case or whitespace share a fingerprint
+func Fingerprint(toolName, message string) string {
+	normalized := uuidPattern.ReplaceAllString(message, "<id>")
+	normalized = hexPattern.ReplaceAllString(normalized, "<hex>")
+	normalized = numberPattern.ReplaceAllString(normalized, "#")
+	normalized = strings.ToLower(strings.Join(strings.Fields(normalized), " "))
+
+	sum := sha256.Sum256([]byte(toolName + "\x00" + truncate(normalized, maxFingerprintLength)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// truncate shortens s to at most n bytes without splitting a character
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,277 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+var (
+	// ErrQueueFull is returned by Enqueue when the buffer has no free capacity
+	ErrQueueFull = errors.New("ingest queue is full")
+
+	// ErrClosed is returned by Enqueue once the pipeline is shutting down
+	ErrClosed = errors.New("ingest pipeline is closed")
+)
+
+// Writer persists batches of tool calls, and forgets the event IDs of
+// batches that couldn't be written so their retries aren't taken for
+// replays
+type Writer interface {
+	IngestToolCalls(ctx context.Context, calls []models.ToolCall) error
+	ReleaseEventIDs(ctx context.Context, projectID uuid.UUID, ids []string) error
+}
+
+// Listener is told the outcome of buffered tool calls: written, or dropped
+// after the flush retries ran out
+type Listener interface {
+	Flushed(calls []models.ToolCall)
+	Dropped(calls []models.ToolCall)
+}
+
+// Config controls buffering and flushing behaviour
+type Config struct {
+	// Maximum number of events waiting to be written
+	BufferSize int
+
+	// Flush as soon as this many events are buffered
+	BatchSize int
+
+	// Flush whatever is buffered at least this often
+	FlushInterval time.Duration
+
+	// Number of times a failed flush is retried before the batch is dropped
+	MaxRetries int
+
+	// Time allowed for a single flush attempt
+	WriteTimeout time.Duration
+}
+
+// DefaultConfig returns the configuration used when nothing is overridden
+func DefaultConfig() Config {
+	return Config{
+		BufferSize:    10000,
+		BatchSize:     500,
+		FlushInterval: 200 * time.Millisecond,
+		MaxRetries:    3,
+		WriteTimeout:  10 * time.Second,
+	}
+}
+
+// Stats is a point-in-time snapshot of the pipeline
+type Stats struct {
+	QueueDepth    int   `json:"queue_depth"`
+	QueueCapacity int   `json:"queue_capacity"`
+	Written       int64 `json:"written"`
+	Dropped       int64 `json:"dropped"`
+	Rejected      int64 `json:"rejected"`
+}
+
+// Pipeline buffers events in memory and writes them to the store in
+// batches, so request latency doesn't depend on database latency
+type Pipeline struct {
+	writer Writer
+	config Config
+
+	queue chan models.ToolCall
+	done  chan struct{}
+
+	// Guards closing the queue against concurrent Enqueue calls, and the
+	// listener
+	mu       sync.RWMutex
+	closed   bool
+	listener Listener
+
+	written  atomic.Int64
+	dropped  atomic.Int64
+	rejected atomic.Int64
+}
+
+// New creates a pipeline writing to writer. Call Run to start flushing.
+func New(writer Writer, config Config) *Pipeline {
+	defaults := DefaultConfig()
+	if config.BufferSize <= 0 {
+		config.BufferSize = defaults.BufferSize
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaults.FlushInterval
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = defaults.WriteTimeout
+	}
+
+	return &Pipeline{
+		writer: writer,
+		config: config,
+		queue:  make(chan models.ToolCall, config.BufferSize),
+		done:   make(chan struct{}),
+	}
+}
+
+// Run starts the pipeline's flush loop. It returns once Close has been
+// called and all buffered events have been written.
+func (p *Pipeline) Run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.ToolCall, 0, p.config.BatchSize)
+	for {
+		select {
+		case call, ok := <-p.queue:
+			if !ok {
+				p.flush(batch)
+				return
+			}
+			batch = append(batch, call)
+			if len(batch) >= p.config.BatchSize {
+				p.flush(batch)
+				batch = batch[:0]
+			}
+
+		case <-ticker.C:
+			if len(batch) > 0 {
+				p.flush(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// SetListener sets the listener told about written and dropped calls
+func (p *Pipeline) SetListener(listener Listener) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.listener = listener
+}
+
+// Enqueue buffers a tool call for writing without blocking. It returns
+// ErrQueueFull when the buffer is at capacity.
+func (p *Pipeline) Enqueue(call models.ToolCall) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	select {
+	case p.queue <- call:
+		return nil
+	default:
+		p.rejected.Add(1)
+		return ErrQueueFull
+	}
+}
+
+// RetryAfter suggests how long a rejected client should wait before retrying
+func (p *Pipeline) RetryAfter() time.Duration {
+	if p.config.FlushInterval < time.Second {
+		return time.Second
+	}
+	return p.config.FlushInterval
+}
+
+// Stats returns current queue depth and counters
+func (p *Pipeline) Stats() Stats {
+	return Stats{
+		QueueDepth:    len(p.queue),
+		QueueCapacity: cap(p.queue),
+		Written:       p.written.Load(),
+		Dropped:       p.dropped.Load(),
+		Rejected:      p.rejected.Load(),
+	}
+}
+
+// Close stops accepting events and waits for buffered events to be written
+func (p *Pipeline) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// flush writes a batch, retrying with exponential backoff
+func (p *Pipeline) flush(batch []models.ToolCall) {
+	if len(batch) == 0 {
+		return
+	}
+
+	backoff := 100 * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), p.config.WriteTimeout)
+		err := p.writer.IngestToolCalls(ctx, batch)
+		cancel()
+
+		if err == nil {
+			p.written.Add(int64(len(batch)))
+			if listener := p.currentListener(); listener != nil {
+				listener.Flushed(batch)
+			}
+			return
+		}
+
+		if attempt >= p.config.MaxRetries {
+			p.dropped.Add(int64(len(batch)))
+			log.Printf("Dropping %d events after %d failed flush attempts: %v", len(batch), attempt+1, err)
+			p.release(batch)
+			if listener := p.currentListener(); listener != nil {
+				listener.Dropped(batch)
+			}
+			return
+		}
+
+		log.Printf("Error flushing %d events (attempt %d): %v", len(batch), attempt+1, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// release forgets the event IDs of a dropped batch, which were claimed when
+// its events were accepted
+func (p *Pipeline) release(batch []models.ToolCall) {
+	ids := make(map[uuid.UUID][]string)
+	for _, call := range batch {
+		if call.EventID != nil {
+			ids[call.ProjectID] = append(ids[call.ProjectID], *call.EventID)
+		}
+	}
+
+	for projectID, projectIDs := range ids {
+		ctx, cancel := context.WithTimeout(context.Background(), p.config.WriteTimeout)
+		if err := p.writer.ReleaseEventIDs(ctx, projectID, projectIDs); err != nil {
+			log.Printf("Error releasing %d event IDs of dropped events: %v", len(projectIDs), err)
+		}
+		cancel()
+	}
+}
+
+// currentListener returns the listener, if one was set
+func (p *Pipeline) currentListener() Listener {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.listener
+}
@@ -0,0 +1,175 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Alert metrics
+const (
+	AlertMetricFailureRate = "failure_rate"   // percentage of failed calls
+	AlertMetricCallCount   = "call_count"     // number of calls
+	AlertMetricTotalTokens = "total_tokens"   // input plus output tokens
+	AlertMetricAvgLatency  = "avg_latency_ms" // mean duration
+	AlertMetricP50Latency  = "p50_latency_ms" // duration percentiles across all matching calls
+	AlertMetricP95Latency  = "p95_latency_ms"
+	AlertMetricP99Latency  = "p99_latency_ms"
+)
+
+// Alert rule comparison operators
+const (
+	AlertOperatorAbove        = "gt"
+	AlertOperatorAboveOrEqual = "gte"
+	AlertOperatorBelow        = "lt"
+	AlertOperatorBelowOrEqual = "lte"
+)
+
+// Alert rule states. AlertStateResolved only appears in alert events, for
+// rules going back to ok.
+const (
+	AlertStateOK       = "ok"
+	AlertStatePending  = "pending"
+	AlertStateFiring   = "firing"
+	AlertStateResolved = "resolved"
+)
+
+// Limits of alert rule durations
+const (
+	MinAlertWindow = time.Minute
+	MaxAlertWindow = 31 * 24 * time.Hour
+	MaxAlertFor    = 24 * time.Hour
+)
+
+// AlertFilter restricts the tool calls an alert rule watches
+type AlertFilter struct {
+	ToolNames        []string          `json:"tool_names,omitempty"`
+	ExcludeToolNames []string          `json:"exclude_tool_names,omitempty"`
+	Models           []string          `json:"models,omitempty"`
+	Metadata         map[string]string `json:"metadata,omitempty"`
+}
+
+// MetricsFilter converts the filter for metrics queries
+func (f AlertFilter) MetricsFilter() MetricsFilter {
+	return MetricsFilter{
+		ToolNames:        f.ToolNames,
+		ExcludeToolNames: f.ExcludeToolNames,
+		Models:           f.Models,
+		Metadata:         f.Metadata,
+	}
+}
+
+// AlertRule compares a metric over a trailing window against a threshold.
+// A breach makes the rule pending, and firing once it lasted the rule's
+// for-duration; the rule goes back to ok when the breach ends.
+type AlertRule struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	Name      string    `json:"name"`
+	Metric    string    `json:"metric"`
+
+	// The rule breaches when the metric compares to the threshold with
+	// the operator, e.g. failure_rate gt 5
+	Operator  string  `json:"operator"`
+	Threshold float64 `json:"threshold"`
+
+	WindowSeconds int         `json:"window_seconds"`
+	ForSeconds    int         `json:"for_seconds"`
+	Filter        AlertFilter `json:"filter"`
+	Enabled       bool        `json:"enabled"`
+	CreatedAt     time.Time   `json:"created_at"`
+
+	// Evaluation state
+	State           string     `json:"state"`
+	StateSince      *time.Time `json:"state_since,omitempty"`
+	LastValue       *float64   `json:"last_value,omitempty"` // nil when the window had no data
+	LastEvaluatedAt *time.Time `json:"last_evaluated_at,omitempty"`
+}
+
+// Window returns the trailing range the metric is computed over
+func (r AlertRule) Window() time.Duration {
+	return time.Duration(r.WindowSeconds) * time.Second
+}
+
+// For returns how long a breach lasts before the rule fires
+func (r AlertRule) For() time.Duration {
+	return time.Duration(r.ForSeconds) * time.Second
+}
+
+// Breached reports whether a metric value breaches the rule's threshold
+func (r AlertRule) Breached(value float64) bool {
+	switch r.Operator {
+	case AlertOperatorAbove:
+		return value > r.Threshold
+	case AlertOperatorAboveOrEqual:
+		return value >= r.Threshold
+	case AlertOperatorBelow:
+		return value < r.Threshold
+	case AlertOperatorBelowOrEqual:
+		return value <= r.Threshold
+	}
+	return false
+}
+
+// AlertRuleRequest is the body of an alert rule creation or update
+type AlertRuleRequest struct {
+	Name          string      `json:"name"`
+	Metric        string      `json:"metric"`
+	Operator      string      `json:"operator"`
+	Threshold     *float64    `json:"threshold"`
+	WindowSeconds int         `json:"window_seconds"`
+	ForSeconds    int         `json:"for_seconds"`
+	Filter        AlertFilter `json:"filter"`
+	Enabled       *bool       `json:"enabled"` // defaults to true
+}
+
+// Validate checks the request describes a usable rule. Metadata keys are
+// checked by the caller, like other metadata keys in requests.
+func (req AlertRuleRequest) Validate() error {
+	window := time.Duration(req.WindowSeconds) * time.Second
+	switch {
+	case req.Name == "" || len(req.Name) > 255:
+		return fmt.Errorf("name is required and must be at most 255 characters")
+	case !isAlertMetric(req.Metric):
+		return fmt.Errorf("unknown metric %q", req.Metric)
+	case req.Operator != AlertOperatorAbove && req.Operator != AlertOperatorAboveOrEqual &&
+		req.Operator != AlertOperatorBelow && req.Operator != AlertOperatorBelowOrEqual:
+		return fmt.Errorf("operator must be one of gt, gte, lt or lte")
+	case req.Threshold == nil:
+		return fmt.Errorf("threshold is required")
+	case window < MinAlertWindow || window > MaxAlertWindow:
+		return fmt.Errorf("window_seconds must be between %d and %d", int(MinAlertWindow.Seconds()), int(MaxAlertWindow.Seconds()))
+	case req.ForSeconds < 0 || time.Duration(req.ForSeconds)*time.Second > MaxAlertFor:
+		return fmt.Errorf("for_seconds must be between 0 and %d", int(MaxAlertFor.Seconds()))
+	}
+	return nil
+}
+
+// isAlertMetric reports whether metric is a known alert metric
+func isAlertMetric(metric string) bool {
+	switch metric {
+	case AlertMetricFailureRate, AlertMetricCallCount, AlertMetricTotalTokens, AlertMetricAvgLatency,
+		AlertMetricP50Latency, AlertMetricP95Latency, AlertMetricP99Latency:
+		return true
+	}
+	return false
+}
+
+// AlertEvent records a state change of an alert rule
+type AlertEvent struct {
+	ID            uuid.UUID `json:"id"`
+	RuleID        uuid.UUID `json:"rule_id"`
+	ProjectID     uuid.UUID `json:"project_id"`
+	State         string    `json:"state"`
+	PreviousState string    `json:"previous_state"`
+	Value         *float64  `json:"value"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// AlertNotification describes a state change of an alert rule to live
+// clients
+type AlertNotification struct {
+	AlertEvent
+	Rule AlertRule `json:"rule"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// API key scopes
+const (
+	// ScopeIngest allows submitting events (agents)
+	ScopeIngest = "ingest"
+
+	// ScopeRead allows querying metrics and tool calls and streaming updates (dashboard)
+	ScopeRead = "read"
+
+	// ScopeAdmin allows everything, including managing API keys
+	ScopeAdmin = "admin"
+)
+
+// APIKey describes an API key. The secret itself is never stored. Keys
+// grant access to a single project; only the bootstrap admin key, with a
+// nil ProjectID, spans all projects.
+type APIKey struct {
+	ID         uuid.UUID  `json:"id"`
+	ProjectID  uuid.UUID  `json:"project_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// HasScope reports whether the key grants scope. Admin keys grant every scope.
+func (k APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// IsGlobal reports whether the key isn't bound to a project
+func (k APIKey) IsGlobal() bool {
+	return k.ProjectID == uuid.Nil
+}
+
+// IsValidScope reports whether scope is a known API key scope
+func IsValidScope(scope string) bool {
+	return scope == ScopeIngest || scope == ScopeRead || scope == ScopeAdmin
+}
+
+// CreateAPIKeyRequest is the body of an API key creation request
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// CreatedAPIKey is returned once on creation and includes the secret key
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
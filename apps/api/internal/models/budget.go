@@ -0,0 +1,133 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Budget metrics
+const (
+	// BudgetMetricTokens counts input plus output tokens
+	BudgetMetricTokens = "tokens"
+
+	// BudgetMetricCost counts cost in US dollars (see ModelPrice)
+	BudgetMetricCost = "cost_usd"
+)
+
+// MaxBudgetWindowHours is the longest rolling window of a budget
+const MaxBudgetWindowHours = 31 * 24
+
+// Budget caps the tokens or cost of a project's tool calls over a rolling
+// window. Without a metadata key the whole project (or tool) shares the
+// budget; with one, each value of the key (e.g. each agent_id) has its own.
+type Budget struct {
+	ID          uuid.UUID `json:"id"`
+	ProjectID   uuid.UUID `json:"project_id"`
+	Name        string    `json:"name"`
+	Metric      string    `json:"metric"`
+	Limit       float64   `json:"limit"`
+	WindowHours int       `json:"window_hours"`
+
+	// Only this tool's calls count, when set
+	ToolName string `json:"tool_name,omitempty"`
+
+	// Usage is tracked separately per value of this metadata key, when
+	// set; calls without the key don't count
+	MetadataKey string `json:"metadata_key,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Window returns the length of the budget's rolling window
+func (b Budget) Window() time.Duration {
+	return time.Duration(b.WindowHours) * time.Hour
+}
+
+// Amount returns how much of the budget's metric a call uses
+func (b Budget) Amount(call ToolCall) float64 {
+	if b.Metric == BudgetMetricCost {
+		if call.CostUSD == nil {
+			return 0
+		}
+		return *call.CostUSD
+	}
+	return float64(call.InputTokens + call.OutputTokens)
+}
+
+// CreateBudgetRequest is the body of a budget creation request
+type CreateBudgetRequest struct {
+	Name        string   `json:"name"`
+	Metric      string   `json:"metric"`
+	Limit       *float64 `json:"limit"`
+	WindowHours int      `json:"window_hours"`
+	ToolName    string   `json:"tool_name"`
+	MetadataKey string   `json:"metadata_key"`
+}
+
+// Validate checks the request describes a usable budget. The metadata key
+// is checked by the caller, like other metadata keys in requests.
+func (req CreateBudgetRequest) Validate() error {
+	switch {
+	case req.Name == "" || len(req.Name) > 255:
+		return fmt.Errorf("name is required and must be at most 255 characters")
+	case req.Metric != BudgetMetricTokens && req.Metric != BudgetMetricCost:
+		return fmt.Errorf("metric must be %q or %q", BudgetMetricTokens, BudgetMetricCost)
+	case req.Limit == nil || *req.Limit <= 0:
+		return fmt.Errorf("limit must be positive")
+	case req.WindowHours < 1 || req.WindowHours > MaxBudgetWindowHours:
+		return fmt.Errorf("window_hours must be between 1 and %d", MaxBudgetWindowHours)
+	case len(req.ToolName) > 255:
+		return fmt.Errorf("tool_name must be at most 255 characters")
+	}
+	return nil
+}
+
+// BudgetUsage is how much of a budget is used in the current window
+type BudgetUsage struct {
+	// Metadata value the usage is tracked for, for per-key budgets
+	Key string `json:"key,omitempty"`
+
+	Used      float64 `json:"used"`
+	Remaining float64 `json:"remaining"`
+	Exceeded  bool    `json:"exceeded"` // the usage reached the limit
+}
+
+// NewBudgetUsage reports used against a budget's limit
+func NewBudgetUsage(b Budget, key string, used float64) BudgetUsage {
+	return BudgetUsage{
+		Key:       key,
+		Used:      used,
+		Remaining: max(b.Limit-used, 0),
+		Exceeded:  used >= b.Limit,
+	}
+}
+
+// BudgetStatus is a budget with its current usage, per key for per-key
+// budgets (keys without usage are omitted)
+type BudgetStatus struct {
+	Budget
+	Usage []BudgetUsage `json:"usage"`
+}
+
+// BudgetCheck answers whether a caller is over any budget applying to it
+type BudgetCheck struct {
+	Exceeded bool           `json:"exceeded"`
+	Budgets  []BudgetStatus `json:"budgets"`
+}
+
+// BudgetExceeded is pushed to a project's live clients when a budget's
+// usage reaches its limit
+type BudgetExceeded struct {
+	BudgetID    uuid.UUID `json:"budget_id"`
+	Name        string    `json:"name"`
+	Metric      string    `json:"metric"`
+	Limit       float64   `json:"limit"`
+	Used        float64   `json:"used"`
+	WindowHours int       `json:"window_hours"`
+	ToolName    string    `json:"tool_name,omitempty"`
+	MetadataKey string    `json:"metadata_key,omitempty"`
+	Key         string    `json:"key,omitempty"`
+	ExceededAt  time.Time `json:"exceeded_at"`
+}
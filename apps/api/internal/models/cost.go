@@ -0,0 +1,129 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ModelPrice is a model's token prices from EffectiveFrom until the model's
+// next price takes effect. Prices are in US dollars per million tokens.
+type ModelPrice struct {
+	ID               uuid.UUID `json:"id"`
+	Model            string    `json:"model"`
+	InputPerMillion  float64   `json:"input_per_million"`
+	OutputPerMillion float64   `json:"output_per_million"`
+
+	// Price of input tokens read from a prompt cache; nil charges them as
+	// regular input tokens
+	CachedInputPerMillion *float64 `json:"cached_input_per_million,omitempty"`
+
+	EffectiveFrom time.Time `json:"effective_from"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Cost returns the cost of a call's tokens in US dollars. Cached tokens
+// are counted within the input tokens.
+func (p ModelPrice) Cost(call ToolCall) float64 {
+	cached := min(call.CachedTokens, call.InputTokens)
+	cachedPrice := p.InputPerMillion
+	if p.CachedInputPerMillion != nil {
+		cachedPrice = *p.CachedInputPerMillion
+	}
+
+	return (float64(call.InputTokens-cached)*p.InputPerMillion +
+		float64(cached)*cachedPrice +
+		float64(call.OutputTokens)*p.OutputPerMillion) / 1e6
+}
+
+// PriceAt returns the price in effect at t among one model's prices, or
+// nil when none had taken effect yet
+func PriceAt(prices []ModelPrice, t time.Time) *ModelPrice {
+	var current *ModelPrice
+	for i := range prices {
+		if prices[i].EffectiveFrom.After(t) {
+			continue
+		}
+		if current == nil || prices[i].EffectiveFrom.After(current.EffectiveFrom) {
+			current = &prices[i]
+		}
+	}
+	return current
+}
+
+// SetModelPriceRequest is the body of a price catalog update. A price with
+// the same model and effective_from replaces the existing one.
+type SetModelPriceRequest struct {
+	Model                 string     `json:"model"`
+	InputPerMillion       *float64   `json:"input_per_million"`
+	OutputPerMillion      *float64   `json:"output_per_million"`
+	CachedInputPerMillion *float64   `json:"cached_input_per_million"`
+	EffectiveFrom         *time.Time `json:"effective_from"` // defaults to the Unix epoch, i.e. always
+}
+
+// Validate checks the request describes a complete price
+func (req SetModelPriceRequest) Validate() error {
+	switch {
+	case req.Model == "" || len(req.Model) > MaxModelLength:
+		return fmt.Errorf("model is required and must be at most %d characters", MaxModelLength)
+	case req.InputPerMillion == nil || req.OutputPerMillion == nil:
+		return fmt.Errorf("input_per_million and output_per_million are required")
+	case *req.InputPerMillion < 0 || *req.OutputPerMillion < 0 ||
+		(req.CachedInputPerMillion != nil && *req.CachedInputPerMillion < 0):
+		return fmt.Errorf("prices must not be negative")
+	}
+	return nil
+}
+
+// MaxModelLength is the longest accepted model name
+const MaxModelLength = 255
+
+// CostTotals aggregates the tokens and cost of tool calls
+type CostTotals struct {
+	Calls int64 `json:"calls"`
+
+	// Calls naming a model without a price at the time of the call
+	UnpricedCalls int64 `json:"unpriced_calls"`
+
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CachedTokens int64   `json:"cached_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// CostGroup holds the cost totals for one tool, model or group_by value
+type CostGroup struct {
+	Group string `json:"group"`
+	CostTotals
+}
+
+// CostDataPoint represents the cost of a time period
+type CostDataPoint struct {
+	Bucket  time.Time `json:"bucket"`
+	Hour    string    `json:"hour"`
+	CostUSD float64   `json:"cost_usd"`
+}
+
+// CostReport breaks down the cost of a project's tool calls by tool,
+// model and time bucket, and by the group_by dimension when given
+type CostReport struct {
+	CostTotals
+	From    time.Time       `json:"from"`
+	To      time.Time       `json:"to"`
+	ByTool  []CostGroup     `json:"by_tool"`
+	ByModel []CostGroup     `json:"by_model"`
+	Groups  []CostGroup     `json:"groups,omitempty"`
+	Series  []CostDataPoint `json:"series"`
+}
+
+// SortCostGroups orders groups most expensive first, then by name
+func SortCostGroups(groups []CostGroup) {
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].CostUSD != groups[j].CostUSD {
+			return groups[i].CostUSD > groups[j].CostUSD
+		}
+		return groups[i].Group < groups[j].Group
+	})
+}
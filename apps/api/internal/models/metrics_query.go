@@ -0,0 +1,84 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Group-by dimensions
+const (
+	GroupByToolName       = "tool_name"
+	GroupByModel          = "model"
+	GroupByMetadataPrefix = "metadata."
+)
+
+// MetricsQuery selects the project, time range and bucketing of a metrics request
+type MetricsQuery struct {
+	// Project whose tool calls are aggregated
+	ProjectID uuid.UUID
+
+	From time.Time
+	To   time.Time
+
+	// Bucket width for time-series metrics
+	Interval time.Duration
+
+	// Timezone buckets are aligned to (e.g. daily buckets start at local midnight)
+	Location *time.Location
+
+	// Restricts which tool calls are aggregated
+	Filter MetricsFilter
+
+	// Splits results by a dimension: GroupByToolName, GroupByModel,
+	// GroupByMetadataPrefix followed by a metadata key, or empty for no
+	// grouping
+	GroupBy string
+}
+
+// MetricsFilter restricts the tool calls included in metrics. Zero-valued
+// fields don't filter.
+type MetricsFilter struct {
+	ToolNames        []string          // tool_name IN (...)
+	ExcludeToolNames []string          // tool_name NOT IN (...)
+	Models           []string          // model IN (...)
+	Status           string            // "success" or "failed"
+	MinDurationMs    *int              // duration_ms >= value
+	MaxDurationMs    *int              // duration_ms <= value
+	Metadata         map[string]string // metadata ->> key = value
+}
+
+// GroupByMetadataKey returns the metadata key results are grouped by, if any
+func (q MetricsQuery) GroupByMetadataKey() (string, bool) {
+	if !strings.HasPrefix(q.GroupBy, GroupByMetadataPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(q.GroupBy, GroupByMetadataPrefix), true
+}
+
+// Duration returns the length of the queried range
+func (q MetricsQuery) Duration() time.Duration {
+	return q.To.Sub(q.From)
+}
+
+// TimeZone returns the IANA name of the query's timezone, defaulting to UTC
+func (q MetricsQuery) TimeZone() string {
+	if q.Location == nil {
+		return "UTC"
+	}
+	return q.Location.String()
+}
+
+// BucketLabel formats a bucket start as an HH:MM label in the query's timezone
+func (q MetricsQuery) BucketLabel(bucket time.Time) string {
+	return q.InZone(bucket).Format("15:04")
+}
+
+// InZone converts t to the query's timezone
+func (q MetricsQuery) InZone(t time.Time) time.Time {
+	if q.Location == nil {
+		return t.UTC()
+	}
+	return t.In(q.Location)
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultProjectID is the project that owns data ingested without a
+// project, including everything stored before projects existed
+var DefaultProjectID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// Project isolates the tool calls and API keys of one team
+type Project struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateProjectRequest is the body of a project creation request
+type CreateProjectRequest struct {
+	Name string `json:"name"`
+}
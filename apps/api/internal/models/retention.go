@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MinRawRetentionDays is the shortest raw retention, leaving the daily
+// rollup room to refresh before raw rows are dropped
+const MinRawRetentionDays = 3
+
+// DataPolicies are the deployment-wide retention and compression policies.
+// Zero disables a policy: data is kept forever, or never compressed.
+type DataPolicies struct {
+	// Days raw tool calls are kept
+	RawRetentionDays int `json:"raw_retention_days"`
+
+	// Days the hourly and daily rollups are kept
+	RollupRetentionDays int `json:"rollup_retention_days"`
+
+	// Age in days after which raw tool calls are compressed
+	CompressAfterDays int `json:"compress_after_days"`
+}
+
+// Validate checks the policies are consistent. Rollups may not be dropped
+// before the raw rows they summarize, since long-range metrics read them.
+func (p DataPolicies) Validate() error {
+	switch {
+	case p.RawRetentionDays < 0 || p.RollupRetentionDays < 0 || p.CompressAfterDays < 0:
+		return fmt.Errorf("policies must not be negative")
+	case p.RawRetentionDays > 0 && p.RawRetentionDays < MinRawRetentionDays:
+		return fmt.Errorf("raw_retention_days must be 0 (keep forever) or at least %d", MinRawRetentionDays)
+	case p.RollupRetentionDays > 0 && (p.RawRetentionDays == 0 || p.RollupRetentionDays < p.RawRetentionDays):
+		return fmt.Errorf("rollup_retention_days must be 0 (keep forever) or at least raw_retention_days")
+	}
+	return nil
+}
+
+// UpdateDataPoliciesRequest is the body of a policies update. Omitted
+// fields keep their current value.
+type UpdateDataPoliciesRequest struct {
+	RawRetentionDays    *int `json:"raw_retention_days"`
+	RollupRetentionDays *int `json:"rollup_retention_days"`
+	CompressAfterDays   *int `json:"compress_after_days"`
+}
+
+// Apply returns the policies with the request's fields changed
+func (req UpdateDataPoliciesRequest) Apply(p DataPolicies) DataPolicies {
+	if req.RawRetentionDays != nil {
+		p.RawRetentionDays = *req.RawRetentionDays
+	}
+	if req.RollupRetentionDays != nil {
+		p.RollupRetentionDays = *req.RollupRetentionDays
+	}
+	if req.CompressAfterDays != nil {
+		p.CompressAfterDays = *req.CompressAfterDays
+	}
+	return p
+}
+
+// DeleteFilter selects a project's tool calls to delete, e.g. for a data
+// subject removal request. Zero-valued fields don't filter; at least one
+// must be set.
+type DeleteFilter struct {
+	RequestID *uuid.UUID        // request_id = value
+	ToolName  string            // tool_name = value
+	From      time.Time         // created_at >= value
+	To        time.Time         // created_at < value
+	Metadata  map[string]string // metadata ->> key = value
+}
+
+// IsEmpty reports whether the filter would select every tool call
+func (f DeleteFilter) IsEmpty() bool {
+	return f.RequestID == nil && f.ToolName == "" && f.From.IsZero() && f.To.IsZero() && len(f.Metadata) == 0
+}
@@ -0,0 +1,162 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SLO types
+const (
+	// SLOTypeAvailability counts successful calls as good
+	SLOTypeAvailability = "availability"
+
+	// SLOTypeLatency counts successful calls completing within the latency
+	// threshold as good, out of all successful calls
+	SLOTypeLatency = "latency"
+)
+
+// MaxSLOWindowDays is the longest rolling window of an SLO
+const MaxSLOWindowDays = 90
+
+// SLO is an objective on the share of a project's matching tool calls that
+// are good over a rolling window, e.g. 99% of search_web calls succeed over
+// 28 days. The complement of the objective is the error budget.
+type SLO struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+
+	// Percentage of calls that must be good, below 100
+	Objective float64 `json:"objective"`
+
+	// Latency SLOs only: slowest duration of a good call
+	LatencyThresholdMs int `json:"latency_threshold_ms,omitempty"`
+
+	WindowDays int `json:"window_days"`
+
+	// Restricts the calls the SLO covers, like alert rules
+	Filter AlertFilter `json:"filter"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Window returns the rolling range the SLO is measured over
+func (s SLO) Window() time.Duration {
+	return time.Duration(s.WindowDays) * 24 * time.Hour
+}
+
+// ErrorBudget returns the fraction of calls allowed to be bad
+func (s SLO) ErrorBudget() float64 {
+	return (100 - s.Objective) / 100
+}
+
+// SLORequest is the body of an SLO creation or update
+type SLORequest struct {
+	Name               string      `json:"name"`
+	Type               string      `json:"type"`
+	Objective          float64     `json:"objective"`
+	LatencyThresholdMs int         `json:"latency_threshold_ms"`
+	WindowDays         int         `json:"window_days"` // defaults to 28
+	Filter             AlertFilter `json:"filter"`
+}
+
+// Validate checks the request describes a usable SLO. Metadata keys are
+// checked by the caller, like other metadata keys in requests.
+func (req SLORequest) Validate() error {
+	switch {
+	case req.Name == "" || len(req.Name) > 255:
+		return fmt.Errorf("name is required and must be at most 255 characters")
+	case req.Type != SLOTypeAvailability && req.Type != SLOTypeLatency:
+		return fmt.Errorf("type must be %q or %q", SLOTypeAvailability, SLOTypeLatency)
+	case req.Objective <= 0 || req.Objective >= 100:
+		return fmt.Errorf("objective must be a percentage above 0 and below 100")
+	case req.Type == SLOTypeLatency && req.LatencyThresholdMs <= 0:
+		return fmt.Errorf("latency_threshold_ms is required for latency SLOs")
+	case req.Type == SLOTypeAvailability && req.LatencyThresholdMs != 0:
+		return fmt.Errorf("latency_threshold_ms only applies to latency SLOs")
+	case req.WindowDays < 1 || req.WindowDays > MaxSLOWindowDays:
+		return fmt.Errorf("window_days must be between 1 and %d", MaxSLOWindowDays)
+	}
+	return nil
+}
+
+// SLOStatus is an SLO's attainment and error budget over its window ending
+// at a point in time
+type SLOStatus struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	GoodCalls  int64 `json:"good_calls"`
+	TotalCalls int64 `json:"total_calls"`
+
+	// Percentage of good calls, nil when no calls matched
+	SLI *float64 `json:"sli"`
+	Met bool     `json:"met"`
+
+	// Number of bad calls the window's traffic allows, and the percentage
+	// of it spent (above 100 once the budget is exhausted)
+	ErrorBudgetCalls float64 `json:"error_budget_calls"`
+	BudgetConsumed   float64 `json:"budget_consumed"`
+	BudgetRemaining  float64 `json:"budget_remaining"`
+
+	BurnRates  []SLOBurnRate  `json:"burn_rates"`
+	BurnAlerts []SLOBurnAlert `json:"burn_alerts"`
+}
+
+// SLOBurnRate is how fast an SLO spends its error budget over a trailing
+// window: 1 spends exactly the budget over the SLO's window
+type SLOBurnRate struct {
+	Window     string  `json:"window"`
+	GoodCalls  int64   `json:"good_calls"`
+	TotalCalls int64   `json:"total_calls"`
+	BurnRate   float64 `json:"burn_rate"`
+}
+
+// SLOBurnAlert is a multi-window burn rate condition: it fires when both
+// the long and the short window burn faster than the threshold, i.e. the
+// budget is being spent fast and still is
+type SLOBurnAlert struct {
+	Severity    string  `json:"severity"` // "page" or "ticket"
+	LongWindow  string  `json:"long_window"`
+	ShortWindow string  `json:"short_window"`
+	Threshold   float64 `json:"threshold"`
+	Firing      bool    `json:"firing"`
+}
+
+// SLOReport is an SLO with its current status
+type SLOReport struct {
+	SLO
+	Status SLOStatus `json:"status"`
+}
+
+// SLOBudgetPoint is one bucket of an SLO's budget consumption over its
+// window
+type SLOBudgetPoint struct {
+	Bucket     time.Time `json:"bucket"`
+	GoodCalls  int64     `json:"good_calls"`
+	TotalCalls int64     `json:"total_calls"`
+
+	// Percentage of good calls in the bucket, nil when it had none
+	SLI *float64 `json:"sli"`
+
+	// Burn rate within the bucket
+	BurnRate float64 `json:"burn_rate"`
+
+	// Percentage of the window's error budget spent from the start of the
+	// window to the end of the bucket
+	BudgetConsumed  float64 `json:"budget_consumed"`
+	BudgetRemaining float64 `json:"budget_remaining"`
+}
+
+// SLOBudgetSeries is an SLO's budget consumption over its window
+type SLOBudgetSeries struct {
+	SLOID    uuid.UUID        `json:"slo_id"`
+	From     time.Time        `json:"from"`
+	To       time.Time        `json:"to"`
+	Interval string           `json:"interval"`
+	TimeZone string           `json:"timezone"`
+	Points   []SLOBudgetPoint `json:"points"`
+}
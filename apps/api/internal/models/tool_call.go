@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,6 +10,7 @@ import (
 // ToolCall represents a single tool call event
 type ToolCall struct {
 	ID           uuid.UUID              `json:"id"`
+	ProjectID    uuid.UUID              `json:"project_id"`
 	RequestID    uuid.UUID              `json:"request_id"`
 	ToolName     string                 `json:"tool_name"`
 	DurationMs   int                    `json:"duration_ms"`
@@ -18,6 +20,12 @@ type ToolCall struct {
 	ErrorMessage *string                `json:"error_message,omitempty"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
 	CreatedAt    time.Time              `json:"created_at"`
+	SpanID       *string                `json:"span_id,omitempty"`
+	ParentSpanID *string                `json:"parent_span_id,omitempty"`
+	EventID      *string                `json:"event_id,omitempty"`
+	Model        *string                `json:"model,omitempty"`
+	CachedTokens int                    `json:"cached_tokens"`
+	CostUSD      *float64               `json:"cost_usd,omitempty"` // nil when the model has no price
 }
 
 // ToolCallEvent is the incoming event from agents
@@ -31,41 +39,181 @@ type ToolCallEvent struct {
 	ErrorMessage *string                `json:"error_message,omitempty"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
 	Timestamp    *time.Time             `json:"timestamp,omitempty"`
+	SpanID       *string                `json:"span_id,omitempty"`
+	ParentSpanID *string                `json:"parent_span_id,omitempty"`
+	EventID      *string                `json:"event_id,omitempty"` // client-supplied idempotency key
+	Model        *string                `json:"model,omitempty"`
+	CachedTokens *int                   `json:"cached_tokens,omitempty"` // input tokens read from a prompt cache
+}
+
+// NewToolCall builds the stored record for an incoming event in a project,
+// assigning an ID and filling in defaults for optional fields
+func NewToolCall(projectID uuid.UUID, event ToolCallEvent) (ToolCall, error) {
+	requestID, err := uuid.Parse(event.RequestID)
+	if err != nil {
+		return ToolCall{}, fmt.Errorf("invalid request_id: %w", err)
+	}
+
+	call := ToolCall{
+		ID:           uuid.New(),
+		ProjectID:    projectID,
+		RequestID:    requestID,
+		ToolName:     event.ToolName,
+		DurationMs:   event.DurationMs,
+		Status:       event.Status,
+		ErrorMessage: event.ErrorMessage,
+		Metadata:     event.Metadata,
+		SpanID:       event.SpanID,
+		ParentSpanID: event.ParentSpanID,
+		EventID:      event.EventID,
+		Model:        event.Model,
+	}
+
+	if event.Timestamp != nil {
+		call.CreatedAt = *event.Timestamp
+	} else {
+		call.CreatedAt = time.Now()
+	}
+
+	if event.InputTokens != nil {
+		call.InputTokens = *event.InputTokens
+	}
+	if event.OutputTokens != nil {
+		call.OutputTokens = *event.OutputTokens
+	}
+	if event.CachedTokens != nil {
+		call.CachedTokens = *event.CachedTokens
+	}
+
+	// Store an empty object rather than NULL when no metadata was sent
+	if call.Metadata == nil {
+		call.Metadata = map[string]interface{}{}
+	}
+
+	return call, nil
+}
+
+// BatchEventResult reports the outcome of a single event within a batch
+type BatchEventResult struct {
+	Index  int        `json:"index"`
+	Status string     `json:"status"` // "accepted", "duplicate" or "rejected"
+	ID     *uuid.UUID `json:"id,omitempty"`
+	Error  string     `json:"error,omitempty"`
+}
+
+// BatchIngestResponse summarizes a batch ingestion request
+type BatchIngestResponse struct {
+	Accepted   int                `json:"accepted"`
+	Duplicates int                `json:"duplicates"`
+	Rejected   int                `json:"rejected"`
+	Results    []BatchEventResult `json:"results"`
 }
 
 // ToolCallDataPoint represents aggregated tool call data for a time period
 type ToolCallDataPoint struct {
-	Hour     string `json:"hour"`
-	Success  int    `json:"success"`
-	Failures int    `json:"failures"`
+	Bucket   time.Time `json:"bucket"`
+	Hour     string    `json:"hour"` // HH:MM label of the bucket, kept for older clients
+	Group    string    `json:"-"`
+	Success  int       `json:"success"`
+	Failures int       `json:"failures"`
+}
+
+// WindowCounts counts the calls of a trailing window
+type WindowCounts struct {
+	Success  int64
+	Failures int64
+
+	// Successful calls lasting at most the requested duration
+	FastSuccess int64
 }
 
 // LatencyDataPoint represents latency percentiles for a tool
 type LatencyDataPoint struct {
-	Tool string  `json:"tool"`
-	P50  float64 `json:"p50"`
-	P95  float64 `json:"p95"`
-	P99  float64 `json:"p99"`
+	Group string  `json:"group,omitempty"`
+	Tool  string  `json:"tool"`
+	P50   float64 `json:"p50"`
+	P95   float64 `json:"p95"`
+	P99   float64 `json:"p99"`
 }
 
 // TokenUsageDataPoint represents token usage for a time period
 type TokenUsageDataPoint struct {
-	Hour   string `json:"hour"`
-	Input  int    `json:"input"`
-	Output int    `json:"output"`
+	Bucket time.Time `json:"bucket"`
+	Hour   string    `json:"hour"`
+	Group  string    `json:"-"`
+	Input  int       `json:"input"`
+	Output int       `json:"output"`
 }
 
 // FailureRateDataPoint represents failure rate for a time period
 type FailureRateDataPoint struct {
-	Hour           string  `json:"hour"`
-	FailurePercent float64 `json:"failurePercent"`
+	Bucket         time.Time `json:"bucket"`
+	Hour           string    `json:"hour"`
+	Group          string    `json:"-"`
+	FailurePercent float64   `json:"failurePercent"`
+}
+
+// MetricsTotals holds the aggregate values shown on the overview cards
+type MetricsTotals struct {
+	TotalCalls   int64   `json:"total_calls"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+	TotalTokens  int64   `json:"total_tokens"`
+	FailureRate  float64 `json:"failure_rate"`
+}
+
+// MetricsChanges holds the percent change of each metric against the
+// comparison window. A nil value means the previous value was zero.
+type MetricsChanges struct {
+	TotalCalls   *float64 `json:"total_calls"`
+	AvgLatencyMs *float64 `json:"avg_latency_ms"`
+	TotalTokens  *float64 `json:"total_tokens"`
+	FailureRate  *float64 `json:"failure_rate"`
+}
+
+// MetricsComparison describes the window the overview is compared against
+type MetricsComparison struct {
+	From          time.Time      `json:"from"`
+	To            time.Time      `json:"to"`
+	Previous      MetricsTotals  `json:"previous"`
+	ChangePercent MetricsChanges `json:"change_percent"`
+}
+
+// GroupTotals holds the overview metrics for one group_by value
+type GroupTotals struct {
+	Group string `json:"group"`
+	MetricsTotals
 }
 
 // MetricsOverview represents aggregated metrics
 type MetricsOverview struct {
-	TotalCalls    int64   `json:"total_calls"`
-	AvgLatencyMs  float64 `json:"avg_latency_ms"`
-	TotalTokens   int64   `json:"total_tokens"`
-	FailureRate   float64 `json:"failure_rate"`
-	ChangePercent float64 `json:"change_percent"`
+	MetricsTotals
+	ChangePercent float64            `json:"change_percent"` // change in total calls, kept for older clients
+	From          time.Time          `json:"from"`
+	To            time.Time          `json:"to"`
+	Comparison    *MetricsComparison `json:"comparison,omitempty"`
+	Groups        []GroupTotals      `json:"groups,omitempty"`
+}
+
+// Series holds the time-series points for one group_by value
+type Series[T any] struct {
+	Group  string `json:"group"`
+	Points []T    `json:"points"`
+}
+
+// GroupSeries splits points into one series per group, in order of each
+// group's first appearance
+func GroupSeries[T any](points []T, group func(T) string) []Series[T] {
+	series := []Series[T]{}
+	index := make(map[string]int)
+	for _, point := range points {
+		key := group(point)
+		i, ok := index[key]
+		if !ok {
+			i = len(series)
+			index[key] = i
+			series = append(series, Series[T]{Group: key})
+		}
+		series[i].Points = append(series[i].Points, point)
+	}
+	return series
 }
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ToolCallNode is a tool call positioned within a request's span tree
+type ToolCallNode struct {
+	ToolCall
+	OffsetMs int64           `json:"offset_ms"`        // start relative to the first call in the chain
+	Orphan   bool            `json:"orphan,omitempty"` // parent_span_id set but parent not found in the chain
+	Children []*ToolCallNode `json:"children"`
+}
+
+// ToolCallTree is a request chain reconstructed from span relationships
+type ToolCallTree struct {
+	RequestID  uuid.UUID       `json:"request_id"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	TotalCalls int             `json:"total_calls"`
+	Orphans    int             `json:"orphans"`
+	Roots      []*ToolCallNode `json:"roots"`
+}
+
+// BuildToolCallTree arranges calls (ordered by created_at) into a tree using
+// span_id/parent_span_id. Calls without a parent become roots; calls whose
+// parent is missing from the chain, or whose ancestry loops back on itself,
+// become roots flagged as orphans.
+func BuildToolCallTree(requestID uuid.UUID, calls []ToolCall) *ToolCallTree {
+	tree := &ToolCallTree{
+		RequestID:  requestID,
+		TotalCalls: len(calls),
+		Roots:      []*ToolCallNode{},
+	}
+	if len(calls) == 0 {
+		return tree
+	}
+
+	started := calls[0].CreatedAt
+	for _, call := range calls[1:] {
+		if call.CreatedAt.Before(started) {
+			started = call.CreatedAt
+		}
+	}
+	tree.StartedAt = &started
+
+	nodes := make([]*ToolCallNode, len(calls))
+	bySpan := make(map[string]*ToolCallNode, len(calls))
+	for i, call := range calls {
+		nodes[i] = &ToolCallNode{
+			ToolCall: call,
+			OffsetMs: call.CreatedAt.Sub(started).Milliseconds(),
+			Children: []*ToolCallNode{},
+		}
+		// The first call to claim a span ID wins if IDs are duplicated
+		if call.SpanID != nil && *call.SpanID != "" {
+			if _, exists := bySpan[*call.SpanID]; !exists {
+				bySpan[*call.SpanID] = nodes[i]
+			}
+		}
+	}
+
+	parents := make(map[*ToolCallNode]*ToolCallNode, len(nodes))
+	for _, node := range nodes {
+		if node.ParentSpanID == nil || *node.ParentSpanID == "" {
+			continue
+		}
+		parent, ok := bySpan[*node.ParentSpanID]
+		if !ok || parent == node {
+			node.Orphan = true
+			continue
+		}
+		parents[node] = parent
+	}
+
+	// Break cycles: a node whose ancestry leads back to itself is orphaned
+	for _, node := range nodes {
+		for cur, steps := parents[node], 0; cur != nil && steps < len(nodes); cur, steps = parents[cur], steps+1 {
+			if cur == node {
+				delete(parents, node)
+				node.Orphan = true
+				break
+			}
+		}
+	}
+
+	for _, node := range nodes {
+		if parent, ok := parents[node]; ok {
+			parent.Children = append(parent.Children, node)
+			continue
+		}
+		if node.Orphan {
+			tree.Orphans++
+		}
+		tree.Roots = append(tree.Roots, node)
+	}
+
+	return tree
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook event types
+const (
+	WebhookEventAlertFiring    = "alert.firing"
+	WebhookEventAlertResolved  = "alert.resolved"
+	WebhookEventBudgetExceeded = "budget.exceeded"
+	WebhookEventNewErrorType   = "error.new"
+
+	// Sent by the test endpoint to every webhook asked, whatever its events
+	WebhookEventTest = "webhook.test"
+)
+
+// Webhook is an HTTP endpoint notified of a project's events with signed
+// JSON bodies
+type Webhook struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+
+	// Event types sent to the webhook; empty means all
+	Events []string `json:"events"`
+
+	// Key of the HMAC-SHA256 body signatures, only returned at creation
+	Secret string `json:"secret,omitempty"`
+
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Wants reports whether the webhook is sent events of a type
+func (w Webhook) Wants(event string) bool {
+	if !w.Enabled {
+		return false
+	}
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateWebhookRequest is the body of a webhook creation request
+type CreateWebhookRequest struct {
+	Name    string   `json:"name"`
+	URL     string   `json:"url"`
+	Events  []string `json:"events"`
+	Secret  string   `json:"secret"`  // generated when empty
+	Enabled *bool    `json:"enabled"` // defaults to true
+}
+
+// Validate checks the request describes a usable webhook
+func (req CreateWebhookRequest) Validate() error {
+	if req.Name == "" || len(req.Name) > 255 {
+		return fmt.Errorf("name is required and must be at most 255 characters")
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(req.URL) > 2048 {
+		return fmt.Errorf("url must be an absolute http or https URL of at most 2048 characters")
+	}
+
+	for _, event := range req.Events {
+		switch event {
+		case WebhookEventAlertFiring, WebhookEventAlertResolved, WebhookEventBudgetExceeded, WebhookEventNewErrorType:
+		default:
+			return fmt.Errorf("unknown event %q", event)
+		}
+	}
+
+	if req.Secret != "" && (len(req.Secret) < 16 || len(req.Secret) > 255) {
+		return fmt.Errorf("secret must be between 16 and 255 characters")
+	}
+	return nil
+}
+
+// WebhookPayload is the JSON body sent to webhooks
+type WebhookPayload struct {
+	// Identifies the event across delivery attempts (X-Nous-Delivery)
+	ID        uuid.UUID   `json:"id"`
+	Event     string      `json:"event"`
+	ProjectID uuid.UUID   `json:"project_id"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookDelivery records one attempt to deliver an event to a webhook
+type WebhookDelivery struct {
+	ID         uuid.UUID `json:"id"`
+	WebhookID  uuid.UUID `json:"webhook_id"`
+	ProjectID  uuid.UUID `json:"project_id"`
+	DeliveryID uuid.UUID `json:"delivery_id"` // the payload ID, shared by retries
+	Event      string    `json:"event"`
+	Attempt    int       `json:"attempt"`
+
+	// Response status, nil when no response was received
+	StatusCode *int   `json:"status_code"`
+	Error      string `json:"error,omitempty"`
+	Success    bool   `json:"success"`
+
+	DurationMs int       `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ErrorType is a kind of tool call failure: a tool's error messages that
+// only differ in numbers share an error type
+type ErrorType struct {
+	ProjectID   uuid.UUID `json:"project_id"`
+	ToolName    string    `json:"tool_name"`
+	Fingerprint string    `json:"fingerprint"`
+	Message     string    `json:"message"` // the first message seen
+	FirstSeenAt time.Time `json:"first_seen_at"`
+}
@@ -0,0 +1,157 @@
+package otlp
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// Supported OTLP/HTTP encodings
+const (
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeJSON     = "application/json"
+)
+
+// Encoding returns the OTLP encoding for a Content-Type header value, or an
+// empty string if the content type is not supported
+func Encoding(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	switch mediaType {
+	case ContentTypeProtobuf, ContentTypeJSON:
+		return mediaType
+	default:
+		return ""
+	}
+}
+
+// DecodeTraces decodes an ExportTraceServiceRequest body.
+// TracesData shares its wire format with ExportTraceServiceRequest, which
+// lets us avoid depending on the gRPC collector packages.
+func DecodeTraces(encoding string, body []byte) (*tracepb.TracesData, error) {
+	traces := &tracepb.TracesData{}
+
+	switch encoding {
+	case ContentTypeProtobuf:
+		if err := proto.Unmarshal(body, traces); err != nil {
+			return nil, fmt.Errorf("invalid protobuf payload: %w", err)
+		}
+	case ContentTypeJSON:
+		// OTLP/JSON encodes trace and span IDs as hex rather than the
+		// base64 protojson expects, so rewrite them before unmarshaling
+		normalized, err := normalizeJSONIDs(body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON payload: %w", err)
+		}
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(normalized, traces); err != nil {
+			return nil, fmt.Errorf("invalid JSON payload: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported encoding: %q", encoding)
+	}
+
+	return traces, nil
+}
+
+// EncodeResponse builds an ExportTraceServiceResponse in the request's
+// encoding. A partial_success field is only included when spans were rejected.
+func EncodeResponse(encoding string, rejectedSpans int64, errorMessage string) []byte {
+	if encoding == ContentTypeJSON {
+		response := map[string]interface{}{}
+		if rejectedSpans > 0 {
+			response["partialSuccess"] = map[string]interface{}{
+				"rejectedSpans": fmt.Sprintf("%d", rejectedSpans),
+				"errorMessage":  errorMessage,
+			}
+		}
+		data, _ := json.Marshal(response)
+		return data
+	}
+
+	if rejectedSpans == 0 {
+		return []byte{}
+	}
+
+	// ExportTracePartialSuccess { int64 rejected_spans = 1; string error_message = 2; }
+	var partial []byte
+	partial = protowire.AppendTag(partial, 1, protowire.VarintType)
+	partial = protowire.AppendVarint(partial, uint64(rejectedSpans))
+	partial = protowire.AppendTag(partial, 2, protowire.BytesType)
+	partial = protowire.AppendString(partial, errorMessage)
+
+	// ExportTraceServiceResponse { ExportTracePartialSuccess partial_success = 1; }
+	var response []byte
+	response = protowire.AppendTag(response, 1, protowire.BytesType)
+	response = protowire.AppendBytes(response, partial)
+	return response
+}
+
+// normalizeJSONIDs converts hex-encoded traceId, spanId and parentSpanId
+// fields in an OTLP/JSON payload to base64
+func normalizeJSONIDs(body []byte) ([]byte, error) {
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	for _, rs := range objects(payload, "resourceSpans", "resource_spans") {
+		for _, ss := range objects(rs, "scopeSpans", "scope_spans") {
+			for _, span := range objects(ss, "spans") {
+				if err := hexToBase64(span, "traceId", "trace_id", "spanId", "span_id", "parentSpanId", "parent_span_id"); err != nil {
+					return nil, err
+				}
+				for _, link := range objects(span, "links") {
+					if err := hexToBase64(link, "traceId", "trace_id", "spanId", "span_id"); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+
+	return json.Marshal(payload)
+}
+
+// objects returns the JSON objects in the first array found under any of keys
+func objects(parent map[string]interface{}, keys ...string) []map[string]interface{} {
+	for _, key := range keys {
+		items, ok := parent[key].([]interface{})
+		if !ok {
+			continue
+		}
+		var result []map[string]interface{}
+		for _, item := range items {
+			if obj, ok := item.(map[string]interface{}); ok {
+				result = append(result, obj)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// hexToBase64 rewrites the given string fields from hex to base64 in place
+func hexToBase64(obj map[string]interface{}, keys ...string) error {
+	for _, key := range keys {
+		value, ok := obj[key].(string)
+		if !ok || value == "" {
+			continue
+		}
+		decoded, err := hex.DecodeString(value)
+		if err != nil {
+			return fmt.Errorf("%s must be hex-encoded: %w", key, err)
+		}
+		obj[key] = base64.StdEncoding.EncodeToString(decoded)
+	}
+	return nil
+}
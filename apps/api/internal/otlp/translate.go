@@ -0,0 +1,213 @@
+package otlp
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+// GenAI semantic-convention attributes mapped onto tool call columns
+const (
+	attrToolName         = "gen_ai.tool.name"
+	attrInputTokens      = "gen_ai.usage.input_tokens"
+	attrOutputTokens     = "gen_ai.usage.output_tokens"
+	attrPromptTokens     = "gen_ai.usage.prompt_tokens"     // deprecated alias of input_tokens
+	attrCompletionTokens = "gen_ai.usage.completion_tokens" // deprecated alias of output_tokens
+	attrRequestModel     = "gen_ai.request.model"
+	attrResponseModel    = "gen_ai.response.model" // preferred: the model that actually answered
+
+	genAIPrefix = "gen_ai."
+)
+
+// Result holds the tool call events translated from an OTLP export
+type Result struct {
+	Events        []models.ToolCallEvent
+	SkippedSpans  int64 // spans without GenAI attributes
+	RejectedSpans int64 // GenAI spans that could not be mapped
+	ErrorMessage  string
+}
+
+// Translate maps spans carrying GenAI/tool semantic-convention attributes
+// onto tool call events. Spans without any gen_ai.* attribute are skipped.
+func Translate(traces *tracepb.TracesData) Result {
+	var result Result
+
+	for _, rs := range traces.GetResourceSpans() {
+		resourceAttrs := attributesToMap(rs.GetResource().GetAttributes())
+
+		for _, ss := range rs.GetScopeSpans() {
+			scopeName := ss.GetScope().GetName()
+
+			for _, span := range ss.GetSpans() {
+				if !hasGenAIAttributes(span) {
+					result.SkippedSpans++
+					continue
+				}
+
+				event, err := spanToEvent(span, resourceAttrs, scopeName)
+				if err != nil {
+					result.RejectedSpans++
+					if result.ErrorMessage == "" {
+						result.ErrorMessage = err.Error()
+					}
+					continue
+				}
+				result.Events = append(result.Events, event)
+			}
+		}
+	}
+
+	return result
+}
+
+// spanToEvent converts a single span into a tool call event. The event
+// still needs the validation of ingested events; durations beyond the
+// duration_ms range are capped just past it so that validation fails.
+func spanToEvent(span *tracepb.Span, resourceAttrs map[string]interface{}, scopeName string) (models.ToolCallEvent, error) {
+	traceID, err := uuid.FromBytes(span.GetTraceId())
+	if err != nil || traceID == uuid.Nil {
+		return models.ToolCallEvent{}, fmt.Errorf("span %q has an invalid trace_id", span.GetName())
+	}
+
+	start, end := span.GetStartTimeUnixNano(), span.GetEndTimeUnixNano()
+	if start == 0 || end < start {
+		return models.ToolCallEvent{}, fmt.Errorf("span %q has invalid start/end times", span.GetName())
+	}
+
+	attrs := attributesToMap(span.GetAttributes())
+
+	// Resource attributes are kept unless a span attribute shadows them
+	metadata := make(map[string]interface{}, len(attrs)+len(resourceAttrs)+2)
+	for key, value := range resourceAttrs {
+		metadata[key] = value
+	}
+
+	event := models.ToolCallEvent{
+		RequestID:  traceID.String(),
+		ToolName:   span.GetName(),
+		DurationMs: int(min((end-start)/uint64(time.Millisecond), math.MaxInt32+1)),
+		Status:     "success",
+	}
+
+	for key, value := range attrs {
+		switch key {
+		case attrToolName:
+			if name, ok := value.(string); ok && name != "" {
+				event.ToolName = name
+				continue
+			}
+		case attrInputTokens, attrPromptTokens:
+			if tokens, ok := value.(int64); ok {
+				n := int(tokens)
+				event.InputTokens = &n
+				continue
+			}
+		case attrOutputTokens, attrCompletionTokens:
+			if tokens, ok := value.(int64); ok {
+				n := int(tokens)
+				event.OutputTokens = &n
+				continue
+			}
+		}
+		metadata[key] = value
+	}
+
+	if event.ToolName == "" {
+		return models.ToolCallEvent{}, fmt.Errorf("span has neither a name nor %s", attrToolName)
+	}
+
+	// The model attributes stay in metadata; the column is used for pricing
+	for _, key := range []string{attrResponseModel, attrRequestModel} {
+		if model, ok := attrs[key].(string); ok && model != "" && len(model) <= models.MaxModelLength {
+			event.Model = &model
+			break
+		}
+	}
+
+	if event.ToolName != span.GetName() {
+		metadata["otel.span_name"] = span.GetName()
+	}
+	if scopeName != "" {
+		metadata["otel.scope.name"] = scopeName
+	}
+	event.Metadata = metadata
+
+	if span.GetStatus().GetCode() == tracepb.Status_STATUS_CODE_ERROR {
+		event.Status = "failed"
+		if msg := span.GetStatus().GetMessage(); msg != "" {
+			event.ErrorMessage = &msg
+		}
+	}
+
+	if spanID := span.GetSpanId(); len(spanID) > 0 {
+		id := hex.EncodeToString(spanID)
+		event.SpanID = &id
+
+		// A span is unique within its trace, so exporter retries dedupe
+		eventID := hex.EncodeToString(span.GetTraceId()) + "-" + id
+		event.EventID = &eventID
+	}
+	if parentSpanID := span.GetParentSpanId(); len(parentSpanID) > 0 {
+		id := hex.EncodeToString(parentSpanID)
+		event.ParentSpanID = &id
+	}
+
+	timestamp := time.Unix(0, int64(start)).UTC()
+	event.Timestamp = &timestamp
+
+	return event, nil
+}
+
+// hasGenAIAttributes reports whether a span carries any gen_ai.* attribute
+func hasGenAIAttributes(span *tracepb.Span) bool {
+	for _, kv := range span.GetAttributes() {
+		if strings.HasPrefix(kv.GetKey(), genAIPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// attributesToMap converts OTLP key/value attributes into plain Go values
+func attributesToMap(attrs []*commonpb.KeyValue) map[string]interface{} {
+	result := make(map[string]interface{}, len(attrs))
+	for _, kv := range attrs {
+		result[kv.GetKey()] = anyValue(kv.GetValue())
+	}
+	return result
+}
+
+// anyValue converts an OTLP AnyValue into a JSON-compatible Go value
+func anyValue(v *commonpb.AnyValue) interface{} {
+	switch value := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return value.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return value.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		return value.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		return value.DoubleValue
+	case *commonpb.AnyValue_BytesValue:
+		return base64.StdEncoding.EncodeToString(value.BytesValue)
+	case *commonpb.AnyValue_ArrayValue:
+		values := make([]interface{}, 0, len(value.ArrayValue.GetValues()))
+		for _, item := range value.ArrayValue.GetValues() {
+			values = append(values, anyValue(item))
+		}
+		return values
+	case *commonpb.AnyValue_KvlistValue:
+		return attributesToMap(value.KvlistValue.GetValues())
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,107 @@
+// Package pricing computes the cost of tool calls at ingest from the model
+// price catalog.
+package pricing
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+const (
+	// How long the loaded catalog is trusted before it is read again.
+	// Changes made through this process take effect immediately.
+	cacheTTL = time.Minute
+
+	// How long to wait before reading the catalog again after a failure
+	retryInterval = 5 * time.Second
+)
+
+// PriceStore lists the model price catalog
+type PriceStore interface {
+	ListModelPrices(ctx context.Context) ([]models.ModelPrice, error)
+}
+
+// Catalog prices tool calls from a cached copy of the price catalog
+type Catalog struct {
+	store PriceStore
+
+	mu      sync.Mutex
+	prices  map[string][]models.ModelPrice
+	expires time.Time
+
+	// Bumped by Invalidate, so a read that started before isn't trusted
+	generation uint64
+}
+
+// New creates a catalog backed by store
+func New(store PriceStore) *Catalog {
+	return &Catalog{store: store}
+}
+
+// Invalidate drops the cached catalog, e.g. after prices changed
+func (c *Catalog) Invalidate() {
+	c.mu.Lock()
+	c.expires = time.Time{}
+	c.generation++
+	c.mu.Unlock()
+}
+
+// Price sets the cost of a call naming a model from the price in effect
+// when the call was made. Calls without a model or price get no cost.
+func (c *Catalog) Price(ctx context.Context, call *models.ToolCall) {
+	call.CostUSD = nil
+	if call.Model == nil {
+		return
+	}
+
+	price := models.PriceAt(c.load(ctx)[*call.Model], call.CreatedAt)
+	if price == nil {
+		return
+	}
+	cost := price.Cost(*call)
+	call.CostUSD = &cost
+}
+
+// load returns the catalog by model, reading it again once expired. If
+// that fails, the previously loaded catalog is kept for a while so
+// ingestion goes on. The catalog is read without holding the lock, so
+// pricing doesn't wait on a slow read.
+func (c *Catalog) load(ctx context.Context) map[string][]models.ModelPrice {
+	c.mu.Lock()
+	if time.Now().Before(c.expires) {
+		prices := c.prices
+		c.mu.Unlock()
+		return prices
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	prices, err := c.store.ListModelPrices(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err != nil {
+		log.Printf("Error loading model prices: %v", err)
+		if generation == c.generation {
+			c.expires = time.Now().Add(retryInterval)
+		}
+		return c.prices
+	}
+
+	byModel := make(map[string][]models.ModelPrice)
+	for _, price := range prices {
+		byModel[price.Model] = append(byModel[price.Model], price)
+	}
+	c.prices = byModel
+
+	// Prices changed during the read are read again by the next call
+	if generation == c.generation {
+		c.expires = time.Now().Add(cacheTTL)
+	}
+	return byModel
+}
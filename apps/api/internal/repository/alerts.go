@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+// alertRuleColumns lists the columns scanned by scanAlertRule
+const alertRuleColumns = `id, project_id, name, metric, operator, threshold, window_seconds, for_seconds,
+	filter, enabled, state, state_since, last_value, last_evaluated_at, created_at`
+
+// scanAlertRule reads a row selected with alertRuleColumns
+func scanAlertRule(row pgx.Row) (*models.AlertRule, error) {
+	var rule models.AlertRule
+	err := row.Scan(&rule.ID, &rule.ProjectID, &rule.Name, &rule.Metric, &rule.Operator, &rule.Threshold,
+		&rule.WindowSeconds, &rule.ForSeconds, &rule.Filter, &rule.Enabled,
+		&rule.State, &rule.StateSince, &rule.LastValue, &rule.LastEvaluatedAt, &rule.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// CreateAlertRule stores a new alert rule
+func (r *Repository) CreateAlertRule(ctx context.Context, rule models.AlertRule) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO alert_rules (id, project_id, name, metric, operator, threshold, window_seconds, for_seconds, filter, enabled, state, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`, rule.ID, rule.ProjectID, rule.Name, rule.Metric, rule.Operator, rule.Threshold,
+		rule.WindowSeconds, rule.ForSeconds, rule.Filter, rule.Enabled, rule.State, rule.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create alert rule: %w", err)
+	}
+
+	return nil
+}
+
+// ListAlertRules returns a project's alert rules, oldest first
+func (r *Repository) ListAlertRules(ctx context.Context, projectID uuid.UUID) ([]models.AlertRule, error) {
+	return r.queryAlertRules(ctx, `SELECT `+alertRuleColumns+` FROM alert_rules WHERE project_id = $1 ORDER BY created_at ASC`, projectID)
+}
+
+// ListAllAlertRules returns the alert rules of every project, oldest first
+func (r *Repository) ListAllAlertRules(ctx context.Context) ([]models.AlertRule, error) {
+	return r.queryAlertRules(ctx, `SELECT `+alertRuleColumns+` FROM alert_rules ORDER BY created_at ASC`)
+}
+
+// queryAlertRules runs a query selecting alertRuleColumns
+func (r *Repository) queryAlertRules(ctx context.Context, query string, args ...any) ([]models.AlertRule, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []models.AlertRule{}
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// GetAlertRule returns one of a project's alert rules, or ErrNotFound
+func (r *Repository) GetAlertRule(ctx context.Context, projectID, id uuid.UUID) (*models.AlertRule, error) {
+	rule, err := scanAlertRule(r.db.QueryRow(ctx,
+		`SELECT `+alertRuleColumns+` FROM alert_rules WHERE project_id = $1 AND id = $2`, projectID, id,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return rule, err
+}
+
+// UpdateAlertRule replaces a rule's definition, keeping its state, or
+// returns ErrNotFound
+func (r *Repository) UpdateAlertRule(ctx context.Context, rule models.AlertRule) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE alert_rules
+		SET name = $3, metric = $4, operator = $5, threshold = $6, window_seconds = $7, for_seconds = $8,
+			filter = $9, enabled = $10
+		WHERE project_id = $1 AND id = $2
+	`, rule.ProjectID, rule.ID, rule.Name, rule.Metric, rule.Operator, rule.Threshold,
+		rule.WindowSeconds, rule.ForSeconds, rule.Filter, rule.Enabled,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update alert rule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteAlertRule deletes a rule with its history, or returns ErrNotFound
+func (r *Repository) DeleteAlertRule(ctx context.Context, projectID, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM alert_rules WHERE project_id = $1 AND id = $2`, projectID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete alert rule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// SetAlertState stores a rule's evaluation state and the event of its
+// state change, provided the stored state is still previous
+func (r *Repository) SetAlertState(ctx context.Context, rule models.AlertRule, previous string, event *models.AlertEvent) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to update alert state: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE alert_rules
+		SET state = $2, state_since = $3, last_value = $4, last_evaluated_at = $5
+		WHERE id = $1 AND state = $6
+	`, rule.ID, rule.State, rule.StateSince, rule.LastValue, rule.LastEvaluatedAt, previous)
+	if err != nil {
+		return false, fmt.Errorf("failed to update alert state: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if event != nil {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO alert_events (id, rule_id, project_id, state, previous_state, value, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, event.ID, event.RuleID, event.ProjectID, event.State, event.PreviousState, event.Value, event.CreatedAt)
+		if err != nil {
+			return false, fmt.Errorf("failed to record alert event: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to update alert state: %w", err)
+	}
+	return true, nil
+}
+
+// ListAlertEvents returns a project's most recent alert events, newest
+// first, optionally only those of one rule
+func (r *Repository) ListAlertEvents(ctx context.Context, projectID uuid.UUID, ruleID *uuid.UUID, limit int) ([]models.AlertEvent, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, rule_id, project_id, state, previous_state, value, created_at
+		FROM alert_events
+		WHERE project_id = $1 AND ($2::uuid IS NULL OR rule_id = $2)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`, projectID, ruleID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.AlertEvent{}
+	for rows.Next() {
+		var e models.AlertEvent
+		if err := rows.Scan(&e.ID, &e.RuleID, &e.ProjectID, &e.State, &e.PreviousState, &e.Value, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+)
+
+// ErrNotFound is returned when a requested record does not exist
+var ErrNotFound = store.ErrNotFound
+
+// apiKeyColumns lists the columns scanned by scanAPIKey
+const apiKeyColumns = `id, project_id, name, prefix, scopes, created_at, last_used_at, revoked_at`
+
+// scanAPIKey reads a row selected with apiKeyColumns
+func scanAPIKey(row pgx.Row) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := row.Scan(&key.ID, &key.ProjectID, &key.Name, &key.Prefix, &key.Scopes, &key.CreatedAt, &key.LastUsedAt, &key.RevokedAt); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// CreateAPIKey stores a new API key for key.ProjectID by the hash of its secret
+func (r *Repository) CreateAPIKey(ctx context.Context, key *models.APIKey, keyHash string) error {
+	query := `
+		INSERT INTO api_keys (id, project_id, name, prefix, key_hash, scopes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	if _, err := r.db.Exec(ctx, query, key.ID, key.ProjectID, key.Name, key.Prefix, keyHash, key.Scopes, key.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return nil
+}
+
+// ListAPIKeys returns a project's API keys, newest first
+func (r *Repository) ListAPIKeys(ctx context.Context, projectID uuid.UUID) ([]models.APIKey, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE project_id = $1 ORDER BY created_at DESC`,
+		projectID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	return keys, rows.Err()
+}
+
+// LookupAPIKey returns the active (not revoked) API key with the given
+// hash and records that it was used. It returns ErrNotFound for unknown or
+// revoked keys.
+func (r *Repository) LookupAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	query := `
+		UPDATE api_keys SET last_used_at = NOW()
+		WHERE key_hash = $1 AND revoked_at IS NULL
+		RETURNING ` + apiKeyColumns
+
+	key, err := scanAPIKey(r.db.QueryRow(ctx, query, keyHash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return key, err
+}
+
+// RevokeAPIKey marks a project's API key as revoked. Revoking an already
+// revoked key succeeds; unknown keys return ErrNotFound.
+func (r *Repository) RevokeAPIKey(ctx context.Context, projectID, id uuid.UUID) (*models.APIKey, error) {
+	query := `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW())
+		WHERE id = $1 AND project_id = $2
+		RETURNING ` + apiKeyColumns
+
+	key, err := scanAPIKey(r.db.QueryRow(ctx, query, id, projectID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return key, err
+}
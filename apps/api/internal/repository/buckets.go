@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+// bucketColumn is an aggregate computed per time bucket, over raw tool
+// calls (expr) or rollup rows (rollup). Columns without a rollup
+// expression are always computed from raw tool calls. Empty buckets report
+// zero instead of the aggregate.
+type bucketColumn struct {
+	expr   string
+	rollup string
+	zero   string
+}
+
+// queryBuckets runs a time-bucketed aggregate over the tool calls matching
+// the query, returning one row per bucket and group (bucket start, group
+// value, then columns), including empty buckets. The group value is "" when
+// the query isn't grouped. It uses TimescaleDB's time_bucket_gapfill and
+// falls back to date_bin and generate_series on plain PostgreSQL. Long
+// ranges are read from the rollups when they can answer the query.
+func (r *Repository) queryBuckets(ctx context.Context, q models.MetricsQuery, columns []bucketColumn) (pgx.Rows, error) {
+	var args queryArgs
+	where := metricsWhere(q, &args)
+	interval := args.add(q.Interval)
+	tz := args.add(q.TimeZone())
+
+	source, rolled := "tool_calls", false
+	if rollupColumns(columns) {
+		if rollupSource, ok := r.rollupSource(q, true, &args); ok {
+			source, rolled = rollupSource, true
+		}
+	}
+
+	group := groupExpression(q, &args)
+	groupBy := "1"
+	if group != "" {
+		groupBy = "1, 2"
+	} else {
+		group = "''::text"
+	}
+
+	exprs := make([]string, len(columns))
+	for i, col := range columns {
+		exprs[i] = col.expr
+		if rolled {
+			exprs[i] = col.rollup
+		}
+	}
+
+	selects := make([]string, len(columns))
+	for i, col := range columns {
+		selects[i] = fmt.Sprintf("COALESCE(%s, %s)", exprs[i], col.zero)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			time_bucket_gapfill(%[1]s::interval, created_at, %[2]s::text, $1::timestamptz, $2::timestamptz) as bucket,
+			%[3]s as grp,
+			%[4]s
+		FROM %[7]s
+		WHERE %[5]s
+		GROUP BY %[6]s
+		ORDER BY 2, 1
+	`, interval, tz, group, strings.Join(selects, ",\n\t\t\t"), where, groupBy, source)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err == nil {
+		return rows, nil
+	}
+
+	// Fallback to standard PostgreSQL: buckets are aligned in local time to
+	// the same origin time_bucket uses (Monday 2000-01-03) and empty buckets
+	// come from generate_series, repeated for every group
+	aggregates := make([]string, len(columns))
+	filled := make([]string, len(columns))
+	for i, col := range columns {
+		aggregates[i] = fmt.Sprintf("%s as c%d", exprs[i], i)
+		filled[i] = fmt.Sprintf("COALESCE(data.c%d, %s)", i, col.zero)
+	}
+
+	groups := "SELECT ''::text as grp"
+	if groupBy != "1" {
+		groups = fmt.Sprintf("SELECT DISTINCT %s as grp FROM %s WHERE %s", group, source, where)
+	}
+
+	query = fmt.Sprintf(`
+		WITH series AS (
+			SELECT generate_series(
+				date_bin(%[1]s::interval, $1::timestamptz AT TIME ZONE %[2]s::text, TIMESTAMP '2000-01-03'),
+				($2::timestamptz AT TIME ZONE %[2]s::text) - interval '1 microsecond',
+				%[1]s::interval
+			) as local_bucket
+		),
+		groups AS (
+			%[3]s
+		),
+		data AS (
+			SELECT
+				date_bin(%[1]s::interval, created_at AT TIME ZONE %[2]s::text, TIMESTAMP '2000-01-03') as local_bucket,
+				%[4]s as grp,
+				%[5]s
+			FROM %[9]s
+			WHERE %[6]s
+			GROUP BY %[7]s
+		)
+		SELECT
+			series.local_bucket AT TIME ZONE %[2]s::text as bucket,
+			groups.grp,
+			%[8]s
+		FROM series
+		CROSS JOIN groups
+		LEFT JOIN data ON data.local_bucket = series.local_bucket AND data.grp = groups.grp
+		ORDER BY 2, 1
+	`, interval, tz, groups, group, strings.Join(aggregates, ",\n\t\t\t\t"), where, groupBy, strings.Join(filled, ",\n\t\t\t"), source)
+
+	return r.db.Query(ctx, query, args...)
+}
+
+// rollupColumns reports whether every column can be read from the rollups
+func rollupColumns(columns []bucketColumn) bool {
+	for _, col := range columns {
+		if col.rollup == "" {
+			return false
+		}
+	}
+	return true
+}
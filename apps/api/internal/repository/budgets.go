@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+// budgetColumns lists the columns scanned by scanBudget
+const budgetColumns = `id, project_id, name, metric, limit_value, window_hours,
+	COALESCE(tool_name, ''), COALESCE(metadata_key, ''), created_at`
+
+// scanBudget reads a row selected with budgetColumns
+func scanBudget(row pgx.Row) (*models.Budget, error) {
+	var b models.Budget
+	err := row.Scan(&b.ID, &b.ProjectID, &b.Name, &b.Metric, &b.Limit, &b.WindowHours,
+		&b.ToolName, &b.MetadataKey, &b.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// CreateBudget stores a new budget
+func (r *Repository) CreateBudget(ctx context.Context, budget models.Budget) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO budgets (id, project_id, name, metric, limit_value, window_hours, tool_name, metadata_key, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), $9)
+	`, budget.ID, budget.ProjectID, budget.Name, budget.Metric, budget.Limit, budget.WindowHours,
+		budget.ToolName, budget.MetadataKey, budget.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create budget: %w", err)
+	}
+
+	return nil
+}
+
+// ListBudgets returns a project's budgets, oldest first
+func (r *Repository) ListBudgets(ctx context.Context, projectID uuid.UUID) ([]models.Budget, error) {
+	return r.queryBudgets(ctx, `SELECT `+budgetColumns+` FROM budgets WHERE project_id = $1 ORDER BY created_at ASC`, projectID)
+}
+
+// ListAllBudgets returns the budgets of every project, oldest first
+func (r *Repository) ListAllBudgets(ctx context.Context) ([]models.Budget, error) {
+	return r.queryBudgets(ctx, `SELECT `+budgetColumns+` FROM budgets ORDER BY created_at ASC`)
+}
+
+// queryBudgets runs a query selecting budgetColumns
+func (r *Repository) queryBudgets(ctx context.Context, query string, args ...any) ([]models.Budget, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	budgets := []models.Budget{}
+	for rows.Next() {
+		b, err := scanBudget(rows)
+		if err != nil {
+			return nil, err
+		}
+		budgets = append(budgets, *b)
+	}
+
+	return budgets, rows.Err()
+}
+
+// GetBudget returns one of a project's budgets, or ErrNotFound
+func (r *Repository) GetBudget(ctx context.Context, projectID, id uuid.UUID) (*models.Budget, error) {
+	b, err := scanBudget(r.db.QueryRow(ctx,
+		`SELECT `+budgetColumns+` FROM budgets WHERE project_id = $1 AND id = $2`, projectID, id,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return b, err
+}
+
+// DeleteBudget deletes one of a project's budgets, or returns ErrNotFound
+func (r *Repository) DeleteBudget(ctx context.Context, projectID, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM budgets WHERE project_id = $1 AND id = $2`, projectID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+)
+
+// costTotalsColumns selects the cost aggregates scanned into CostTotals
+const costTotalsColumns = `
+	COUNT(*)::bigint,
+	(COUNT(*) FILTER (WHERE model IS NOT NULL AND cost_usd IS NULL))::bigint,
+	COALESCE(SUM(input_tokens), 0)::bigint,
+	COALESCE(SUM(output_tokens), 0)::bigint,
+	COALESCE(SUM(cached_tokens), 0)::bigint,
+	COALESCE(SUM(cost_usd), 0)::float
+`
+
+// GetCostMetrics returns the cost of tool calls by tool, model and time
+// bucket. Costs are always read from raw tool calls, since the rollups
+// don't keep them.
+func (r *Repository) GetCostMetrics(ctx context.Context, q models.MetricsQuery) (*models.CostReport, error) {
+	return store.BuildCostReport(ctx, q, r.GetCostTotals, r.getCostSeries)
+}
+
+// GetCostTotals aggregates tokens and cost per group_by value
+func (r *Repository) GetCostTotals(ctx context.Context, q models.MetricsQuery) ([]models.CostGroup, error) {
+	var args queryArgs
+	where := metricsWhere(q, &args)
+	group := groupExpression(q, &args)
+	if group == "" {
+		group = "''::text"
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+group+` as grp, `+costTotalsColumns+`
+		FROM tool_calls
+		WHERE `+where+`
+		GROUP BY 1
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []models.CostGroup{}
+	for rows.Next() {
+		var g models.CostGroup
+		if err := rows.Scan(&g.Group, &g.Calls, &g.UnpricedCalls, &g.InputTokens, &g.OutputTokens, &g.CachedTokens, &g.CostUSD); err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+
+	return groups, rows.Err()
+}
+
+// getCostSeries returns the total cost per time bucket
+func (r *Repository) getCostSeries(ctx context.Context, q models.MetricsQuery) ([]models.CostDataPoint, error) {
+	rows, err := r.queryBuckets(ctx, q, []bucketColumn{
+		{expr: "SUM(cost_usd)::float", zero: "0"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []models.CostDataPoint
+	for rows.Next() {
+		var dp models.CostDataPoint
+		var group string
+		if err := rows.Scan(&dp.Bucket, &group, &dp.CostUSD); err != nil {
+			return nil, err
+		}
+		dp.Bucket = q.InZone(dp.Bucket)
+		dp.Hour = q.BucketLabel(dp.Bucket)
+		results = append(results, dp)
+	}
+
+	return results, rows.Err()
+}
+
+// ListModelPrices returns all prices, by model and then effective time
+func (r *Repository) ListModelPrices(ctx context.Context) ([]models.ModelPrice, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, model, input_per_million, output_per_million, cached_input_per_million, effective_from, created_at
+		FROM model_prices
+		ORDER BY model, effective_from
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := []models.ModelPrice{}
+	for rows.Next() {
+		var p models.ModelPrice
+		if err := rows.Scan(&p.ID, &p.Model, &p.InputPerMillion, &p.OutputPerMillion, &p.CachedInputPerMillion, &p.EffectiveFrom, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		prices = append(prices, p)
+	}
+
+	return prices, rows.Err()
+}
+
+// SetModelPrice stores a price, replacing the model's price with the same
+// effective time
+func (r *Repository) SetModelPrice(ctx context.Context, price *models.ModelPrice) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO model_prices (id, model, input_per_million, output_per_million, cached_input_per_million, effective_from, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (model, effective_from) DO UPDATE SET
+			input_per_million = EXCLUDED.input_per_million,
+			output_per_million = EXCLUDED.output_per_million,
+			cached_input_per_million = EXCLUDED.cached_input_per_million
+		RETURNING id, created_at
+	`, price.ID, price.Model, price.InputPerMillion, price.OutputPerMillion, price.CachedInputPerMillion,
+		price.EffectiveFrom, price.CreatedAt,
+	).Scan(&price.ID, &price.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to set model price: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteModelPrice deletes a price, or returns ErrNotFound
+func (r *Repository) DeleteModelPrice(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM model_prices WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete model price: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+// queryArgs accumulates positional query arguments
+type queryArgs []interface{}
+
+// add appends a value and returns its placeholder
+func (a *queryArgs) add(value interface{}) string {
+	*a = append(*a, value)
+	return fmt.Sprintf("$%d", len(*a))
+}
+
+// metricsWhere returns the WHERE conditions selecting the project's tool
+// calls in the query's range that match its filter, adding their values to
+// args. When args starts empty, the range bounds are always $1 (from) and
+// $2 (to).
+func metricsWhere(q models.MetricsQuery, args *queryArgs) string {
+	conds := []string{
+		"created_at >= " + args.add(q.From),
+		"created_at < " + args.add(q.To),
+		"project_id = " + args.add(q.ProjectID),
+	}
+
+	f := q.Filter
+	if len(f.ToolNames) > 0 {
+		conds = append(conds, "tool_name = ANY("+args.add(f.ToolNames)+")")
+	}
+	if len(f.ExcludeToolNames) > 0 {
+		conds = append(conds, "tool_name <> ALL("+args.add(f.ExcludeToolNames)+")")
+	}
+	if len(f.Models) > 0 {
+		conds = append(conds, "model = ANY("+args.add(f.Models)+")")
+	}
+	if f.Status != "" {
+		conds = append(conds, "status = "+args.add(f.Status))
+	}
+	if f.MinDurationMs != nil {
+		conds = append(conds, "duration_ms >= "+args.add(*f.MinDurationMs))
+	}
+	if f.MaxDurationMs != nil {
+		conds = append(conds, "duration_ms <= "+args.add(*f.MaxDurationMs))
+	}
+	// Sorted so the generated SQL is stable for the statement cache
+	keys := make([]string, 0, len(f.Metadata))
+	for key := range f.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		conds = append(conds, "metadata ->> "+args.add(key)+"::text = "+args.add(f.Metadata[key]))
+	}
+
+	return strings.Join(conds, " AND ")
+}
+
+// groupExpression returns the SQL expression for the query's group_by
+// dimension, or an empty string when results aren't grouped. Calls missing
+// a model or grouped metadata key fall into the "" group.
+func groupExpression(q models.MetricsQuery, args *queryArgs) string {
+	if q.GroupBy == models.GroupByToolName {
+		return "tool_name"
+	}
+	if q.GroupBy == models.GroupByModel {
+		return "COALESCE(model, '')"
+	}
+	if key, ok := q.GroupByMetadataKey(); ok {
+		return "COALESCE(metadata ->> " + args.add(key) + "::text, '')"
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+// ClaimEventIDs records the event IDs of a project's calls that carry one,
+// so retried events can be recognized. Event IDs are scoped to the
+// project, so teams can't collide with or probe each other's. The result
+// is aligned with calls: nil for calls that are new (or have no event ID),
+// otherwise the originally ingested record for that event ID. An event ID
+// repeated within calls is a duplicate of its first occurrence, so of the
+// original record when the first occurrence is a replay.
+func (r *Repository) ClaimEventIDs(ctx context.Context, projectID uuid.UUID, calls []models.ToolCall) ([]*models.ToolCall, error) {
+	originals := make([]*models.ToolCall, len(calls))
+
+	first := make(map[string]int)
+
+	// Index of the first occurrence of each later occurrence of an event
+	// ID, resolved once the first occurrences are
+	repeats := make(map[int]int)
+	var ids, records []string
+	for i, call := range calls {
+		if call.EventID == nil {
+			continue
+		}
+		if j, ok := first[*call.EventID]; ok {
+			repeats[i] = j
+			continue
+		}
+		first[*call.EventID] = i
+
+		record, err := json.Marshal(call)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode record: %w", err)
+		}
+		ids = append(ids, *call.EventID)
+		records = append(records, string(record))
+	}
+
+	if len(ids) == 0 {
+		return originals, nil
+	}
+
+	// Expired keys are reclaimed; live keys are left untouched and not returned
+	query := `
+		INSERT INTO ingest_keys (project_id, event_id, record, received_at)
+		SELECT $1, k.event_id, k.record, NOW()
+		FROM unnest($2::varchar[], $3::jsonb[]) AS k(event_id, record)
+		ON CONFLICT (project_id, event_id) DO UPDATE
+			SET record = EXCLUDED.record, received_at = EXCLUDED.received_at
+			WHERE ingest_keys.received_at < NOW() - make_interval(secs => $4)
+		RETURNING event_id
+	`
+
+	rows, err := r.db.Query(ctx, query, projectID, ids, records, r.dedupWindow.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim event IDs: %w", err)
+	}
+
+	claimed := make(map[string]bool, len(ids))
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		claimed[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim event IDs: %w", err)
+	}
+
+	var duplicateIDs []string
+	for _, id := range ids {
+		if !claimed[id] {
+			duplicateIDs = append(duplicateIDs, id)
+		}
+	}
+	if len(duplicateIDs) == 0 {
+		resolveRepeats(calls, originals, repeats)
+		return originals, nil
+	}
+
+	rows, err = r.db.Query(ctx, `SELECT event_id, record FROM ingest_keys WHERE project_id = $1 AND event_id = ANY($2)`, projectID, duplicateIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load original records: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var original models.ToolCall
+		if err := rows.Scan(&id, &original); err != nil {
+			return nil, err
+		}
+		originals[first[id]] = &original
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load original records: %w", err)
+	}
+
+	resolveRepeats(calls, originals, repeats)
+	return originals, nil
+}
+
+// resolveRepeats points later occurrences of an event ID at the original
+// record of their first occurrence, or at the first occurrence itself
+// when it is new
+func resolveRepeats(calls []models.ToolCall, originals []*models.ToolCall, repeats map[int]int) {
+	for i, j := range repeats {
+		if originals[j] != nil {
+			originals[i] = originals[j]
+		} else {
+			originals[i] = &calls[j]
+		}
+	}
+}
+
+// ReleaseEventIDs forgets a project's claimed event IDs, e.g. when the
+// events could not be stored, so a retry is not mistaken for a duplicate
+func (r *Repository) ReleaseEventIDs(ctx context.Context, projectID uuid.UUID, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if _, err := r.db.Exec(ctx, `DELETE FROM ingest_keys WHERE project_id = $1 AND event_id = ANY($2)`, projectID, ids); err != nil {
+		return fmt.Errorf("failed to release event IDs: %w", err)
+	}
+
+	return nil
+}
+
+// PruneEventIDs deletes event IDs older than the dedup window
+func (r *Repository) PruneEventIDs(ctx context.Context) (int64, error) {
+	tag, err := r.db.Exec(ctx,
+		`DELETE FROM ingest_keys WHERE received_at < NOW() - make_interval(secs => $1)`,
+		r.dedupWindow.Seconds(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune event IDs: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
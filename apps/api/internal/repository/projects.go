@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+// CreateProject stores a new project
+func (r *Repository) CreateProject(ctx context.Context, project models.Project) error {
+	query := `INSERT INTO projects (id, name, created_at) VALUES ($1, $2, $3)`
+
+	if _, err := r.db.Exec(ctx, query, project.ID, project.Name, project.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create project: %w", err)
+	}
+
+	return nil
+}
+
+// ListProjects returns all projects, oldest first
+func (r *Repository) ListProjects(ctx context.Context) ([]models.Project, error) {
+	rows, err := r.db.Query(ctx, `SELECT id, name, created_at FROM projects ORDER BY created_at ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []models.Project{}
+	for rows.Next() {
+		var p models.Project
+		if err := rows.Scan(&p.ID, &p.Name, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		projects = append(projects, p)
+	}
+
+	return projects, rows.Err()
+}
+
+// GetProject returns a project by ID, or ErrNotFound
+func (r *Repository) GetProject(ctx context.Context, id uuid.UUID) (*models.Project, error) {
+	var p models.Project
+	err := r.db.QueryRow(ctx, `SELECT id, name, created_at FROM projects WHERE id = $1`, id).
+		Scan(&p.ID, &p.Name, &p.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
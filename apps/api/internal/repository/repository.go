@@ -4,20 +4,43 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
 )
 
+// Repository is the PostgreSQL/TimescaleDB store
 type Repository struct {
 	db *pgxpool.Pool
+
+	// How long a claimed event ID blocks duplicates
+	dedupWindow time.Duration
+
+	// Whether long-range metrics read the continuous aggregates
+	rollups bool
 }
 
+var _ store.Store = (*Repository)(nil)
+
+// DefaultDedupWindow is how long event IDs are remembered unless configured
+const DefaultDedupWindow = store.DefaultDedupWindow
+
 func New(db *pgxpool.Pool) *Repository {
-	return &Repository{db: db}
+	return &Repository{db: db, dedupWindow: DefaultDedupWindow}
+}
+
+// SetDedupWindow configures how long client event IDs are deduplicated
+func (r *Repository) SetDedupWindow(window time.Duration) {
+	if window > 0 {
+		r.dedupWindow = window
+	}
 }
 
 // Ping checks database connectivity
@@ -25,121 +48,122 @@ func (r *Repository) Ping(ctx context.Context) error {
 	return r.db.Ping(ctx)
 }
 
-// IngestToolCall stores a tool call event in the database
-func (r *Repository) IngestToolCall(ctx context.Context, event models.ToolCallEvent) error {
-	requestID, err := uuid.Parse(event.RequestID)
-	if err != nil {
-		return fmt.Errorf("invalid request_id: %w", err)
-	}
-
-	var createdAt time.Time
-	if event.Timestamp != nil {
-		createdAt = *event.Timestamp
-	} else {
-		createdAt = time.Now()
-	}
-
-	inputTokens := 0
-	if event.InputTokens != nil {
-		inputTokens = *event.InputTokens
-	}
+// toolCallColumns lists the columns written for each ingested tool call
+var toolCallColumns = []string{
+	"id", "request_id", "tool_name", "duration_ms", "status",
+	"input_tokens", "output_tokens", "error_message", "metadata", "created_at",
+	"span_id", "parent_span_id", "event_id", "project_id",
+	"model", "cached_tokens", "cost_usd",
+}
 
-	outputTokens := 0
-	if event.OutputTokens != nil {
-		outputTokens = *event.OutputTokens
+// toolCallRow returns column values for a tool call matching toolCallColumns
+func toolCallRow(call models.ToolCall) []interface{} {
+	return []interface{}{
+		call.ID, call.RequestID, call.ToolName, call.DurationMs, call.Status,
+		call.InputTokens, call.OutputTokens, call.ErrorMessage, call.Metadata, call.CreatedAt,
+		call.SpanID, call.ParentSpanID, call.EventID, call.ProjectID,
+		call.Model, call.CachedTokens, call.CostUSD,
 	}
+}
 
+// IngestToolCall stores a tool call in the database
+func (r *Repository) IngestToolCall(ctx context.Context, call models.ToolCall) error {
 	query := `
 		INSERT INTO tool_calls (
-			request_id, tool_name, duration_ms, status,
-			input_tokens, output_tokens, error_message, metadata, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			id, request_id, tool_name, duration_ms, status,
+			input_tokens, output_tokens, error_message, metadata, created_at,
+			span_id, parent_span_id, event_id, project_id,
+			model, cached_tokens, cost_usd
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 	`
 
-	// Handle metadata - convert to JSONB, use empty object if nil
-	var metadataJSON interface{}
-	if event.Metadata != nil {
-		metadataJSON = event.Metadata
-	} else {
-		metadataJSON = map[string]interface{}{}
+	if _, err := r.db.Exec(ctx, query, toolCallRow(call)...); err != nil {
+		return fmt.Errorf("failed to insert tool call: %w", err)
 	}
 
-	_, err = r.db.Exec(
-		ctx, query,
-		requestID, event.ToolName, event.DurationMs, event.Status,
-		inputTokens, outputTokens, event.ErrorMessage, metadataJSON, createdAt,
-	)
+	return nil
+}
+
+// IngestToolCalls bulk-inserts tool calls using the COPY protocol
+func (r *Repository) IngestToolCalls(ctx context.Context, calls []models.ToolCall) error {
+	rows := make([][]interface{}, 0, len(calls))
+	for _, call := range calls {
+		rows = append(rows, toolCallRow(call))
+	}
 
+	_, err := r.db.CopyFrom(ctx, pgx.Identifier{"tool_calls"}, toolCallColumns, pgx.CopyFromRows(rows))
 	if err != nil {
-		return fmt.Errorf("failed to insert tool call: %w", err)
+		return fmt.Errorf("failed to copy tool calls: %w", err)
 	}
 
 	return nil
 }
 
-// GetToolCallsMetrics returns aggregated tool call data grouped by hour
-func (r *Repository) GetToolCallsMetrics(ctx context.Context, hours int) ([]models.ToolCallDataPoint, error) {
-	// Use TimescaleDB time_bucket if available, otherwise use date_trunc
-	query := `
-		SELECT 
-			TO_CHAR(time_bucket('1 hour', created_at), 'HH24:MI') as hour,
-			COUNT(*) FILTER (WHERE status = 'success') as success,
-			COUNT(*) FILTER (WHERE status = 'failed') as failures
-		FROM tool_calls
-		WHERE created_at >= NOW() - make_interval(hours => $1)
-		GROUP BY time_bucket('1 hour', created_at)
-		ORDER BY hour
-	`
-
-	rows, err := r.db.Query(ctx, query, hours)
+// GetToolCallsMetrics returns success and failure counts per time bucket
+func (r *Repository) GetToolCallsMetrics(ctx context.Context, q models.MetricsQuery) ([]models.ToolCallDataPoint, error) {
+	rows, err := r.queryBuckets(ctx, q, []bucketColumn{
+		{
+			expr:   "COUNT(*) FILTER (WHERE status = 'success')",
+			rollup: "(SUM(calls) FILTER (WHERE status = 'success'))::bigint",
+			zero:   "0",
+		},
+		{
+			expr:   "COUNT(*) FILTER (WHERE status = 'failed')",
+			rollup: "(SUM(calls) FILTER (WHERE status = 'failed'))::bigint",
+			zero:   "0",
+		},
+	})
 	if err != nil {
-		// Fallback to standard PostgreSQL if TimescaleDB not available
-		query = `
-			SELECT 
-				TO_CHAR(date_trunc('hour', created_at), 'HH24:MI') as hour,
-				COUNT(*) FILTER (WHERE status = 'success') as success,
-				COUNT(*) FILTER (WHERE status = 'failed') as failures
-			FROM tool_calls
-			WHERE created_at >= NOW() - make_interval(hours => $1)
-			GROUP BY date_trunc('hour', created_at)
-			ORDER BY hour
-		`
-		rows, err = r.db.Query(ctx, query, hours)
-		if err != nil {
-			return nil, err
-		}
+		return nil, err
 	}
 	defer rows.Close()
 
 	var results []models.ToolCallDataPoint
 	for rows.Next() {
 		var dp models.ToolCallDataPoint
-		if err := rows.Scan(&dp.Hour, &dp.Success, &dp.Failures); err != nil {
+		if err := rows.Scan(&dp.Bucket, &dp.Group, &dp.Success, &dp.Failures); err != nil {
 			return nil, err
 		}
+		dp.Bucket = q.InZone(dp.Bucket)
+		dp.Hour = q.BucketLabel(dp.Bucket)
 		results = append(results, dp)
 	}
 
-	return results, nil
+	return results, rows.Err()
 }
 
-// GetLatencyMetrics returns latency percentiles per tool
-func (r *Repository) GetLatencyMetrics(ctx context.Context, hours int) ([]models.LatencyDataPoint, error) {
-	query := `
+// GetLatencyMetrics returns latency percentiles per tool. Unless the query
+// filters on status, only successful calls are measured. Percentiles read
+// from the rollups are approximated from their percentile sketches.
+func (r *Repository) GetLatencyMetrics(ctx context.Context, q models.MetricsQuery) ([]models.LatencyDataPoint, error) {
+	var args queryArgs
+	where := metricsWhere(q, &args)
+	if q.Filter.Status == "" {
+		where += " AND status = 'success'"
+	}
+
+	group := groupExpression(q, &args)
+	if group == "" {
+		group = "''::text"
+	}
+
+	source, rolled := r.rollupSource(q, false, &args)
+	if !rolled {
+		source = "tool_calls"
+	}
+
+	query := fmt.Sprintf(`
 		SELECT 
-			tool_name,
-			COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY duration_ms), 0)::float as p50,
-			COALESCE(PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY duration_ms), 0)::float as p95,
-			COALESCE(PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY duration_ms), 0)::float as p99
-		FROM tool_calls
-		WHERE created_at >= NOW() - make_interval(hours => $1)
-			AND status = 'success'
-		GROUP BY tool_name
+			%s as grp,
+			tool_name,%s
+		FROM %s
+		WHERE %s
+		GROUP BY 1, 2
 		HAVING COUNT(*) > 0
-		ORDER BY tool_name
-	`
+		ORDER BY 1, 2
+	`, group, latencyPercentiles(rolled), source, where)
 
-	rows, err := r.db.Query(ctx, query, hours)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query error: %w", err)
 	}
@@ -148,213 +172,334 @@ func (r *Repository) GetLatencyMetrics(ctx context.Context, hours int) ([]models
 	var results []models.LatencyDataPoint
 	for rows.Next() {
 		var dp models.LatencyDataPoint
-		if err := rows.Scan(&dp.Tool, &dp.P50, &dp.P95, &dp.P99); err != nil {
+		if err := rows.Scan(&dp.Group, &dp.Tool, &dp.P50, &dp.P95, &dp.P99); err != nil {
 			return nil, fmt.Errorf("scan error: %w", err)
 		}
 		results = append(results, dp)
 	}
 
-	return results, nil
+	return results, rows.Err()
 }
 
-// GetTokenUsageMetrics returns token usage aggregated by hour
-func (r *Repository) GetTokenUsageMetrics(ctx context.Context, hours int) ([]models.TokenUsageDataPoint, error) {
-	query := `
+// GetLatencyTotals returns latency percentiles across all matching calls,
+// like GetLatencyMetrics without splitting by tool, or nil when no call
+// matched
+func (r *Repository) GetLatencyTotals(ctx context.Context, q models.MetricsQuery) (*models.LatencyDataPoint, error) {
+	var args queryArgs
+	where := metricsWhere(q, &args)
+	if q.Filter.Status == "" {
+		where += " AND status = 'success'"
+	}
+
+	source, rolled := r.rollupSource(q, false, &args)
+	count := "COUNT(*)"
+	if rolled {
+		count = "COALESCE(SUM(calls), 0)"
+	} else {
+		source = "tool_calls"
+	}
+
+	query := fmt.Sprintf(`
 		SELECT 
-			TO_CHAR(time_bucket('1 hour', created_at), 'HH24:MI') as hour,
-			COALESCE(SUM(input_tokens), 0)::int as input,
-			COALESCE(SUM(output_tokens), 0)::int as output
-		FROM tool_calls
-		WHERE created_at >= NOW() - make_interval(hours => $1)
-		GROUP BY time_bucket('1 hour', created_at)
-		ORDER BY hour
-	`
+			%s,%s
+		FROM %s
+		WHERE %s
+	`, count, latencyPercentiles(rolled), source, where)
+
+	var calls int64
+	var dp models.LatencyDataPoint
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&calls, &dp.P50, &dp.P95, &dp.P99); err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	if calls == 0 {
+		return nil, nil
+	}
+	return &dp, nil
+}
 
-	rows, err := r.db.Query(ctx, query, hours)
+// GetWindowCounts counts the matching calls of trailing windows ending at
+// q.To in a single scan of tool_calls, one result per start
+func (r *Repository) GetWindowCounts(ctx context.Context, q models.MetricsQuery, starts []time.Time, fastMs int) ([]models.WindowCounts, error) {
+	if len(starts) == 0 {
+		return nil, nil
+	}
+	q.From = slices.MinFunc(starts, time.Time.Compare)
+
+	var args queryArgs
+	where := metricsWhere(q, &args)
+	fast := "FALSE"
+	if fastMs > 0 {
+		fast = "duration_ms <= " + args.add(fastMs)
+	}
+
+	columns := make([]string, 0, 3*len(starts))
+	for _, start := range starts {
+		since := "created_at >= " + args.add(start)
+		columns = append(columns,
+			fmt.Sprintf("COUNT(*) FILTER (WHERE %s AND status = 'success')", since),
+			fmt.Sprintf("COUNT(*) FILTER (WHERE %s AND status = 'failed')", since),
+			fmt.Sprintf("COUNT(*) FILTER (WHERE %s AND status = 'success' AND %s)", since, fast),
+		)
+	}
+
+	counts := make([]models.WindowCounts, len(starts))
+	dest := make([]interface{}, 0, 3*len(starts))
+	for i := range counts {
+		dest = append(dest, &counts[i].Success, &counts[i].Failures, &counts[i].FastSuccess)
+	}
+	query := fmt.Sprintf(`SELECT %s FROM tool_calls WHERE %s`, strings.Join(columns, ", "), where)
+	if err := r.db.QueryRow(ctx, query, args...).Scan(dest...); err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	return counts, nil
+}
+
+// latencyPercentiles returns the p50, p95 and p99 columns of a latency
+// query, approximated from the percentile sketches when reading rollups
+func latencyPercentiles(rolled bool) string {
+	if rolled {
+		return `
+			COALESCE(approx_percentile(0.5, rollup(latency)), 0) as p50,
+			COALESCE(approx_percentile(0.95, rollup(latency)), 0) as p95,
+			COALESCE(approx_percentile(0.99, rollup(latency)), 0) as p99`
+	}
+	return `
+			COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY duration_ms), 0)::float as p50,
+			COALESCE(PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY duration_ms), 0)::float as p95,
+			COALESCE(PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY duration_ms), 0)::float as p99`
+}
+
+// GetTokenUsageMetrics returns token usage per time bucket
+func (r *Repository) GetTokenUsageMetrics(ctx context.Context, q models.MetricsQuery) ([]models.TokenUsageDataPoint, error) {
+	rows, err := r.queryBuckets(ctx, q, []bucketColumn{
+		{expr: "SUM(input_tokens)::bigint", rollup: "SUM(input_tokens)::bigint", zero: "0"},
+		{expr: "SUM(output_tokens)::bigint", rollup: "SUM(output_tokens)::bigint", zero: "0"},
+	})
 	if err != nil {
-		// Fallback to standard PostgreSQL
-		query = `
-			SELECT 
-				TO_CHAR(date_trunc('hour', created_at), 'HH24:MI') as hour,
-				COALESCE(SUM(input_tokens), 0)::int as input,
-				COALESCE(SUM(output_tokens), 0)::int as output
-			FROM tool_calls
-			WHERE created_at >= NOW() - make_interval(hours => $1)
-			GROUP BY date_trunc('hour', created_at)
-			ORDER BY hour
-		`
-		rows, err = r.db.Query(ctx, query, hours)
-		if err != nil {
-			return nil, err
-		}
+		return nil, err
 	}
 	defer rows.Close()
 
 	var results []models.TokenUsageDataPoint
 	for rows.Next() {
 		var dp models.TokenUsageDataPoint
-		if err := rows.Scan(&dp.Hour, &dp.Input, &dp.Output); err != nil {
+		if err := rows.Scan(&dp.Bucket, &dp.Group, &dp.Input, &dp.Output); err != nil {
 			return nil, err
 		}
+		dp.Bucket = q.InZone(dp.Bucket)
+		dp.Hour = q.BucketLabel(dp.Bucket)
 		results = append(results, dp)
 	}
 
-	return results, nil
+	return results, rows.Err()
 }
 
-// GetFailureRateMetrics returns failure rate aggregated by hour
-func (r *Repository) GetFailureRateMetrics(ctx context.Context, hours int) ([]models.FailureRateDataPoint, error) {
-	query := `
-		SELECT 
-			TO_CHAR(time_bucket('1 hour', created_at), 'HH24:MI') as hour,
-			CASE 
-				WHEN COUNT(*) > 0 THEN 
-					(COUNT(*) FILTER (WHERE status = 'failed')::float / COUNT(*)::float * 100)
-				ELSE 0
-			END as failure_percent
-		FROM tool_calls
-		WHERE created_at >= NOW() - make_interval(hours => $1)
-		GROUP BY time_bucket('1 hour', created_at)
-		ORDER BY hour
-	`
-
-	rows, err := r.db.Query(ctx, query, hours)
+// GetFailureRateMetrics returns the failure percentage per time bucket
+func (r *Repository) GetFailureRateMetrics(ctx context.Context, q models.MetricsQuery) ([]models.FailureRateDataPoint, error) {
+	rows, err := r.queryBuckets(ctx, q, []bucketColumn{
+		{
+			expr:   "(COUNT(*) FILTER (WHERE status = 'failed')::float / NULLIF(COUNT(*), 0)::float * 100)",
+			rollup: "(COALESCE(SUM(calls) FILTER (WHERE status = 'failed'), 0)::float / NULLIF(SUM(calls), 0)::float * 100)",
+			zero:   "0",
+		},
+	})
 	if err != nil {
-		// Fallback to standard PostgreSQL
-		query = `
-			SELECT 
-				TO_CHAR(date_trunc('hour', created_at), 'HH24:MI') as hour,
-				CASE 
-					WHEN COUNT(*) > 0 THEN 
-						(COUNT(*) FILTER (WHERE status = 'failed')::float / COUNT(*)::float * 100)
-					ELSE 0
-				END as failure_percent
-			FROM tool_calls
-			WHERE created_at >= NOW() - make_interval(hours => $1)
-			GROUP BY date_trunc('hour', created_at)
-			ORDER BY hour
-		`
-		rows, err = r.db.Query(ctx, query, hours)
-		if err != nil {
-			return nil, err
-		}
+		return nil, err
 	}
 	defer rows.Close()
 
 	var results []models.FailureRateDataPoint
 	for rows.Next() {
 		var dp models.FailureRateDataPoint
-		if err := rows.Scan(&dp.Hour, &dp.FailurePercent); err != nil {
+		if err := rows.Scan(&dp.Bucket, &dp.Group, &dp.FailurePercent); err != nil {
 			return nil, err
 		}
+		dp.Bucket = q.InZone(dp.Bucket)
+		dp.Hour = q.BucketLabel(dp.Bucket)
 		results = append(results, dp)
 	}
 
-	return results, nil
+	return results, rows.Err()
 }
 
-// GetRecentToolCalls returns the most recent tool calls
-func (r *Repository) GetRecentToolCalls(ctx context.Context, limit int) ([]models.ToolCall, error) {
-	query := `
-		SELECT 
-			id, request_id, tool_name, duration_ms, status,
-			input_tokens, output_tokens, error_message, metadata, created_at
-		FROM tool_calls
-		ORDER BY created_at DESC
-		LIMIT $1
-	`
+// toolCallSelectColumns lists the columns scanned by scanToolCalls
+const toolCallSelectColumns = `
+	id, request_id, tool_name, duration_ms, status,
+	input_tokens, output_tokens, error_message, metadata, created_at,
+	span_id, parent_span_id, event_id, project_id,
+	model, cached_tokens, cost_usd
+`
 
-	rows, err := r.db.Query(ctx, query, limit)
-	if err != nil {
-		return nil, err
-	}
+// scanToolCalls reads tool call rows selected with toolCallSelectColumns
+func scanToolCalls(rows pgx.Rows) ([]models.ToolCall, error) {
 	defer rows.Close()
 
 	var results []models.ToolCall
 	for rows.Next() {
 		var tc models.ToolCall
-		var errorMsg sql.NullString
+		var errorMsg, spanID, parentSpanID, eventID sql.NullString
 		if err := rows.Scan(
 			&tc.ID, &tc.RequestID, &tc.ToolName, &tc.DurationMs, &tc.Status,
 			&tc.InputTokens, &tc.OutputTokens, &errorMsg, &tc.Metadata, &tc.CreatedAt,
+			&spanID, &parentSpanID, &eventID, &tc.ProjectID,
+			&tc.Model, &tc.CachedTokens, &tc.CostUSD,
 		); err != nil {
 			return nil, err
 		}
 		if errorMsg.Valid {
 			tc.ErrorMessage = &errorMsg.String
 		}
+		if spanID.Valid {
+			tc.SpanID = &spanID.String
+		}
+		if parentSpanID.Valid {
+			tc.ParentSpanID = &parentSpanID.String
+		}
+		if eventID.Valid {
+			tc.EventID = &eventID.String
+		}
 		results = append(results, tc)
 	}
 
-	return results, nil
+	return results, rows.Err()
 }
 
-// GetToolCallChain returns all tool calls for a specific request ID
-func (r *Repository) GetToolCallChain(ctx context.Context, requestID uuid.UUID) ([]models.ToolCall, error) {
+// GetRecentToolCalls returns the most recent tool calls in a project
+func (r *Repository) GetRecentToolCalls(ctx context.Context, projectID uuid.UUID, limit int) ([]models.ToolCall, error) {
 	query := `
-		SELECT 
-			id, request_id, tool_name, duration_ms, status,
-			input_tokens, output_tokens, error_message, metadata, created_at
+		SELECT ` + toolCallSelectColumns + `
+		FROM tool_calls
+		WHERE project_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(ctx, query, projectID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanToolCalls(rows)
+}
+
+// GetToolCallChain returns all tool calls in a project for a specific request ID
+func (r *Repository) GetToolCallChain(ctx context.Context, projectID, requestID uuid.UUID) ([]models.ToolCall, error) {
+	query := `
+		SELECT ` + toolCallSelectColumns + `
 		FROM tool_calls
-		WHERE request_id = $1
+		WHERE project_id = $1 AND request_id = $2
 		ORDER BY created_at ASC
 	`
 
-	rows, err := r.db.Query(ctx, query, requestID)
+	rows, err := r.db.Query(ctx, query, projectID, requestID)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var results []models.ToolCall
-	for rows.Next() {
-		var tc models.ToolCall
-		var errorMsg sql.NullString
-		if err := rows.Scan(
-			&tc.ID, &tc.RequestID, &tc.ToolName, &tc.DurationMs, &tc.Status,
-			&tc.InputTokens, &tc.OutputTokens, &errorMsg, &tc.Metadata, &tc.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		if errorMsg.Valid {
-			tc.ErrorMessage = &errorMsg.String
-		}
-		results = append(results, tc)
+	return scanToolCalls(rows)
+}
+
+// GetToolCallTree returns the tool calls for a request ID arranged by
+// their span parent/child relationships
+func (r *Repository) GetToolCallTree(ctx context.Context, projectID, requestID uuid.UUID) (*models.ToolCallTree, error) {
+	calls, err := r.GetToolCallChain(ctx, projectID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.BuildToolCallTree(requestID, calls), nil
+}
+
+// GetMetricsOverview returns aggregated overview metrics for the query's
+// range, compared against the same-length window compareOffset earlier.
+// A zero compareOffset compares against the immediately preceding window.
+// Grouped queries also report totals per group for the current range.
+func (r *Repository) GetMetricsOverview(ctx context.Context, q models.MetricsQuery, compareOffset time.Duration) (*models.MetricsOverview, error) {
+	return store.BuildOverview(ctx, q, compareOffset, r.GetMetricsTotals, r.getGroupTotals)
+}
+
+// metricsTotalsColumns selects the overview aggregates scanned into MetricsTotals
+const metricsTotalsColumns = `
+	COUNT(*)::bigint as total_calls,
+	COALESCE(AVG(duration_ms), 0) as avg_latency_ms,
+	COALESCE(SUM(input_tokens + output_tokens), 0)::bigint as total_tokens,
+	CASE 
+		WHEN COUNT(*) > 0 THEN 
+			(COUNT(*) FILTER (WHERE status = 'failed')::float / COUNT(*)::float * 100)
+		ELSE 0
+	END as failure_rate
+`
+
+// rollupTotalsColumns is metricsTotalsColumns over rollup rows
+const rollupTotalsColumns = `
+	COALESCE(SUM(calls), 0)::bigint as total_calls,
+	COALESCE(SUM(duration_sum)::float / NULLIF(SUM(calls), 0), 0) as avg_latency_ms,
+	(COALESCE(SUM(input_tokens), 0) + COALESCE(SUM(output_tokens), 0))::bigint as total_tokens,
+	COALESCE(COALESCE(SUM(calls) FILTER (WHERE status = 'failed'), 0)::float / NULLIF(SUM(calls), 0)::float * 100, 0) as failure_rate
+`
+
+// totalsSource returns the FROM item and overview aggregates for the
+// query: rollups for long ranges they can answer, otherwise raw tool calls
+func (r *Repository) totalsSource(q models.MetricsQuery, args *queryArgs) (string, string) {
+	if source, ok := r.rollupSource(q, false, args); ok {
+		return source, rollupTotalsColumns
+	}
+	return "tool_calls", metricsTotalsColumns
+}
+
+// GetMetricsTotals aggregates the overview metrics for the query's range
+// and filter, without the overview's comparison
+func (r *Repository) GetMetricsTotals(ctx context.Context, q models.MetricsQuery) (*models.MetricsTotals, error) {
+	var args queryArgs
+	where := metricsWhere(q, &args)
+	source, columns := r.totalsSource(q, &args)
+	query := `
+		SELECT ` + columns + `
+		FROM ` + source + `
+		WHERE ` + where
+
+	var totals models.MetricsTotals
+	err := r.db.QueryRow(ctx, query, args...).Scan(
+		&totals.TotalCalls,
+		&totals.AvgLatencyMs,
+		&totals.TotalTokens,
+		&totals.FailureRate,
+	)
+	if err != nil {
+		return nil, err
 	}
 
-	return results, nil
+	return &totals, nil
 }
 
-// GetMetricsOverview returns aggregated overview metrics
-func (r *Repository) GetMetricsOverview(ctx context.Context, hours int) (*models.MetricsOverview, error) {
+// getGroupTotals aggregates the overview metrics per group_by value,
+// busiest groups first
+func (r *Repository) getGroupTotals(ctx context.Context, q models.MetricsQuery) ([]models.GroupTotals, error) {
+	var args queryArgs
+	where := metricsWhere(q, &args)
+	source, columns := r.totalsSource(q, &args)
 	query := `
-		SELECT 
-			COUNT(*)::bigint as total_calls,
-			COALESCE(AVG(duration_ms), 0) as avg_latency_ms,
-			COALESCE(SUM(input_tokens + output_tokens), 0)::bigint as total_tokens,
-			CASE 
-				WHEN COUNT(*) > 0 THEN 
-					(COUNT(*) FILTER (WHERE status = 'failed')::float / COUNT(*)::float * 100)
-				ELSE 0
-			END as failure_rate
-		FROM tool_calls
-		WHERE created_at >= NOW() - make_interval(hours => $1)
+		SELECT ` + groupExpression(q, &args) + ` as grp, ` + columns + `
+		FROM ` + source + `
+		WHERE ` + where + `
+		GROUP BY 1
+		ORDER BY 2 DESC, 1
 	`
 
-	var overview models.MetricsOverview
-	err := r.db.QueryRow(ctx, query, hours).Scan(
-		&overview.TotalCalls,
-		&overview.AvgLatencyMs,
-		&overview.TotalTokens,
-		&overview.FailureRate,
-	)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	// Calculate change percent (simplified - compare with previous period)
-	// In production, you'd want to compare with the same period from before
-	overview.ChangePercent = 0.0 // TODO: Implement proper comparison
+	groups := []models.GroupTotals{}
+	for rows.Next() {
+		var g models.GroupTotals
+		if err := rows.Scan(&g.Group, &g.TotalCalls, &g.AvgLatencyMs, &g.TotalTokens, &g.FailureRate); err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
 
-	return &overview, nil
+	return groups, rows.Err()
 }
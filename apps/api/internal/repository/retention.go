@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+)
+
+// policyDays selects the days of a policy setting of a TimescaleDB job,
+// or 0 when there is no such job
+const policyDays = `COALESCE((
+	SELECT (EXTRACT(EPOCH FROM (j.config ->> '%[1]s')::interval) / 86400)::int
+	FROM timescaledb_information.jobs j
+	WHERE j.proc_name = '%[2]s' AND j.hypertable_name = %[3]s
+	LIMIT 1
+), 0)`
+
+// GetDataPolicies returns the retention and compression policies, read
+// from the TimescaleDB jobs enforcing them
+func (r *Repository) GetDataPolicies(ctx context.Context) (*models.DataPolicies, error) {
+	if err := r.requireTimescale(ctx); err != nil {
+		return nil, err
+	}
+
+	// Rollup policies are jobs on the daily rollup's materialization table
+	rollupTable := `(
+		SELECT materialization_hypertable_name
+		FROM timescaledb_information.continuous_aggregates
+		WHERE view_name = 'tool_calls_daily'
+	)`
+
+	var policies models.DataPolicies
+	err := r.db.QueryRow(ctx, `SELECT `+
+		fmt.Sprintf(policyDays, "drop_after", "policy_retention", "'tool_calls'")+`, `+
+		fmt.Sprintf(policyDays, "drop_after", "policy_retention", rollupTable)+`, `+
+		fmt.Sprintf(policyDays, "compress_after", "policy_compression", "'tool_calls'"),
+	).Scan(&policies.RawRetentionDays, &policies.RollupRetentionDays, &policies.CompressAfterDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data policies: %w", err)
+	}
+
+	return &policies, nil
+}
+
+// SetDataPolicies replaces the TimescaleDB retention and compression
+// policies. With raw retention set, the rollups only refresh within the
+// retained range, so dropping raw chunks never removes rolled-up data.
+func (r *Repository) SetDataPolicies(ctx context.Context, policies models.DataPolicies) error {
+	if err := r.requireTimescale(ctx); err != nil {
+		return err
+	}
+	if policies.RollupRetentionDays > 0 && !r.rollups {
+		return fmt.Errorf("%w: rollup retention needs the rollups, which require timescaledb_toolkit", store.ErrUnsupported)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update data policies: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := setRetentionPolicy(ctx, tx, "tool_calls", policies.RawRetentionDays); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT remove_compression_policy('tool_calls', if_exists => TRUE)`); err != nil {
+		return fmt.Errorf("failed to remove compression policy: %w", err)
+	}
+	if policies.CompressAfterDays > 0 {
+		_, err := tx.Exec(ctx,
+			`SELECT add_compression_policy('tool_calls', compress_after => make_interval(days => $1::int))`,
+			policies.CompressAfterDays,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add compression policy: %w", err)
+		}
+	}
+
+	if r.rollups {
+		for _, ru := range rollups {
+			if err := setRetentionPolicy(ctx, tx, ru.view, policies.RollupRetentionDays); err != nil {
+				return err
+			}
+
+			if _, err := tx.Exec(ctx, `SELECT remove_continuous_aggregate_policy($1::text::regclass, if_exists => TRUE)`, ru.view); err != nil {
+				return fmt.Errorf("failed to remove refresh policy: %w", err)
+			}
+			_, err := tx.Exec(ctx, `
+				SELECT add_continuous_aggregate_policy($1::text::regclass,
+					start_offset => CASE WHEN $2::int > 0 THEN make_interval(days => $2::int) END,
+					end_offset => $3::text::interval,
+					schedule_interval => $4::text::interval)
+			`, ru.view, policies.RawRetentionDays, ru.refreshLag, ru.refreshEvery)
+			if err != nil {
+				return fmt.Errorf("failed to add refresh policy: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to update data policies: %w", err)
+	}
+	return nil
+}
+
+// setRetentionPolicy replaces the retention policy of a hypertable or
+// continuous aggregate; 0 days removes it
+func setRetentionPolicy(ctx context.Context, tx pgx.Tx, relation string, days int) error {
+	if _, err := tx.Exec(ctx, `SELECT remove_retention_policy($1::text::regclass, if_exists => TRUE)`, relation); err != nil {
+		return fmt.Errorf("failed to remove retention policy: %w", err)
+	}
+	if days == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `SELECT add_retention_policy($1::text::regclass, drop_after => make_interval(days => $2::int))`, relation, days)
+	if err != nil {
+		return fmt.Errorf("failed to add retention policy: %w", err)
+	}
+	return nil
+}
+
+// requireTimescale returns an error wrapping store.ErrUnsupported unless
+// the database runs TimescaleDB, which enforces the policies
+func (r *Repository) requireTimescale(ctx context.Context) error {
+	var installed bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'timescaledb')`).Scan(&installed)
+	if err != nil {
+		return fmt.Errorf("failed to detect TimescaleDB: %w", err)
+	}
+	if !installed {
+		return fmt.Errorf("%w: retention and compression policies require TimescaleDB", store.ErrUnsupported)
+	}
+	return nil
+}
+
+// EnforceRetention does nothing: TimescaleDB jobs enforce the policies
+func (r *Repository) EnforceRetention(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+// DeleteToolCalls deletes a project's tool calls matching the filter and
+// their claimed event IDs. Rollups pick up the deletion on their next
+// refresh.
+func (r *Repository) DeleteToolCalls(ctx context.Context, projectID uuid.UUID, filter models.DeleteFilter) (int64, error) {
+	var args queryArgs
+	conds := []string{"project_id = " + args.add(projectID)}
+	if filter.RequestID != nil {
+		conds = append(conds, "request_id = "+args.add(*filter.RequestID))
+	}
+	if filter.ToolName != "" {
+		conds = append(conds, "tool_name = "+args.add(filter.ToolName))
+	}
+	if !filter.From.IsZero() {
+		conds = append(conds, "created_at >= "+args.add(filter.From))
+	}
+	if !filter.To.IsZero() {
+		conds = append(conds, "created_at < "+args.add(filter.To))
+	}
+	keys := make([]string, 0, len(filter.Metadata))
+	for key := range filter.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		conds = append(conds, "metadata ->> "+args.add(key)+"::text = "+args.add(filter.Metadata[key]))
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete tool calls: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var deleted int64
+	var eventIDs []string
+	err = tx.QueryRow(ctx, `
+		WITH deleted AS (
+			DELETE FROM tool_calls WHERE `+strings.Join(conds, " AND ")+`
+			RETURNING event_id
+		)
+		SELECT COUNT(*), COALESCE(array_agg(event_id) FILTER (WHERE event_id IS NOT NULL), '{}')
+		FROM deleted
+	`, args...).Scan(&deleted, &eventIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete tool calls: %w", err)
+	}
+
+	// Deduplication records hold a copy of the event
+	if len(eventIDs) > 0 {
+		_, err := tx.Exec(ctx, `DELETE FROM ingest_keys WHERE project_id = $1 AND event_id = ANY($2)`, projectID, eventIDs)
+		if err != nil {
+			return 0, fmt.Errorf("failed to delete event IDs: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to delete tool calls: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+)
+
+// rollup is a continuous aggregate of tool calls per project, tool, status
+// and bucket (see the add_metric_rollups migration)
+type rollup struct {
+	view   string
+	bucket time.Duration
+
+	// Shortest query range read from this rollup
+	minRange time.Duration
+
+	// Refresh policy: how far behind now it materializes, and how often
+	refreshLag, refreshEvery string
+}
+
+// Rollups, coarsest first
+var rollups = []rollup{
+	{
+		view: "tool_calls_daily", bucket: 24 * time.Hour, minRange: 14 * 24 * time.Hour,
+		refreshLag: "1 day", refreshEvery: "1 hour",
+	},
+	{
+		view: "tool_calls_hourly", bucket: time.Hour, minRange: 6 * time.Hour,
+		refreshLag: "1 hour", refreshEvery: "30 minutes",
+	},
+}
+
+// DetectRollups enables reading metrics from the continuous aggregates
+// when the database has them, returning whether it does. They are only
+// created on TimescaleDB with the timescaledb_toolkit extension.
+func (r *Repository) DetectRollups(ctx context.Context) (bool, error) {
+	var hourly, daily bool
+	err := r.db.QueryRow(ctx, `
+		SELECT to_regclass('tool_calls_hourly') IS NOT NULL, to_regclass('tool_calls_daily') IS NOT NULL
+	`).Scan(&hourly, &daily)
+	if err != nil {
+		return false, fmt.Errorf("failed to detect rollups: %w", err)
+	}
+
+	r.rollups = hourly && daily
+	return r.rollups, nil
+}
+
+// rollupSource returns a FROM item of pre-aggregated rows covering the
+// query's range, for queries the rollups can answer; ok is false when the
+// metrics must be computed from raw tool calls. bucketed is set for
+// time-series queries, whose buckets must be made of whole rollup buckets.
+//
+// The rows have the rollup columns (with the bucket start as created_at,
+// so metricsWhere applies): full rollup buckets inside the range come from
+// the longest rollup the query allows, and the partial buckets at either
+// edge are aggregated from tool_calls into one row per tool and status,
+// placed at the start of their part of the range.
+func (r *Repository) rollupSource(q models.MetricsQuery, bucketed bool, args *queryArgs) (string, bool) {
+	if !r.rollups || !rollupFilter(q) {
+		return "", false
+	}
+
+	for _, ru := range rollups {
+		if q.Duration() < ru.minRange || (bucketed && !ru.alignsWith(q)) {
+			continue
+		}
+
+		start := q.From.Truncate(ru.bucket)
+		if start.Before(q.From) {
+			start = start.Add(ru.bucket)
+		}
+		end := q.To.Truncate(ru.bucket)
+		if !start.Before(end) {
+			continue
+		}
+
+		from, to := args.add(q.From), args.add(q.To)
+		rolledFrom, rolledTo := args.add(start), args.add(end)
+		return fmt.Sprintf(`(
+			SELECT bucket as created_at, project_id, tool_name, status, calls, duration_sum, input_tokens, output_tokens, latency
+			FROM %[1]s
+			WHERE bucket >= %[4]s::timestamptz AND bucket < %[5]s::timestamptz
+			UNION ALL
+			SELECT %[2]s::timestamptz, project_id, tool_name, status, `+rawRollupColumns+`
+			FROM tool_calls
+			WHERE created_at >= %[2]s::timestamptz AND created_at < %[4]s::timestamptz
+			GROUP BY project_id, tool_name, status
+			UNION ALL
+			SELECT %[5]s::timestamptz, project_id, tool_name, status, `+rawRollupColumns+`
+			FROM tool_calls
+			WHERE created_at >= %[5]s::timestamptz AND created_at < %[3]s::timestamptz
+			GROUP BY project_id, tool_name, status
+		) rolled`, ru.view, from, to, rolledFrom, rolledTo), true
+	}
+
+	return "", false
+}
+
+// rawRollupColumns aggregates raw tool calls into the rollup columns
+const rawRollupColumns = `COUNT(*), SUM(duration_ms), SUM(input_tokens), SUM(output_tokens), percentile_agg(duration_ms::double precision)`
+
+// rollupFilter reports whether the query only filters and groups by
+// columns the rollups keep
+func rollupFilter(q models.MetricsQuery) bool {
+	f := q.Filter
+	if f.MinDurationMs != nil || f.MaxDurationMs != nil || len(f.Models) > 0 || len(f.Metadata) > 0 {
+		return false
+	}
+	return q.GroupBy == "" || q.GroupBy == models.GroupByToolName
+}
+
+// alignsWith reports whether every bucket of the query is made of whole
+// rollup buckets. Rollup buckets are aligned in UTC, so each query bucket
+// must start on a rollup bucket boundary, which rules out e.g. daily
+// rollups for local-midnight buckets outside UTC.
+func (ru rollup) alignsWith(q models.MetricsQuery) bool {
+	if q.Interval <= 0 || q.Interval%ru.bucket != 0 {
+		return false
+	}
+
+	for _, start := range store.BucketStarts(q) {
+		if !start.Equal(start.Truncate(ru.bucket)) {
+			return false
+		}
+	}
+	return true
+}
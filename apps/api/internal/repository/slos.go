@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+// sloColumns lists the columns scanned by scanSLO
+const sloColumns = `id, project_id, name, type, objective, COALESCE(latency_threshold_ms, 0), window_days, filter, created_at`
+
+// scanSLO reads a row selected with sloColumns
+func scanSLO(row pgx.Row) (*models.SLO, error) {
+	var slo models.SLO
+	err := row.Scan(&slo.ID, &slo.ProjectID, &slo.Name, &slo.Type, &slo.Objective,
+		&slo.LatencyThresholdMs, &slo.WindowDays, &slo.Filter, &slo.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &slo, nil
+}
+
+// CreateSLO stores a new SLO
+func (r *Repository) CreateSLO(ctx context.Context, slo models.SLO) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO slos (id, project_id, name, type, objective, latency_threshold_ms, window_days, filter, created_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0), $7, $8, $9)
+	`, slo.ID, slo.ProjectID, slo.Name, slo.Type, slo.Objective, slo.LatencyThresholdMs, slo.WindowDays, slo.Filter, slo.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create SLO: %w", err)
+	}
+
+	return nil
+}
+
+// ListSLOs returns a project's SLOs, oldest first
+func (r *Repository) ListSLOs(ctx context.Context, projectID uuid.UUID) ([]models.SLO, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+sloColumns+` FROM slos WHERE project_id = $1 ORDER BY created_at ASC`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slos := []models.SLO{}
+	for rows.Next() {
+		slo, err := scanSLO(rows)
+		if err != nil {
+			return nil, err
+		}
+		slos = append(slos, *slo)
+	}
+
+	return slos, rows.Err()
+}
+
+// GetSLO returns one of a project's SLOs, or ErrNotFound
+func (r *Repository) GetSLO(ctx context.Context, projectID, id uuid.UUID) (*models.SLO, error) {
+	slo, err := scanSLO(r.db.QueryRow(ctx,
+		`SELECT `+sloColumns+` FROM slos WHERE project_id = $1 AND id = $2`, projectID, id,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return slo, err
+}
+
+// UpdateSLO replaces an SLO's definition, or returns ErrNotFound
+func (r *Repository) UpdateSLO(ctx context.Context, slo models.SLO) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE slos
+		SET name = $3, type = $4, objective = $5, latency_threshold_ms = NULLIF($6, 0), window_days = $7, filter = $8
+		WHERE project_id = $1 AND id = $2
+	`, slo.ProjectID, slo.ID, slo.Name, slo.Type, slo.Objective, slo.LatencyThresholdMs, slo.WindowDays, slo.Filter)
+	if err != nil {
+		return fmt.Errorf("failed to update SLO: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteSLO deletes an SLO, or returns ErrNotFound
+func (r *Repository) DeleteSLO(ctx context.Context, projectID, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM slos WHERE project_id = $1 AND id = $2`, projectID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete SLO: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
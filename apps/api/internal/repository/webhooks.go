@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+// webhookColumns lists the columns scanned by scanWebhook
+const webhookColumns = `id, project_id, name, url, events, secret, enabled, created_at`
+
+// scanWebhook reads a row selected with webhookColumns
+func scanWebhook(row pgx.Row) (*models.Webhook, error) {
+	var w models.Webhook
+	if err := row.Scan(&w.ID, &w.ProjectID, &w.Name, &w.URL, &w.Events, &w.Secret, &w.Enabled, &w.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// CreateWebhook stores a new webhook
+func (r *Repository) CreateWebhook(ctx context.Context, webhook models.Webhook) error {
+	events := webhook.Events
+	if events == nil {
+		events = []string{}
+	}
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO webhooks (id, project_id, name, url, events, secret, enabled, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, webhook.ID, webhook.ProjectID, webhook.Name, webhook.URL, events, webhook.Secret, webhook.Enabled, webhook.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return nil
+}
+
+// ListWebhooks returns a project's webhooks, oldest first
+func (r *Repository) ListWebhooks(ctx context.Context, projectID uuid.UUID) ([]models.Webhook, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE project_id = $1 ORDER BY created_at ASC`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, *w)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// GetWebhook returns one of a project's webhooks, or ErrNotFound
+func (r *Repository) GetWebhook(ctx context.Context, projectID, id uuid.UUID) (*models.Webhook, error) {
+	w, err := scanWebhook(r.db.QueryRow(ctx,
+		`SELECT `+webhookColumns+` FROM webhooks WHERE project_id = $1 AND id = $2`, projectID, id,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return w, err
+}
+
+// DeleteWebhook deletes a webhook with its delivery log, or returns
+// ErrNotFound
+func (r *Repository) DeleteWebhook(ctx context.Context, projectID, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM webhooks WHERE project_id = $1 AND id = $2`, projectID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// RecordWebhookDelivery stores a delivery attempt
+func (r *Repository) RecordWebhookDelivery(ctx context.Context, d models.WebhookDelivery) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO webhook_deliveries (id, webhook_id, project_id, delivery_id, event, attempt, status_code, error, success, duration_ms, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10, $11)
+	`, d.ID, d.WebhookID, d.ProjectID, d.DeliveryID, d.Event, d.Attempt, d.StatusCode, d.Error, d.Success, d.DurationMs, d.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// ListWebhookDeliveries returns a webhook's most recent delivery attempts,
+// newest first
+func (r *Repository) ListWebhookDeliveries(ctx context.Context, projectID, webhookID uuid.UUID, limit int) ([]models.WebhookDelivery, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, webhook_id, project_id, delivery_id, event, attempt, status_code, COALESCE(error, ''), success, duration_ms, created_at
+		FROM webhook_deliveries
+		WHERE project_id = $1 AND webhook_id = $2
+		ORDER BY created_at DESC
+		LIMIT $3
+	`, projectID, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		err := rows.Scan(&d.ID, &d.WebhookID, &d.ProjectID, &d.DeliveryID, &d.Event, &d.Attempt,
+			&d.StatusCode, &d.Error, &d.Success, &d.DurationMs, &d.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// RecordErrorType stores an error type unless the project already has it,
+// reporting whether it is new
+func (r *Repository) RecordErrorType(ctx context.Context, e models.ErrorType) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		INSERT INTO error_types (project_id, tool_name, fingerprint, message, first_seen_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (project_id, tool_name, fingerprint) DO NOTHING
+	`, e.ProjectID, e.ToolName, e.Fingerprint, e.Message, e.FirstSeenAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to record error type: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
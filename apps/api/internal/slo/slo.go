@@ -0,0 +1,277 @@
+// Package slo computes the attainment, error budget and burn rates of SLOs
+// from the store's tool call counts.
+package slo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+// Store provides the call counts SLOs are computed from
+type Store interface {
+	GetToolCallsMetrics(ctx context.Context, q models.MetricsQuery) ([]models.ToolCallDataPoint, error)
+	GetWindowCounts(ctx context.Context, q models.MetricsQuery, starts []time.Time, fastMs int) ([]models.WindowCounts, error)
+}
+
+// burnAlert is a multi-window burn rate condition, firing when the long
+// window spends budgetSpent of the error budget and the short window still
+// burns as fast (thresholds from the Google SRE workbook)
+type burnAlert struct {
+	severity    string
+	long, short time.Duration
+	budgetSpent float64
+}
+
+var burnAlerts = []burnAlert{
+	{severity: "page", long: time.Hour, short: 5 * time.Minute, budgetSpent: 0.02},
+	{severity: "page", long: 6 * time.Hour, short: 30 * time.Minute, budgetSpent: 0.05},
+	{severity: "ticket", long: 24 * time.Hour, short: 2 * time.Hour, budgetSpent: 0.10},
+	{severity: "ticket", long: 72 * time.Hour, short: 6 * time.Hour, budgetSpent: 0.10},
+}
+
+// threshold returns the burn rate at which the alert's long window spends
+// its share of the budget of an SLO's window
+func (a burnAlert) threshold(s models.SLO) float64 {
+	return math.Round(a.budgetSpent*float64(s.Window())/float64(a.long)*100) / 100
+}
+
+// Counts is a number of good calls out of a total
+type Counts struct {
+	Good  int64
+	Total int64
+}
+
+// BurnRate returns how fast calls spend an SLO's error budget: the rate of
+// bad calls over the rate the budget allows
+func (c Counts) BurnRate(s models.SLO) float64 {
+	if c.Total == 0 {
+		return 0
+	}
+	return float64(c.Total-c.Good) / float64(c.Total) / s.ErrorBudget()
+}
+
+// sli returns the percentage of good calls, nil without calls
+func (c Counts) sli() *float64 {
+	if c.Total == 0 {
+		return nil
+	}
+	sli := float64(c.Good) / float64(c.Total) * 100
+	return &sli
+}
+
+// Status computes an SLO's attainment over its window ending at now, its
+// burn rates over the trailing windows of the burn alerts that fit in the
+// SLO's window, and which of those alerts fire. All windows are counted in
+// one store query.
+func Status(ctx context.Context, store Store, s models.SLO, now time.Time) (*models.SLOStatus, error) {
+	// Windows used by an alert, shortest first
+	var windows []time.Duration
+	for _, a := range burnAlerts {
+		if a.long > s.Window() {
+			continue
+		}
+		for _, w := range []time.Duration{a.long, a.short} {
+			if !slices.Contains(windows, w) {
+				windows = append(windows, w)
+			}
+		}
+	}
+	slices.Sort(windows)
+
+	counts, err := Count(ctx, store, s, now, append([]time.Duration{s.Window()}, windows...))
+	if err != nil {
+		return nil, err
+	}
+
+	from := now.Add(-s.Window())
+	total := counts[0]
+
+	status := &models.SLOStatus{
+		From:             from.UTC(),
+		To:               now.UTC(),
+		GoodCalls:        total.Good,
+		TotalCalls:       total.Total,
+		SLI:              total.sli(),
+		ErrorBudgetCalls: s.ErrorBudget() * float64(total.Total),
+		BurnRates:        []models.SLOBurnRate{},
+		BurnAlerts:       []models.SLOBurnAlert{},
+	}
+	status.Met = status.SLI == nil || *status.SLI >= s.Objective
+	status.BudgetConsumed, status.BudgetRemaining = consumed(s, total.Total-total.Good, total.Total)
+
+	rates := make(map[time.Duration]float64, len(windows))
+	for i, w := range windows {
+		c := counts[i+1]
+		rates[w] = c.BurnRate(s)
+		status.BurnRates = append(status.BurnRates, models.SLOBurnRate{
+			Window:     formatWindow(w),
+			GoodCalls:  c.Good,
+			TotalCalls: c.Total,
+			BurnRate:   rates[w],
+		})
+	}
+
+	for _, a := range burnAlerts {
+		if a.long > s.Window() {
+			continue
+		}
+		threshold := a.threshold(s)
+		status.BurnAlerts = append(status.BurnAlerts, models.SLOBurnAlert{
+			Severity:    a.severity,
+			LongWindow:  formatWindow(a.long),
+			ShortWindow: formatWindow(a.short),
+			Threshold:   threshold,
+			Firing:      rates[a.long] > threshold && rates[a.short] > threshold,
+		})
+	}
+
+	return status, nil
+}
+
+// Series computes an SLO's budget consumption per bucket of q, which sets
+// the range, interval and timezone. Consumption accumulates from the start
+// of the range and is relative to the budget of the whole range.
+func Series(ctx context.Context, store Store, s models.SLO, q models.MetricsQuery) ([]models.SLOBudgetPoint, error) {
+	buckets, counts, err := bucketCounts(ctx, store, s, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var total Counts
+	for _, c := range counts {
+		total.Good += c.Good
+		total.Total += c.Total
+	}
+
+	points := make([]models.SLOBudgetPoint, 0, len(buckets))
+	var bad int64
+	for i, bucket := range buckets {
+		c := counts[i]
+		bad += c.Total - c.Good
+
+		p := models.SLOBudgetPoint{
+			Bucket:     bucket,
+			GoodCalls:  c.Good,
+			TotalCalls: c.Total,
+			SLI:        c.sli(),
+			BurnRate:   c.BurnRate(s),
+		}
+		p.BudgetConsumed, p.BudgetRemaining = consumed(s, bad, total.Total)
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+// Count returns an SLO's good and total calls over each of the trailing
+// windows ending at now
+func Count(ctx context.Context, store Store, s models.SLO, now time.Time, windows []time.Duration) ([]Counts, error) {
+	starts := make([]time.Time, len(windows))
+	for i, w := range windows {
+		starts[i] = now.Add(-w)
+	}
+
+	fastMs := 0
+	if s.Type == models.SLOTypeLatency {
+		fastMs = s.LatencyThresholdMs
+	}
+	q := models.MetricsQuery{ProjectID: s.ProjectID, To: now, Filter: s.Filter.MetricsFilter()}
+	windowCounts, err := store.GetWindowCounts(ctx, q, starts, fastMs)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]Counts, len(windowCounts))
+	for i, c := range windowCounts {
+		switch s.Type {
+		case models.SLOTypeAvailability:
+			counts[i] = Counts{Good: c.Success, Total: c.Success + c.Failures}
+		case models.SLOTypeLatency:
+			counts[i] = Counts{Good: c.FastSuccess, Total: c.Success}
+		default:
+			return nil, fmt.Errorf("unknown SLO type %q", s.Type)
+		}
+	}
+	return counts, nil
+}
+
+// bucketCounts returns an SLO's good and total calls per bucket of q, in
+// bucket order
+func bucketCounts(ctx context.Context, store Store, s models.SLO, q models.MetricsQuery) ([]time.Time, []Counts, error) {
+	q.ProjectID = s.ProjectID
+	q.Filter = s.Filter.MetricsFilter()
+	q.GroupBy = ""
+
+	switch s.Type {
+	case models.SLOTypeAvailability:
+		points, err := store.GetToolCallsMetrics(ctx, q)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		buckets := make([]time.Time, len(points))
+		counts := make([]Counts, len(points))
+		for i, p := range points {
+			buckets[i] = p.Bucket
+			counts[i] = Counts{Good: int64(p.Success), Total: int64(p.Success + p.Failures)}
+		}
+		return buckets, counts, nil
+
+	case models.SLOTypeLatency:
+		// Successful calls, then those of them within the threshold
+		q.Filter.Status = "success"
+		points, err := store.GetToolCallsMetrics(ctx, q)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		threshold := s.LatencyThresholdMs
+		fastQuery := q
+		fastQuery.Filter.MaxDurationMs = &threshold
+		fastPoints, err := store.GetToolCallsMetrics(ctx, fastQuery)
+		if err != nil {
+			return nil, nil, err
+		}
+		fast := make(map[int64]int, len(fastPoints))
+		for _, p := range fastPoints {
+			fast[p.Bucket.UnixNano()] = p.Success
+		}
+
+		buckets := make([]time.Time, len(points))
+		counts := make([]Counts, len(points))
+		for i, p := range points {
+			buckets[i] = p.Bucket
+			counts[i] = Counts{Good: int64(fast[p.Bucket.UnixNano()]), Total: int64(p.Success)}
+		}
+		return buckets, counts, nil
+	}
+
+	return nil, nil, fmt.Errorf("unknown SLO type %q", s.Type)
+}
+
+// consumed returns the percentages of an SLO's error budget spent and left
+// by bad calls out of total
+func consumed(s models.SLO, bad, total int64) (float64, float64) {
+	allowed := s.ErrorBudget() * float64(total)
+	if allowed == 0 {
+		return 0, 100
+	}
+	spent := float64(bad) / allowed * 100
+	return spent, 100 - spent
+}
+
+// formatWindow renders a window as minutes, hours or days, e.g. "30m"
+func formatWindow(w time.Duration) string {
+	switch {
+	case w%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", w/(24*time.Hour))
+	case w%time.Hour == 0:
+		return fmt.Sprintf("%dh", w/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", w/time.Minute)
+	}
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"context"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+// BuildCostReport assembles the cost report for the query. totals
+// aggregates the cost per value of the query's group_by dimension (a
+// single "" group when ungrouped); series returns the total cost per
+// bucket.
+func BuildCostReport(
+	ctx context.Context,
+	q models.MetricsQuery,
+	totals func(context.Context, models.MetricsQuery) ([]models.CostGroup, error),
+	series func(context.Context, models.MetricsQuery) ([]models.CostDataPoint, error),
+) (*models.CostReport, error) {
+	report := &models.CostReport{From: q.From, To: q.To}
+
+	ungrouped := q
+	ungrouped.GroupBy = ""
+	overall, err := totals(ctx, ungrouped)
+	if err != nil {
+		return nil, err
+	}
+	if len(overall) > 0 {
+		report.CostTotals = overall[0].CostTotals
+	}
+
+	for _, breakdown := range []struct {
+		groupBy string
+		groups  *[]models.CostGroup
+	}{
+		{models.GroupByToolName, &report.ByTool},
+		{models.GroupByModel, &report.ByModel},
+		{q.GroupBy, &report.Groups},
+	} {
+		if breakdown.groupBy == "" {
+			continue
+		}
+		grouped := q
+		grouped.GroupBy = breakdown.groupBy
+		groups, err := totals(ctx, grouped)
+		if err != nil {
+			return nil, err
+		}
+		models.SortCostGroups(groups)
+		*breakdown.groups = groups
+	}
+
+	report.Series, err = series(ctx, ungrouped)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// CostTotals aggregates the cost of calls per group
+func CostTotals(q models.MetricsQuery, calls []models.ToolCall) []models.CostGroup {
+	index := make(map[string]int)
+	groups := []models.CostGroup{}
+	for _, call := range calls {
+		key := GroupKey(q, call)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, models.CostGroup{Group: key})
+		}
+
+		g := &groups[i]
+		g.Calls++
+		if call.Model != nil && call.CostUSD == nil {
+			g.UnpricedCalls++
+		}
+		g.InputTokens += int64(call.InputTokens)
+		g.OutputTokens += int64(call.OutputTokens)
+		g.CachedTokens += int64(call.CachedTokens)
+		if call.CostUSD != nil {
+			g.CostUSD += *call.CostUSD
+		}
+	}
+
+	models.SortCostGroups(groups)
+	return groups
+}
+
+// CostSeries returns the total cost per bucket
+func CostSeries(q models.MetricsQuery, calls []models.ToolCall) []models.CostDataPoint {
+	q.GroupBy = ""
+
+	var points []models.CostDataPoint
+	for _, b := range bucketCalls(q, calls) {
+		dp := models.CostDataPoint{Bucket: b.bucket, Hour: q.BucketLabel(b.bucket)}
+		for _, call := range b.calls {
+			if call.CostUSD != nil {
+				dp.CostUSD += *call.CostUSD
+			}
+		}
+		points = append(points, dp)
+	}
+	return points
+}
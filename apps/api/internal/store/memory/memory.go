@@ -0,0 +1,923 @@
+// Package memory is an in-memory store for local development and tests.
+// Nothing survives a restart.
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+)
+
+var _ store.Store = (*Store)(nil)
+
+// Store keeps tool calls, projects and API keys in memory
+type Store struct {
+	mu sync.RWMutex
+
+	// Tool calls per project, in ingestion order
+	calls map[uuid.UUID][]models.ToolCall
+
+	projects map[uuid.UUID]models.Project
+	keys     map[uuid.UUID]*apiKeyRecord
+
+	// Claimed event IDs
+	eventIDs map[eventKey]claim
+
+	// How long a claimed event ID blocks duplicates
+	dedupWindow time.Duration
+
+	// Retention policies; only raw retention is supported
+	policies models.DataPolicies
+
+	// Model price catalog
+	prices map[uuid.UUID]models.ModelPrice
+
+	budgets map[uuid.UUID]models.Budget
+
+	alertRules  map[uuid.UUID]models.AlertRule
+	alertEvents []models.AlertEvent // oldest first
+
+	webhooks   map[uuid.UUID]models.Webhook
+	deliveries []models.WebhookDelivery // oldest first
+	errorTypes map[errorTypeKey]models.ErrorType
+
+	slos map[uuid.UUID]models.SLO
+}
+
+// errorTypeKey identifies an error type within a project
+type errorTypeKey struct {
+	project     uuid.UUID
+	toolName    string
+	fingerprint string
+}
+
+// apiKeyRecord is a stored API key with the hash of its secret
+type apiKeyRecord struct {
+	key  models.APIKey
+	hash string
+}
+
+// eventKey identifies a claimed event ID; event IDs are scoped per project
+type eventKey struct {
+	project uuid.UUID
+	eventID string
+}
+
+// claim is the originally ingested record for an event ID
+type claim struct {
+	record     models.ToolCall
+	receivedAt time.Time
+}
+
+// New creates an empty store holding only the default project
+func New() *Store {
+	return &Store{
+		calls: make(map[uuid.UUID][]models.ToolCall),
+		projects: map[uuid.UUID]models.Project{
+			models.DefaultProjectID: {ID: models.DefaultProjectID, Name: "Default", CreatedAt: time.Now().UTC()},
+		},
+		keys:        make(map[uuid.UUID]*apiKeyRecord),
+		eventIDs:    make(map[eventKey]claim),
+		dedupWindow: store.DefaultDedupWindow,
+		prices:      make(map[uuid.UUID]models.ModelPrice),
+		budgets:     make(map[uuid.UUID]models.Budget),
+		alertRules:  make(map[uuid.UUID]models.AlertRule),
+		webhooks:    make(map[uuid.UUID]models.Webhook),
+		errorTypes:  make(map[errorTypeKey]models.ErrorType),
+		slos:        make(map[uuid.UUID]models.SLO),
+	}
+}
+
+// SetDedupWindow configures how long client event IDs are deduplicated
+func (s *Store) SetDedupWindow(window time.Duration) {
+	if window > 0 {
+		s.mu.Lock()
+		s.dedupWindow = window
+		s.mu.Unlock()
+	}
+}
+
+// Ping always succeeds
+func (s *Store) Ping(ctx context.Context) error {
+	return nil
+}
+
+// IngestToolCall stores a tool call
+func (s *Store) IngestToolCall(ctx context.Context, call models.ToolCall) error {
+	return s.IngestToolCalls(ctx, []models.ToolCall{call})
+}
+
+// IngestToolCalls stores a batch of tool calls
+func (s *Store) IngestToolCalls(ctx context.Context, calls []models.ToolCall) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, call := range calls {
+		s.calls[call.ProjectID] = append(s.calls[call.ProjectID], call)
+	}
+	return nil
+}
+
+// ClaimEventIDs records the event IDs of a project's calls that carry one.
+// The result is aligned with calls: nil for new calls, otherwise the
+// originally ingested record. An event ID repeated within calls is a
+// duplicate of its first occurrence, so of the original record when the
+// first occurrence is a replay.
+func (s *Store) ClaimEventIDs(ctx context.Context, projectID uuid.UUID, calls []models.ToolCall) ([]*models.ToolCall, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	originals := make([]*models.ToolCall, len(calls))
+	first := make(map[string]int)
+	for i, call := range calls {
+		if call.EventID == nil {
+			continue
+		}
+		if j, ok := first[*call.EventID]; ok {
+			// The first occurrence is resolved: a replay or new
+			if originals[j] != nil {
+				originals[i] = originals[j]
+			} else {
+				originals[i] = &calls[j]
+			}
+			continue
+		}
+		first[*call.EventID] = i
+
+		key := eventKey{project: projectID, eventID: *call.EventID}
+		if existing, ok := s.eventIDs[key]; ok && now.Sub(existing.receivedAt) < s.dedupWindow {
+			record := existing.record
+			originals[i] = &record
+			continue
+		}
+		s.eventIDs[key] = claim{record: call, receivedAt: now}
+	}
+
+	return originals, nil
+}
+
+// ReleaseEventIDs forgets a project's claimed event IDs
+func (s *Store) ReleaseEventIDs(ctx context.Context, projectID uuid.UUID, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		delete(s.eventIDs, eventKey{project: projectID, eventID: id})
+	}
+	return nil
+}
+
+// PruneEventIDs deletes event IDs older than the dedup window
+func (s *Store) PruneEventIDs(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pruned int64
+	for key, c := range s.eventIDs {
+		if time.Since(c.receivedAt) >= s.dedupWindow {
+			delete(s.eventIDs, key)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+// projectCalls returns a project's tool calls. The slice must not be
+// modified; s.mu must be held.
+func (s *Store) projectCalls(projectID uuid.UUID) []models.ToolCall {
+	return s.calls[projectID]
+}
+
+// matching returns the tool calls matching a metrics query; s.mu must be
+// held
+func (s *Store) matching(q models.MetricsQuery) []models.ToolCall {
+	return store.Filter(q, s.projectCalls(q.ProjectID))
+}
+
+// GetMetricsOverview returns aggregated overview metrics for the query's
+// range, compared against the same-length window compareOffset earlier
+func (s *Store) GetMetricsOverview(ctx context.Context, q models.MetricsQuery, compareOffset time.Duration) (*models.MetricsOverview, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	calls := s.projectCalls(q.ProjectID)
+	return store.BuildOverview(ctx, q, compareOffset,
+		func(ctx context.Context, q models.MetricsQuery) (*models.MetricsTotals, error) {
+			totals := store.Totals(store.Filter(q, calls))
+			return &totals, nil
+		},
+		func(ctx context.Context, q models.MetricsQuery) ([]models.GroupTotals, error) {
+			return store.GroupTotals(q, store.Filter(q, calls)), nil
+		},
+	)
+}
+
+// GetMetricsTotals aggregates the overview metrics for the query's range
+// and filter, without the overview's comparison
+func (s *Store) GetMetricsTotals(ctx context.Context, q models.MetricsQuery) (*models.MetricsTotals, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	totals := store.Totals(s.matching(q))
+	return &totals, nil
+}
+
+// GetToolCallsMetrics returns success and failure counts per time bucket
+func (s *Store) GetToolCallsMetrics(ctx context.Context, q models.MetricsQuery) ([]models.ToolCallDataPoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return store.ToolCallsSeries(q, s.matching(q)), nil
+}
+
+// GetLatencyMetrics returns latency percentiles per tool
+func (s *Store) GetLatencyMetrics(ctx context.Context, q models.MetricsQuery) ([]models.LatencyDataPoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return store.LatencyPercentiles(q, s.matching(q)), nil
+}
+
+// GetLatencyTotals returns latency percentiles across all matching calls,
+// or nil when no call matched
+func (s *Store) GetLatencyTotals(ctx context.Context, q models.MetricsQuery) (*models.LatencyDataPoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return store.LatencyTotals(q, s.matching(q)), nil
+}
+
+// GetWindowCounts counts the matching calls of trailing windows ending at
+// q.To, one result per start
+func (s *Store) GetWindowCounts(ctx context.Context, q models.MetricsQuery, starts []time.Time, fastMs int) ([]models.WindowCounts, error) {
+	if len(starts) == 0 {
+		return nil, nil
+	}
+	q.From = slices.MinFunc(starts, time.Time.Compare)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return store.WindowCounts(q, s.matching(q), starts, fastMs), nil
+}
+
+// GetTokenUsageMetrics returns token usage per time bucket
+func (s *Store) GetTokenUsageMetrics(ctx context.Context, q models.MetricsQuery) ([]models.TokenUsageDataPoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return store.TokenUsageSeries(q, s.matching(q)), nil
+}
+
+// GetFailureRateMetrics returns the failure percentage per time bucket
+func (s *Store) GetFailureRateMetrics(ctx context.Context, q models.MetricsQuery) ([]models.FailureRateDataPoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return store.FailureRateSeries(q, s.matching(q)), nil
+}
+
+// GetCostMetrics returns the cost of tool calls by tool, model and time
+// bucket
+func (s *Store) GetCostMetrics(ctx context.Context, q models.MetricsQuery) (*models.CostReport, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return store.BuildCostReport(ctx, q,
+		func(ctx context.Context, q models.MetricsQuery) ([]models.CostGroup, error) {
+			return store.CostTotals(q, s.matching(q)), nil
+		},
+		func(ctx context.Context, q models.MetricsQuery) ([]models.CostDataPoint, error) {
+			return store.CostSeries(q, s.matching(q)), nil
+		},
+	)
+}
+
+// GetCostTotals aggregates tokens and cost per group_by value
+func (s *Store) GetCostTotals(ctx context.Context, q models.MetricsQuery) ([]models.CostGroup, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return store.CostTotals(q, s.matching(q)), nil
+}
+
+// GetRecentToolCalls returns the most recent tool calls in a project
+func (s *Store) GetRecentToolCalls(ctx context.Context, projectID uuid.UUID, limit int) ([]models.ToolCall, error) {
+	s.mu.RLock()
+	calls := append([]models.ToolCall(nil), s.projectCalls(projectID)...)
+	s.mu.RUnlock()
+
+	sort.SliceStable(calls, func(i, j int) bool {
+		return calls[i].CreatedAt.After(calls[j].CreatedAt)
+	})
+	if len(calls) > limit {
+		calls = calls[:limit]
+	}
+	return calls, nil
+}
+
+// GetToolCallChain returns all tool calls in a project for a request ID,
+// oldest first
+func (s *Store) GetToolCallChain(ctx context.Context, projectID, requestID uuid.UUID) ([]models.ToolCall, error) {
+	s.mu.RLock()
+	var chain []models.ToolCall
+	for _, call := range s.projectCalls(projectID) {
+		if call.RequestID == requestID {
+			chain = append(chain, call)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.SliceStable(chain, func(i, j int) bool {
+		return chain[i].CreatedAt.Before(chain[j].CreatedAt)
+	})
+	return chain, nil
+}
+
+// GetToolCallTree returns the tool calls for a request ID arranged by
+// their span parent/child relationships
+func (s *Store) GetToolCallTree(ctx context.Context, projectID, requestID uuid.UUID) (*models.ToolCallTree, error) {
+	calls, err := s.GetToolCallChain(ctx, projectID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.BuildToolCallTree(requestID, calls), nil
+}
+
+// CreateProject stores a new project
+func (s *Store) CreateProject(ctx context.Context, project models.Project) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.projects[project.ID] = project
+	return nil
+}
+
+// ListProjects returns all projects, oldest first
+func (s *Store) ListProjects(ctx context.Context) ([]models.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	projects := make([]models.Project, 0, len(s.projects))
+	for _, p := range s.projects {
+		projects = append(projects, p)
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].CreatedAt.Before(projects[j].CreatedAt)
+	})
+	return projects, nil
+}
+
+// GetProject returns a project by ID, or store.ErrNotFound
+func (s *Store) GetProject(ctx context.Context, id uuid.UUID) (*models.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, ok := s.projects[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &p, nil
+}
+
+// CreateAPIKey stores a new API key by the hash of its secret
+func (s *Store) CreateAPIKey(ctx context.Context, key *models.APIKey, keyHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key.ID] = &apiKeyRecord{key: *key, hash: keyHash}
+	return nil
+}
+
+// ListAPIKeys returns a project's API keys, newest first
+func (s *Store) ListAPIKeys(ctx context.Context, projectID uuid.UUID) ([]models.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := []models.APIKey{}
+	for _, record := range s.keys {
+		if record.key.ProjectID == projectID {
+			keys = append(keys, record.key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// LookupAPIKey returns the active API key with the given hash and records
+// that it was used, or store.ErrNotFound for unknown or revoked keys
+func (s *Store) LookupAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, record := range s.keys {
+		if record.hash == keyHash && record.key.RevokedAt == nil {
+			now := time.Now().UTC()
+			record.key.LastUsedAt = &now
+			key := record.key
+			return &key, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+// RevokeAPIKey marks a project's API key as revoked, or returns
+// store.ErrNotFound for unknown keys
+func (s *Store) RevokeAPIKey(ctx context.Context, projectID, id uuid.UUID) (*models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.keys[id]
+	if !ok || record.key.ProjectID != projectID {
+		return nil, store.ErrNotFound
+	}
+	if record.key.RevokedAt == nil {
+		now := time.Now().UTC()
+		record.key.RevokedAt = &now
+	}
+	key := record.key
+	return &key, nil
+}
+
+// GetDataPolicies returns the retention policies
+func (s *Store) GetDataPolicies(ctx context.Context) (*models.DataPolicies, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	policies := s.policies
+	return &policies, nil
+}
+
+// SetDataPolicies replaces the retention policies. Only raw retention is
+// supported.
+func (s *Store) SetDataPolicies(ctx context.Context, policies models.DataPolicies) error {
+	if err := store.RawRetentionOnly("memory", policies); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.policies = policies
+	return nil
+}
+
+// EnforceRetention deletes tool calls older than the raw retention
+func (s *Store) EnforceRetention(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.policies.RawRetentionDays == 0 {
+		return 0, nil
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -s.policies.RawRetentionDays)
+	return s.deleteCalls(func(call models.ToolCall) bool {
+		return call.CreatedAt.Before(cutoff)
+	}), nil
+}
+
+// DeleteToolCalls deletes a project's tool calls matching the filter and
+// their claimed event IDs
+func (s *Store) DeleteToolCalls(ctx context.Context, projectID uuid.UUID, filter models.DeleteFilter) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.deleteCalls(func(call models.ToolCall) bool {
+		return call.ProjectID == projectID && store.MatchesDelete(filter, call)
+	}), nil
+}
+
+// deleteCalls deletes the tool calls selected by match and their claimed
+// event IDs; s.mu must be held
+func (s *Store) deleteCalls(match func(models.ToolCall) bool) int64 {
+	var deleted int64
+	for projectID, calls := range s.calls {
+		kept := calls[:0:0]
+		for _, call := range calls {
+			if !match(call) {
+				kept = append(kept, call)
+				continue
+			}
+			deleted++
+			if call.EventID != nil {
+				delete(s.eventIDs, eventKey{project: projectID, eventID: *call.EventID})
+			}
+		}
+		s.calls[projectID] = kept
+	}
+	return deleted
+}
+
+// ListModelPrices returns all prices, by model and then effective time
+func (s *Store) ListModelPrices(ctx context.Context) ([]models.ModelPrice, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	prices := make([]models.ModelPrice, 0, len(s.prices))
+	for _, price := range s.prices {
+		prices = append(prices, price)
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		if prices[i].Model != prices[j].Model {
+			return prices[i].Model < prices[j].Model
+		}
+		return prices[i].EffectiveFrom.Before(prices[j].EffectiveFrom)
+	})
+	return prices, nil
+}
+
+// SetModelPrice stores a price, replacing the model's price with the same
+// effective time
+func (s *Store) SetModelPrice(ctx context.Context, price *models.ModelPrice) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, existing := range s.prices {
+		if existing.Model == price.Model && existing.EffectiveFrom.Equal(price.EffectiveFrom) {
+			price.ID = id
+			price.CreatedAt = existing.CreatedAt
+		}
+	}
+	s.prices[price.ID] = *price
+	return nil
+}
+
+// DeleteModelPrice deletes a price, or returns store.ErrNotFound
+func (s *Store) DeleteModelPrice(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.prices[id]; !ok {
+		return store.ErrNotFound
+	}
+	delete(s.prices, id)
+	return nil
+}
+
+// CreateBudget stores a new budget
+func (s *Store) CreateBudget(ctx context.Context, budget models.Budget) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.budgets[budget.ID] = budget
+	return nil
+}
+
+// ListBudgets returns a project's budgets, oldest first
+func (s *Store) ListBudgets(ctx context.Context, projectID uuid.UUID) ([]models.Budget, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.sortedBudgets(func(b models.Budget) bool { return b.ProjectID == projectID }), nil
+}
+
+// ListAllBudgets returns the budgets of every project, oldest first
+func (s *Store) ListAllBudgets(ctx context.Context) ([]models.Budget, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.sortedBudgets(func(models.Budget) bool { return true }), nil
+}
+
+// sortedBudgets returns the budgets selected by match, oldest first; s.mu
+// must be held
+func (s *Store) sortedBudgets(match func(models.Budget) bool) []models.Budget {
+	budgets := []models.Budget{}
+	for _, b := range s.budgets {
+		if match(b) {
+			budgets = append(budgets, b)
+		}
+	}
+	sort.Slice(budgets, func(i, j int) bool {
+		return budgets[i].CreatedAt.Before(budgets[j].CreatedAt)
+	})
+	return budgets
+}
+
+// GetBudget returns one of a project's budgets, or store.ErrNotFound
+func (s *Store) GetBudget(ctx context.Context, projectID, id uuid.UUID) (*models.Budget, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	b, ok := s.budgets[id]
+	if !ok || b.ProjectID != projectID {
+		return nil, store.ErrNotFound
+	}
+	return &b, nil
+}
+
+// DeleteBudget deletes one of a project's budgets, or returns
+// store.ErrNotFound
+func (s *Store) DeleteBudget(ctx context.Context, projectID, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.budgets[id]
+	if !ok || b.ProjectID != projectID {
+		return store.ErrNotFound
+	}
+	delete(s.budgets, id)
+	return nil
+}
+
+// CreateAlertRule stores a new alert rule
+func (s *Store) CreateAlertRule(ctx context.Context, rule models.AlertRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.alertRules[rule.ID] = rule
+	return nil
+}
+
+// ListAlertRules returns a project's alert rules, oldest first
+func (s *Store) ListAlertRules(ctx context.Context, projectID uuid.UUID) ([]models.AlertRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.sortedAlertRules(func(r models.AlertRule) bool { return r.ProjectID == projectID }), nil
+}
+
+// ListAllAlertRules returns the alert rules of every project, oldest first
+func (s *Store) ListAllAlertRules(ctx context.Context) ([]models.AlertRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.sortedAlertRules(func(models.AlertRule) bool { return true }), nil
+}
+
+// sortedAlertRules returns the rules selected by match, oldest first; s.mu
+// must be held
+func (s *Store) sortedAlertRules(match func(models.AlertRule) bool) []models.AlertRule {
+	rules := []models.AlertRule{}
+	for _, r := range s.alertRules {
+		if match(r) {
+			rules = append(rules, r)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].CreatedAt.Before(rules[j].CreatedAt)
+	})
+	return rules
+}
+
+// GetAlertRule returns one of a project's alert rules, or store.ErrNotFound
+func (s *Store) GetAlertRule(ctx context.Context, projectID, id uuid.UUID) (*models.AlertRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rule, ok := s.alertRules[id]
+	if !ok || rule.ProjectID != projectID {
+		return nil, store.ErrNotFound
+	}
+	return &rule, nil
+}
+
+// UpdateAlertRule replaces a rule's definition, keeping its state, or
+// returns store.ErrNotFound
+func (s *Store) UpdateAlertRule(ctx context.Context, rule models.AlertRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.alertRules[rule.ID]
+	if !ok || current.ProjectID != rule.ProjectID {
+		return store.ErrNotFound
+	}
+	rule.CreatedAt = current.CreatedAt
+	rule.State, rule.StateSince = current.State, current.StateSince
+	rule.LastValue, rule.LastEvaluatedAt = current.LastValue, current.LastEvaluatedAt
+	s.alertRules[rule.ID] = rule
+	return nil
+}
+
+// DeleteAlertRule deletes a rule with its history, or returns
+// store.ErrNotFound
+func (s *Store) DeleteAlertRule(ctx context.Context, projectID, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, ok := s.alertRules[id]
+	if !ok || rule.ProjectID != projectID {
+		return store.ErrNotFound
+	}
+	delete(s.alertRules, id)
+
+	kept := s.alertEvents[:0]
+	for _, e := range s.alertEvents {
+		if e.RuleID != id {
+			kept = append(kept, e)
+		}
+	}
+	s.alertEvents = kept
+	return nil
+}
+
+// SetAlertState stores a rule's evaluation state and the event of its
+// state change, provided the stored state is still previous
+func (s *Store) SetAlertState(ctx context.Context, rule models.AlertRule, previous string, event *models.AlertEvent) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.alertRules[rule.ID]
+	if !ok || current.State != previous {
+		return false, nil
+	}
+	current.State, current.StateSince = rule.State, rule.StateSince
+	current.LastValue, current.LastEvaluatedAt = rule.LastValue, rule.LastEvaluatedAt
+	s.alertRules[rule.ID] = current
+
+	if event != nil {
+		s.alertEvents = append(s.alertEvents, *event)
+	}
+	return true, nil
+}
+
+// ListAlertEvents returns a project's most recent alert events, newest
+// first, optionally only those of one rule
+func (s *Store) ListAlertEvents(ctx context.Context, projectID uuid.UUID, ruleID *uuid.UUID, limit int) ([]models.AlertEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := []models.AlertEvent{}
+	for i := len(s.alertEvents) - 1; i >= 0 && len(events) < limit; i-- {
+		e := s.alertEvents[i]
+		if e.ProjectID == projectID && (ruleID == nil || e.RuleID == *ruleID) {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+// CreateWebhook stores a new webhook
+func (s *Store) CreateWebhook(ctx context.Context, webhook models.Webhook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if webhook.Events == nil {
+		webhook.Events = []string{}
+	}
+	s.webhooks[webhook.ID] = webhook
+	return nil
+}
+
+// ListWebhooks returns a project's webhooks, oldest first
+func (s *Store) ListWebhooks(ctx context.Context, projectID uuid.UUID) ([]models.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	webhooks := []models.Webhook{}
+	for _, w := range s.webhooks {
+		if w.ProjectID == projectID {
+			webhooks = append(webhooks, w)
+		}
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+	return webhooks, nil
+}
+
+// GetWebhook returns one of a project's webhooks, or store.ErrNotFound
+func (s *Store) GetWebhook(ctx context.Context, projectID, id uuid.UUID) (*models.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	w, ok := s.webhooks[id]
+	if !ok || w.ProjectID != projectID {
+		return nil, store.ErrNotFound
+	}
+	return &w, nil
+}
+
+// DeleteWebhook deletes a webhook with its delivery log, or returns
+// store.ErrNotFound
+func (s *Store) DeleteWebhook(ctx context.Context, projectID, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, ok := s.webhooks[id]
+	if !ok || w.ProjectID != projectID {
+		return store.ErrNotFound
+	}
+	delete(s.webhooks, id)
+
+	kept := s.deliveries[:0]
+	for _, d := range s.deliveries {
+		if d.WebhookID != id {
+			kept = append(kept, d)
+		}
+	}
+	s.deliveries = kept
+	return nil
+}
+
+// RecordWebhookDelivery stores a delivery attempt, unless the webhook was
+// deleted meanwhile
+func (s *Store) RecordWebhookDelivery(ctx context.Context, d models.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.webhooks[d.WebhookID]; ok {
+		s.deliveries = append(s.deliveries, d)
+	}
+	return nil
+}
+
+// ListWebhookDeliveries returns a webhook's most recent delivery attempts,
+// newest first
+func (s *Store) ListWebhookDeliveries(ctx context.Context, projectID, webhookID uuid.UUID, limit int) ([]models.WebhookDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deliveries := []models.WebhookDelivery{}
+	for i := len(s.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		d := s.deliveries[i]
+		if d.ProjectID == projectID && d.WebhookID == webhookID {
+			deliveries = append(deliveries, d)
+		}
+	}
+	return deliveries, nil
+}
+
+// RecordErrorType stores an error type unless the project already has it,
+// reporting whether it is new
+func (s *Store) RecordErrorType(ctx context.Context, e models.ErrorType) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := errorTypeKey{project: e.ProjectID, toolName: e.ToolName, fingerprint: e.Fingerprint}
+	if _, ok := s.errorTypes[key]; ok {
+		return false, nil
+	}
+	s.errorTypes[key] = e
+	return true, nil
+}
+
+// CreateSLO stores a new SLO
+func (s *Store) CreateSLO(ctx context.Context, slo models.SLO) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.slos[slo.ID] = slo
+	return nil
+}
+
+// ListSLOs returns a project's SLOs, oldest first
+func (s *Store) ListSLOs(ctx context.Context, projectID uuid.UUID) ([]models.SLO, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	slos := []models.SLO{}
+	for _, slo := range s.slos {
+		if slo.ProjectID == projectID {
+			slos = append(slos, slo)
+		}
+	}
+	sort.Slice(slos, func(i, j int) bool {
+		return slos[i].CreatedAt.Before(slos[j].CreatedAt)
+	})
+	return slos, nil
+}
+
+// GetSLO returns one of a project's SLOs, or store.ErrNotFound
+func (s *Store) GetSLO(ctx context.Context, projectID, id uuid.UUID) (*models.SLO, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	slo, ok := s.slos[id]
+	if !ok || slo.ProjectID != projectID {
+		return nil, store.ErrNotFound
+	}
+	return &slo, nil
+}
+
+// UpdateSLO replaces an SLO's definition, or returns store.ErrNotFound
+func (s *Store) UpdateSLO(ctx context.Context, slo models.SLO) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.slos[slo.ID]
+	if !ok || current.ProjectID != slo.ProjectID {
+		return store.ErrNotFound
+	}
+	slo.CreatedAt = current.CreatedAt
+	s.slos[slo.ID] = slo
+	return nil
+}
+
+// DeleteSLO deletes an SLO, or returns store.ErrNotFound
+func (s *Store) DeleteSLO(ctx context.Context, projectID, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	slo, ok := s.slos[id]
+	if !ok || slo.ProjectID != projectID {
+		return store.ErrNotFound
+	}
+	delete(s.slos, id)
+	return nil
+}
@@ -0,0 +1,387 @@
+package store
+
+import (
+	"encoding/json"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+// The metrics below are computed in Go over already-loaded tool calls that
+// match the query (see Filter), for backends that can't aggregate in the
+// database. They match the results of the PostgreSQL repository.
+
+// bucketOrigin aligns buckets like TimescaleDB's time_bucket (a Monday)
+var bucketOrigin = time.Date(2000, 1, 3, 0, 0, 0, 0, time.UTC)
+
+// Matches reports whether a tool call is in the query's project and range
+// and passes its filter
+func Matches(q models.MetricsQuery, call models.ToolCall) bool {
+	if call.ProjectID != q.ProjectID || call.CreatedAt.Before(q.From) || !call.CreatedAt.Before(q.To) {
+		return false
+	}
+
+	f := q.Filter
+	if len(f.ToolNames) > 0 && !slices.Contains(f.ToolNames, call.ToolName) {
+		return false
+	}
+	if len(f.ExcludeToolNames) > 0 && slices.Contains(f.ExcludeToolNames, call.ToolName) {
+		return false
+	}
+	if len(f.Models) > 0 && (call.Model == nil || !slices.Contains(f.Models, *call.Model)) {
+		return false
+	}
+	if f.Status != "" && call.Status != f.Status {
+		return false
+	}
+	if f.MinDurationMs != nil && call.DurationMs < *f.MinDurationMs {
+		return false
+	}
+	if f.MaxDurationMs != nil && call.DurationMs > *f.MaxDurationMs {
+		return false
+	}
+	for key, want := range f.Metadata {
+		if value, ok := MetadataText(call.Metadata, key); !ok || value != want {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the calls matching the query
+func Filter(q models.MetricsQuery, calls []models.ToolCall) []models.ToolCall {
+	var matching []models.ToolCall
+	for _, call := range calls {
+		if Matches(q, call) {
+			matching = append(matching, call)
+		}
+	}
+	return matching
+}
+
+// MetadataText returns a metadata value as text like PostgreSQL's ->>
+// operator: strings as is, other values JSON-encoded. ok is false when the
+// key is missing or null.
+func MetadataText(metadata map[string]interface{}, key string) (string, bool) {
+	value, ok := metadata[key]
+	if !ok || value == nil {
+		return "", false
+	}
+	if str, isString := value.(string); isString {
+		return str, true
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+	return string(encoded), true
+}
+
+// GroupKey returns the group_by value of a tool call: "" when the query
+// isn't grouped or the call lacks the grouped model or metadata key
+func GroupKey(q models.MetricsQuery, call models.ToolCall) string {
+	if q.GroupBy == models.GroupByToolName {
+		return call.ToolName
+	}
+	if q.GroupBy == models.GroupByModel {
+		if call.Model == nil {
+			return ""
+		}
+		return *call.Model
+	}
+	if key, ok := q.GroupByMetadataKey(); ok {
+		value, _ := MetadataText(call.Metadata, key)
+		return value
+	}
+	return ""
+}
+
+// BucketStart returns the start of the query bucket containing t. Buckets
+// are aligned in the query's timezone, so daily buckets start at local
+// midnight.
+func BucketStart(q models.MetricsQuery, t time.Time) time.Time {
+	interval := bucketInterval(q)
+	wall := wallClock(t, q.Location)
+	offset := wall.Sub(bucketOrigin) % interval
+	if offset < 0 {
+		offset += interval
+	}
+	return fromWallClock(wall.Add(-offset), q.Location)
+}
+
+// BucketStarts returns the starts of all buckets overlapping the query's
+// range, in order
+func BucketStarts(q models.MetricsQuery) []time.Time {
+	interval := bucketInterval(q)
+	end := wallClock(q.To, q.Location)
+
+	var starts []time.Time
+	for wall := wallClock(BucketStart(q, q.From), q.Location); wall.Before(end); wall = wall.Add(interval) {
+		starts = append(starts, fromWallClock(wall, q.Location))
+	}
+	return starts
+}
+
+// bucketInterval returns the query's bucket width, defaulting to an hour
+func bucketInterval(q models.MetricsQuery) time.Duration {
+	if q.Interval <= 0 {
+		return time.Hour
+	}
+	return q.Interval
+}
+
+// wallClock returns t's local time in loc as a UTC time with the same
+// clock reading, so buckets can be computed without DST jumps
+func wallClock(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	l := t.In(loc)
+	return time.Date(l.Year(), l.Month(), l.Day(), l.Hour(), l.Minute(), l.Second(), l.Nanosecond(), time.UTC)
+}
+
+// fromWallClock converts a wallClock reading back to a time in loc
+func fromWallClock(wall time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), loc)
+}
+
+// bucketed is a group's calls in one bucket
+type bucketed struct {
+	group  string
+	bucket time.Time
+	calls  []models.ToolCall
+}
+
+// bucketCalls splits calls into the query's buckets per group,
+// including empty buckets, ordered by group and then bucket. Ungrouped
+// queries always return every bucket; grouped queries return the groups
+// that have calls.
+func bucketCalls(q models.MetricsQuery, calls []models.ToolCall) []bucketed {
+	starts := BucketStarts(q)
+	index := make(map[int64]int, len(starts))
+	for i, start := range starts {
+		index[start.UnixNano()] = i
+	}
+
+	byGroup := make(map[string][][]models.ToolCall)
+	if q.GroupBy == "" {
+		byGroup[""] = make([][]models.ToolCall, len(starts))
+	}
+	for _, call := range calls {
+		i, ok := index[BucketStart(q, call.CreatedAt).UnixNano()]
+		if !ok {
+			continue
+		}
+		group := GroupKey(q, call)
+		buckets, ok := byGroup[group]
+		if !ok {
+			buckets = make([][]models.ToolCall, len(starts))
+			byGroup[group] = buckets
+		}
+		buckets[i] = append(buckets[i], call)
+	}
+
+	groups := make([]string, 0, len(byGroup))
+	for group := range byGroup {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	rows := make([]bucketed, 0, len(groups)*len(starts))
+	for _, group := range groups {
+		for i, start := range starts {
+			rows = append(rows, bucketed{group: group, bucket: q.InZone(start), calls: byGroup[group][i]})
+		}
+	}
+	return rows
+}
+
+// ToolCallsSeries returns success and failure counts per bucket
+func ToolCallsSeries(q models.MetricsQuery, calls []models.ToolCall) []models.ToolCallDataPoint {
+	var points []models.ToolCallDataPoint
+	for _, b := range bucketCalls(q, calls) {
+		dp := models.ToolCallDataPoint{Bucket: b.bucket, Hour: q.BucketLabel(b.bucket), Group: b.group}
+		for _, call := range b.calls {
+			switch call.Status {
+			case "success":
+				dp.Success++
+			case "failed":
+				dp.Failures++
+			}
+		}
+		points = append(points, dp)
+	}
+	return points
+}
+
+// TokenUsageSeries returns input and output tokens per bucket
+func TokenUsageSeries(q models.MetricsQuery, calls []models.ToolCall) []models.TokenUsageDataPoint {
+	var points []models.TokenUsageDataPoint
+	for _, b := range bucketCalls(q, calls) {
+		dp := models.TokenUsageDataPoint{Bucket: b.bucket, Hour: q.BucketLabel(b.bucket), Group: b.group}
+		for _, call := range b.calls {
+			dp.Input += call.InputTokens
+			dp.Output += call.OutputTokens
+		}
+		points = append(points, dp)
+	}
+	return points
+}
+
+// FailureRateSeries returns the failure percentage per bucket
+func FailureRateSeries(q models.MetricsQuery, calls []models.ToolCall) []models.FailureRateDataPoint {
+	var points []models.FailureRateDataPoint
+	for _, b := range bucketCalls(q, calls) {
+		dp := models.FailureRateDataPoint{Bucket: b.bucket, Hour: q.BucketLabel(b.bucket), Group: b.group}
+		dp.FailurePercent = Totals(b.calls).FailureRate
+		points = append(points, dp)
+	}
+	return points
+}
+
+// LatencyPercentiles returns latency percentiles per group and tool. Unless
+// the query filters on status, only successful calls are measured.
+func LatencyPercentiles(q models.MetricsQuery, calls []models.ToolCall) []models.LatencyDataPoint {
+	type key struct{ group, tool string }
+	durations := make(map[key][]float64)
+	for _, call := range calls {
+		if q.Filter.Status == "" && call.Status != "success" {
+			continue
+		}
+		k := key{GroupKey(q, call), call.ToolName}
+		durations[k] = append(durations[k], float64(call.DurationMs))
+	}
+
+	keys := make([]key, 0, len(durations))
+	for k := range durations {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].group != keys[j].group {
+			return keys[i].group < keys[j].group
+		}
+		return keys[i].tool < keys[j].tool
+	})
+
+	points := make([]models.LatencyDataPoint, 0, len(keys))
+	for _, k := range keys {
+		values := durations[k]
+		sort.Float64s(values)
+		points = append(points, models.LatencyDataPoint{
+			Group: k.group,
+			Tool:  k.tool,
+			P50:   Percentile(values, 0.5),
+			P95:   Percentile(values, 0.95),
+			P99:   Percentile(values, 0.99),
+		})
+	}
+	return points
+}
+
+// LatencyTotals returns latency percentiles across all calls, like
+// LatencyPercentiles without splitting by group and tool, or nil when no
+// call is measured
+func LatencyTotals(q models.MetricsQuery, calls []models.ToolCall) *models.LatencyDataPoint {
+	var values []float64
+	for _, call := range calls {
+		if q.Filter.Status == "" && call.Status != "success" {
+			continue
+		}
+		values = append(values, float64(call.DurationMs))
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	sort.Float64s(values)
+	return &models.LatencyDataPoint{
+		P50: Percentile(values, 0.5),
+		P95: Percentile(values, 0.95),
+		P99: Percentile(values, 0.99),
+	}
+}
+
+// WindowCounts counts calls per trailing window ending at q.To, one result
+// per start
+func WindowCounts(q models.MetricsQuery, calls []models.ToolCall, starts []time.Time, fastMs int) []models.WindowCounts {
+	counts := make([]models.WindowCounts, len(starts))
+	for _, call := range calls {
+		for i, start := range starts {
+			if call.CreatedAt.Before(start) {
+				continue
+			}
+			switch call.Status {
+			case "success":
+				counts[i].Success++
+				if fastMs > 0 && call.DurationMs <= fastMs {
+					counts[i].FastSuccess++
+				}
+			case "failed":
+				counts[i].Failures++
+			}
+		}
+	}
+	return counts
+}
+
+// Percentile returns the p-th percentile of sorted values, interpolating
+// between the closest ranks like PostgreSQL's percentile_cont
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := p * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(lower)
+	return sorted[lower] + (sorted[lower+1]-sorted[lower])*frac
+}
+
+// Totals aggregates the overview metrics of calls
+func Totals(calls []models.ToolCall) models.MetricsTotals {
+	var totals models.MetricsTotals
+	var durations, failures int64
+	for _, call := range calls {
+		totals.TotalCalls++
+		totals.TotalTokens += int64(call.InputTokens + call.OutputTokens)
+		durations += int64(call.DurationMs)
+		if call.Status == "failed" {
+			failures++
+		}
+	}
+	if totals.TotalCalls > 0 {
+		totals.AvgLatencyMs = float64(durations) / float64(totals.TotalCalls)
+		totals.FailureRate = float64(failures) / float64(totals.TotalCalls) * 100
+	}
+	return totals
+}
+
+// GroupTotals aggregates the overview metrics of calls per group, busiest
+// groups first
+func GroupTotals(q models.MetricsQuery, calls []models.ToolCall) []models.GroupTotals {
+	byGroup := make(map[string][]models.ToolCall)
+	for _, call := range calls {
+		group := GroupKey(q, call)
+		byGroup[group] = append(byGroup[group], call)
+	}
+
+	groups := make([]models.GroupTotals, 0, len(byGroup))
+	for group, calls := range byGroup {
+		groups = append(groups, models.GroupTotals{Group: group, MetricsTotals: Totals(calls)})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].TotalCalls != groups[j].TotalCalls {
+			return groups[i].TotalCalls > groups[j].TotalCalls
+		}
+		return groups[i].Group < groups[j].Group
+	})
+	return groups
+}
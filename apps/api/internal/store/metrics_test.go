@@ -0,0 +1,100 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+func TestBucketStarts(t *testing.T) {
+	utc := func(hour, min, sec int) time.Time {
+		return time.Date(2026, 3, 2, hour, min, sec, 0, time.UTC)
+	}
+	// Half-hour offset, so hourly and daily buckets aren't UTC-aligned
+	kolkata := time.FixedZone("IST", 5*3600+1800)
+
+	tests := []struct {
+		name  string
+		query models.MetricsQuery
+		want  []time.Time
+	}{
+		{
+			name:  "aligned range",
+			query: models.MetricsQuery{From: utc(10, 0, 0), To: utc(10, 15, 0), Interval: 5 * time.Minute},
+			want:  []time.Time{utc(10, 0, 0), utc(10, 5, 0), utc(10, 10, 0)},
+		},
+		{
+			name:  "misaligned from and to",
+			query: models.MetricsQuery{From: utc(10, 7, 30), To: utc(10, 16, 1), Interval: 5 * time.Minute},
+			want:  []time.Time{utc(10, 5, 0), utc(10, 10, 0), utc(10, 15, 0)},
+		},
+		{
+			name:  "range inside one bucket",
+			query: models.MetricsQuery{From: utc(10, 1, 0), To: utc(10, 2, 0), Interval: 15 * time.Minute},
+			want:  []time.Time{utc(10, 0, 0)},
+		},
+		{
+			name:  "zero interval is hourly",
+			query: models.MetricsQuery{From: utc(10, 30, 0), To: utc(12, 0, 0)},
+			want:  []time.Time{utc(10, 0, 0), utc(11, 0, 0)},
+		},
+		{
+			name:  "negative interval is hourly",
+			query: models.MetricsQuery{From: utc(10, 0, 0), To: utc(11, 0, 0), Interval: -time.Minute},
+			want:  []time.Time{utc(10, 0, 0)},
+		},
+		{
+			name: "daily buckets at local midnight",
+			query: models.MetricsQuery{
+				From:     utc(10, 0, 0),
+				To:       utc(10, 0, 0).Add(24 * time.Hour),
+				Interval: 24 * time.Hour,
+				Location: kolkata,
+			},
+			want: []time.Time{
+				time.Date(2026, 3, 2, 0, 0, 0, 0, kolkata),
+				time.Date(2026, 3, 3, 0, 0, 0, 0, kolkata),
+			},
+		},
+		{
+			name:  "empty range",
+			query: models.MetricsQuery{From: utc(10, 0, 0), To: utc(10, 0, 0), Interval: time.Minute},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BucketStarts(tt.query)
+			if len(got) != len(tt.want) {
+				t.Fatalf("BucketStarts = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("BucketStarts[%d] = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBucketStart(t *testing.T) {
+	q := models.MetricsQuery{Interval: 15 * time.Minute}
+
+	tests := []struct {
+		at, want time.Time
+	}{
+		{time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC), time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 3, 2, 10, 14, 59, 999, time.UTC), time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 3, 2, 10, 15, 0, 0, time.UTC), time.Date(2026, 3, 2, 10, 15, 0, 0, time.UTC)},
+
+		// Before the bucket origin
+		{time.Date(1999, 12, 31, 23, 59, 0, 0, time.UTC), time.Date(1999, 12, 31, 23, 45, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		if got := BucketStart(q, tt.at); !got.Equal(tt.want) {
+			t.Errorf("BucketStart(%s) = %s, want %s", tt.at, got, tt.want)
+		}
+	}
+}
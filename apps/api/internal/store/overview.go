@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+// BuildOverview assembles the metrics overview for the query's range,
+// compared against the same-length window compareOffset earlier. A zero
+// compareOffset compares against the immediately preceding window. totals
+// aggregates a range; groups, called for grouped queries only, aggregates
+// the current range per group.
+func BuildOverview(
+	ctx context.Context,
+	q models.MetricsQuery,
+	compareOffset time.Duration,
+	totals func(context.Context, models.MetricsQuery) (*models.MetricsTotals, error),
+	groups func(context.Context, models.MetricsQuery) ([]models.GroupTotals, error),
+) (*models.MetricsOverview, error) {
+	from, to := q.From, q.To
+	if compareOffset <= 0 {
+		compareOffset = q.Duration()
+	}
+
+	current, err := totals(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	prevFrom, prevTo := from.Add(-compareOffset), to.Add(-compareOffset)
+	prevQuery := q
+	prevQuery.From, prevQuery.To = prevFrom, prevTo
+	previous, err := totals(ctx, prevQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	overview := &models.MetricsOverview{
+		MetricsTotals: *current,
+		From:          from,
+		To:            to,
+		Comparison: &models.MetricsComparison{
+			From:     prevFrom,
+			To:       prevTo,
+			Previous: *previous,
+			ChangePercent: models.MetricsChanges{
+				TotalCalls:   percentChange(float64(current.TotalCalls), float64(previous.TotalCalls)),
+				AvgLatencyMs: percentChange(current.AvgLatencyMs, previous.AvgLatencyMs),
+				TotalTokens:  percentChange(float64(current.TotalTokens), float64(previous.TotalTokens)),
+				FailureRate:  percentChange(current.FailureRate, previous.FailureRate),
+			},
+		},
+	}
+	if change := overview.Comparison.ChangePercent.TotalCalls; change != nil {
+		overview.ChangePercent = *change
+	}
+
+	if q.GroupBy != "" {
+		g, err := groups(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		overview.Groups = g
+	}
+
+	return overview, nil
+}
+
+// percentChange returns the change from previous to current in percent,
+// or nil when previous is zero and the change is undefined
+func percentChange(current, previous float64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	change := (current - previous) / previous * 100
+	return &change
+}
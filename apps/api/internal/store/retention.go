@@ -0,0 +1,38 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+// MatchesDelete reports whether a tool call is selected by a delete filter
+func MatchesDelete(f models.DeleteFilter, call models.ToolCall) bool {
+	if f.RequestID != nil && call.RequestID != *f.RequestID {
+		return false
+	}
+	if f.ToolName != "" && call.ToolName != f.ToolName {
+		return false
+	}
+	if !f.From.IsZero() && call.CreatedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !call.CreatedAt.Before(f.To) {
+		return false
+	}
+	for key, want := range f.Metadata {
+		if value, ok := MetadataText(call.Metadata, key); !ok || value != want {
+			return false
+		}
+	}
+	return true
+}
+
+// RawRetentionOnly rejects policies other than raw retention, for backends
+// without rollups or compression
+func RawRetentionOnly(backend string, p models.DataPolicies) error {
+	if p.RollupRetentionDays != 0 || p.CompressAfterDays != 0 {
+		return fmt.Errorf("%w: the %s store only supports raw_retention_days", ErrUnsupported, backend)
+	}
+	return nil
+}
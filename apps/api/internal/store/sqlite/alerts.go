@@ -0,0 +1,259 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+)
+
+// alertRuleColumns lists the columns scanned by scanAlertRule
+const alertRuleColumns = `id, project_id, name, metric, operator, threshold, window_seconds, for_seconds,
+	filter, enabled, state, state_since, last_value, last_evaluated_at, created_at`
+
+// scanAlertRule reads a row selected with alertRuleColumns
+func scanAlertRule(row interface{ Scan(...interface{}) error }) (*models.AlertRule, error) {
+	var rule models.AlertRule
+	var id, projectID, filter string
+	var lastValue sql.NullFloat64
+	var stateSince, lastEvaluatedAt sql.NullInt64
+	var createdAt int64
+	err := row.Scan(&id, &projectID, &rule.Name, &rule.Metric, &rule.Operator, &rule.Threshold,
+		&rule.WindowSeconds, &rule.ForSeconds, &filter, &rule.Enabled,
+		&rule.State, &stateSince, &lastValue, &lastEvaluatedAt, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if rule.ID, err = uuid.Parse(id); err != nil {
+		return nil, err
+	}
+	if rule.ProjectID, err = uuid.Parse(projectID); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(filter), &rule.Filter); err != nil {
+		return nil, fmt.Errorf("invalid alert rule filter: %w", err)
+	}
+	if lastValue.Valid {
+		rule.LastValue = &lastValue.Float64
+	}
+	rule.StateSince = nullMicros(stateSince)
+	rule.LastEvaluatedAt = nullMicros(lastEvaluatedAt)
+	rule.CreatedAt = fromMicros(createdAt)
+	return &rule, nil
+}
+
+// optionalMicros encodes a nullable timestamp as stored
+func optionalMicros(t *time.Time) *int64 {
+	if t == nil {
+		return nil
+	}
+	us := toMicros(*t)
+	return &us
+}
+
+// CreateAlertRule stores a new alert rule
+func (s *Store) CreateAlertRule(ctx context.Context, rule models.AlertRule) error {
+	filter, err := json.Marshal(rule.Filter)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert rule filter: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO alert_rules (id, project_id, name, metric, operator, threshold, window_seconds, for_seconds, filter, enabled, state, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, rule.ID.String(), rule.ProjectID.String(), rule.Name, rule.Metric, rule.Operator, rule.Threshold,
+		rule.WindowSeconds, rule.ForSeconds, string(filter), rule.Enabled, rule.State, toMicros(rule.CreatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create alert rule: %w", err)
+	}
+
+	return nil
+}
+
+// ListAlertRules returns a project's alert rules, oldest first
+func (s *Store) ListAlertRules(ctx context.Context, projectID uuid.UUID) ([]models.AlertRule, error) {
+	return s.queryAlertRules(ctx, `SELECT `+alertRuleColumns+` FROM alert_rules WHERE project_id = ? ORDER BY created_at ASC`, projectID.String())
+}
+
+// ListAllAlertRules returns the alert rules of every project, oldest first
+func (s *Store) ListAllAlertRules(ctx context.Context) ([]models.AlertRule, error) {
+	return s.queryAlertRules(ctx, `SELECT `+alertRuleColumns+` FROM alert_rules ORDER BY created_at ASC`)
+}
+
+// queryAlertRules runs a query selecting alertRuleColumns
+func (s *Store) queryAlertRules(ctx context.Context, query string, args ...any) ([]models.AlertRule, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []models.AlertRule{}
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// GetAlertRule returns one of a project's alert rules, or store.ErrNotFound
+func (s *Store) GetAlertRule(ctx context.Context, projectID, id uuid.UUID) (*models.AlertRule, error) {
+	rule, err := scanAlertRule(s.db.QueryRowContext(ctx,
+		`SELECT `+alertRuleColumns+` FROM alert_rules WHERE project_id = ? AND id = ?`, projectID.String(), id.String(),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrNotFound
+	}
+	return rule, err
+}
+
+// UpdateAlertRule replaces a rule's definition, keeping its state, or
+// returns store.ErrNotFound
+func (s *Store) UpdateAlertRule(ctx context.Context, rule models.AlertRule) error {
+	filter, err := json.Marshal(rule.Filter)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert rule filter: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE alert_rules
+		SET name = ?, metric = ?, operator = ?, threshold = ?, window_seconds = ?, for_seconds = ?,
+			filter = ?, enabled = ?
+		WHERE project_id = ? AND id = ?
+	`, rule.Name, rule.Metric, rule.Operator, rule.Threshold, rule.WindowSeconds, rule.ForSeconds,
+		string(filter), rule.Enabled, rule.ProjectID.String(), rule.ID.String(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update alert rule: %w", err)
+	}
+	return requireAffected(result, "update alert rule")
+}
+
+// DeleteAlertRule deletes a rule with its history, or returns
+// store.ErrNotFound
+func (s *Store) DeleteAlertRule(ctx context.Context, projectID, id uuid.UUID) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM alert_rules WHERE project_id = ? AND id = ?`, projectID.String(), id.String())
+	if err != nil {
+		return fmt.Errorf("failed to delete alert rule: %w", err)
+	}
+	return requireAffected(result, "delete alert rule")
+}
+
+// requireAffected returns store.ErrNotFound when a statement changed no rows
+func requireAffected(result sql.Result, action string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+	if affected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// SetAlertState stores a rule's evaluation state and the event of its
+// state change, provided the stored state is still previous
+func (s *Store) SetAlertState(ctx context.Context, rule models.AlertRule, previous string, event *models.AlertEvent) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to update alert state: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE alert_rules
+		SET state = ?, state_since = ?, last_value = ?, last_evaluated_at = ?
+		WHERE id = ? AND state = ?
+	`, rule.State, optionalMicros(rule.StateSince), rule.LastValue, optionalMicros(rule.LastEvaluatedAt),
+		rule.ID.String(), previous,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to update alert state: %w", err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to update alert state: %w", err)
+	}
+	if updated == 0 {
+		return false, nil
+	}
+
+	if event != nil {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO alert_events (id, rule_id, project_id, state, previous_state, value, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, event.ID.String(), event.RuleID.String(), event.ProjectID.String(), event.State, event.PreviousState,
+			event.Value, toMicros(event.CreatedAt),
+		)
+		if err != nil {
+			return false, fmt.Errorf("failed to record alert event: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to update alert state: %w", err)
+	}
+	return true, nil
+}
+
+// ListAlertEvents returns a project's most recent alert events, newest
+// first, optionally only those of one rule
+func (s *Store) ListAlertEvents(ctx context.Context, projectID uuid.UUID, ruleID *uuid.UUID, limit int) ([]models.AlertEvent, error) {
+	var rule *string
+	if ruleID != nil {
+		id := ruleID.String()
+		rule = &id
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, rule_id, project_id, state, previous_state, value, created_at
+		FROM alert_events
+		WHERE project_id = ? AND (? IS NULL OR rule_id = ?)
+		ORDER BY created_at DESC
+		LIMIT ?
+	`, projectID.String(), rule, rule, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.AlertEvent{}
+	for rows.Next() {
+		var e models.AlertEvent
+		var id, eventRuleID, eventProjectID string
+		var value sql.NullFloat64
+		var createdAt int64
+		if err := rows.Scan(&id, &eventRuleID, &eventProjectID, &e.State, &e.PreviousState, &value, &createdAt); err != nil {
+			return nil, err
+		}
+		if e.ID, err = uuid.Parse(id); err != nil {
+			return nil, err
+		}
+		if e.RuleID, err = uuid.Parse(eventRuleID); err != nil {
+			return nil, err
+		}
+		if e.ProjectID, err = uuid.Parse(eventProjectID); err != nil {
+			return nil, err
+		}
+		if value.Valid {
+			e.Value = &value.Float64
+		}
+		e.CreatedAt = fromMicros(createdAt)
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
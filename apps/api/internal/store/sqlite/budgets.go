@@ -0,0 +1,112 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+)
+
+// budgetColumns lists the columns scanned by scanBudget
+const budgetColumns = `id, project_id, name, metric, limit_value, window_hours,
+	COALESCE(tool_name, ''), COALESCE(metadata_key, ''), created_at`
+
+// scanBudget reads a row selected with budgetColumns
+func scanBudget(row interface{ Scan(...interface{}) error }) (*models.Budget, error) {
+	var b models.Budget
+	var id, projectID string
+	var createdAt int64
+	err := row.Scan(&id, &projectID, &b.Name, &b.Metric, &b.Limit, &b.WindowHours,
+		&b.ToolName, &b.MetadataKey, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.ID, err = uuid.Parse(id); err != nil {
+		return nil, err
+	}
+	if b.ProjectID, err = uuid.Parse(projectID); err != nil {
+		return nil, err
+	}
+	b.CreatedAt = fromMicros(createdAt)
+	return &b, nil
+}
+
+// CreateBudget stores a new budget
+func (s *Store) CreateBudget(ctx context.Context, budget models.Budget) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO budgets (id, project_id, name, metric, limit_value, window_hours, tool_name, metadata_key, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), ?)
+	`, budget.ID.String(), budget.ProjectID.String(), budget.Name, budget.Metric, budget.Limit, budget.WindowHours,
+		budget.ToolName, budget.MetadataKey, toMicros(budget.CreatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create budget: %w", err)
+	}
+
+	return nil
+}
+
+// ListBudgets returns a project's budgets, oldest first
+func (s *Store) ListBudgets(ctx context.Context, projectID uuid.UUID) ([]models.Budget, error) {
+	return s.queryBudgets(ctx, `SELECT `+budgetColumns+` FROM budgets WHERE project_id = ? ORDER BY created_at ASC`, projectID.String())
+}
+
+// ListAllBudgets returns the budgets of every project, oldest first
+func (s *Store) ListAllBudgets(ctx context.Context) ([]models.Budget, error) {
+	return s.queryBudgets(ctx, `SELECT `+budgetColumns+` FROM budgets ORDER BY created_at ASC`)
+}
+
+// queryBudgets runs a query selecting budgetColumns
+func (s *Store) queryBudgets(ctx context.Context, query string, args ...any) ([]models.Budget, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	budgets := []models.Budget{}
+	for rows.Next() {
+		b, err := scanBudget(rows)
+		if err != nil {
+			return nil, err
+		}
+		budgets = append(budgets, *b)
+	}
+
+	return budgets, rows.Err()
+}
+
+// GetBudget returns one of a project's budgets, or store.ErrNotFound
+func (s *Store) GetBudget(ctx context.Context, projectID, id uuid.UUID) (*models.Budget, error) {
+	b, err := scanBudget(s.db.QueryRowContext(ctx,
+		`SELECT `+budgetColumns+` FROM budgets WHERE project_id = ? AND id = ?`, projectID.String(), id.String(),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrNotFound
+	}
+	return b, err
+}
+
+// DeleteBudget deletes one of a project's budgets, or returns
+// store.ErrNotFound
+func (s *Store) DeleteBudget(ctx context.Context, projectID, id uuid.UUID) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM budgets WHERE project_id = ? AND id = ?`, projectID.String(), id.String())
+	if err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+	if deleted == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+)
+
+// CreateProject stores a new project
+func (s *Store) CreateProject(ctx context.Context, project models.Project) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO projects (id, name, created_at) VALUES (?, ?, ?)`,
+		project.ID.String(), project.Name, toMicros(project.CreatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create project: %w", err)
+	}
+
+	return nil
+}
+
+// scanProject reads a row selected as id, name, created_at
+func scanProject(row interface{ Scan(...interface{}) error }) (*models.Project, error) {
+	var p models.Project
+	var id string
+	var createdAt int64
+	if err := row.Scan(&id, &p.Name, &createdAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if p.ID, err = uuid.Parse(id); err != nil {
+		return nil, err
+	}
+	p.CreatedAt = fromMicros(createdAt)
+	return &p, nil
+}
+
+// ListProjects returns all projects, oldest first
+func (s *Store) ListProjects(ctx context.Context) ([]models.Project, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, name, created_at FROM projects ORDER BY created_at ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []models.Project{}
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, *p)
+	}
+
+	return projects, rows.Err()
+}
+
+// GetProject returns a project by ID, or store.ErrNotFound
+func (s *Store) GetProject(ctx context.Context, id uuid.UUID) (*models.Project, error) {
+	p, err := scanProject(s.db.QueryRowContext(ctx, `SELECT id, name, created_at FROM projects WHERE id = ?`, id.String()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrNotFound
+	}
+	return p, err
+}
+
+// apiKeyColumns lists the columns scanned by scanAPIKey
+const apiKeyColumns = `id, project_id, name, prefix, scopes, created_at, last_used_at, revoked_at`
+
+// scanAPIKey reads a row selected with apiKeyColumns
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (*models.APIKey, error) {
+	var key models.APIKey
+	var id, projectID, scopes string
+	var createdAt int64
+	var lastUsedAt, revokedAt sql.NullInt64
+	if err := row.Scan(&id, &projectID, &key.Name, &key.Prefix, &scopes, &createdAt, &lastUsedAt, &revokedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if key.ID, err = uuid.Parse(id); err != nil {
+		return nil, err
+	}
+	if key.ProjectID, err = uuid.Parse(projectID); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(scopes), &key.Scopes); err != nil {
+		return nil, fmt.Errorf("invalid scopes: %w", err)
+	}
+	key.CreatedAt = fromMicros(createdAt)
+	key.LastUsedAt = nullMicros(lastUsedAt)
+	key.RevokedAt = nullMicros(revokedAt)
+	return &key, nil
+}
+
+// CreateAPIKey stores a new API key for key.ProjectID by the hash of its secret
+func (s *Store) CreateAPIKey(ctx context.Context, key *models.APIKey, keyHash string) error {
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return fmt.Errorf("failed to encode scopes: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO api_keys (id, project_id, name, prefix, key_hash, scopes, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, key.ID.String(), key.ProjectID.String(), key.Name, key.Prefix, keyHash, string(scopes), toMicros(key.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return nil
+}
+
+// ListAPIKeys returns a project's API keys, newest first
+func (s *Store) ListAPIKeys(ctx context.Context, projectID uuid.UUID) ([]models.APIKey, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE project_id = ? ORDER BY created_at DESC`,
+		projectID.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	return keys, rows.Err()
+}
+
+// LookupAPIKey returns the active (not revoked) API key with the given
+// hash and records that it was used. It returns store.ErrNotFound for
+// unknown or revoked keys.
+func (s *Store) LookupAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	key, err := scanAPIKey(s.db.QueryRowContext(ctx, `
+		UPDATE api_keys SET last_used_at = ?
+		WHERE key_hash = ? AND revoked_at IS NULL
+		RETURNING `+apiKeyColumns,
+		toMicros(time.Now()), keyHash,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrNotFound
+	}
+	return key, err
+}
+
+// RevokeAPIKey marks a project's API key as revoked. Revoking an already
+// revoked key succeeds; unknown keys return store.ErrNotFound.
+func (s *Store) RevokeAPIKey(ctx context.Context, projectID, id uuid.UUID) (*models.APIKey, error) {
+	key, err := scanAPIKey(s.db.QueryRowContext(ctx, `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, ?)
+		WHERE id = ? AND project_id = ?
+		RETURNING `+apiKeyColumns,
+		toMicros(time.Now()), id.String(), projectID.String(),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrNotFound
+	}
+	return key, err
+}
@@ -0,0 +1,353 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+)
+
+// metadataText is the SQL expression for a metadata value as text,
+// matching PostgreSQL's ->> operator (and store.MetadataText): strings
+// unquoted, booleans as true/false, JSON null as NULL. Both placeholders
+// take the key's JSON path.
+const metadataText = `CASE json_type(metadata, ?)
+	WHEN 'true' THEN 'true'
+	WHEN 'false' THEN 'false'
+	ELSE CAST(json_extract(metadata, ?) AS TEXT)
+END`
+
+// jsonPath returns the JSON path selecting a top-level metadata key
+func jsonPath(key string) string {
+	return `$."` + strings.ReplaceAll(key, `"`, `\"`) + `"`
+}
+
+// metricsWhere returns the WHERE conditions selecting the project's tool
+// calls in the query's range that match its filter, and their arguments
+func metricsWhere(q models.MetricsQuery) (string, []interface{}) {
+	conds := []string{"created_at >= ?", "created_at < ?", "project_id = ?"}
+	args := []interface{}{toMicros(q.From), toMicros(q.To), q.ProjectID.String()}
+
+	f := q.Filter
+	if len(f.ToolNames) > 0 {
+		conds = append(conds, "tool_name IN ("+placeholders(len(f.ToolNames))+")")
+		for _, name := range f.ToolNames {
+			args = append(args, name)
+		}
+	}
+	if len(f.ExcludeToolNames) > 0 {
+		conds = append(conds, "tool_name NOT IN ("+placeholders(len(f.ExcludeToolNames))+")")
+		for _, name := range f.ExcludeToolNames {
+			args = append(args, name)
+		}
+	}
+	if len(f.Models) > 0 {
+		conds = append(conds, "model IN ("+placeholders(len(f.Models))+")")
+		for _, model := range f.Models {
+			args = append(args, model)
+		}
+	}
+	if f.Status != "" {
+		conds = append(conds, "status = ?")
+		args = append(args, f.Status)
+	}
+	if f.MinDurationMs != nil {
+		conds = append(conds, "duration_ms >= ?")
+		args = append(args, *f.MinDurationMs)
+	}
+	if f.MaxDurationMs != nil {
+		conds = append(conds, "duration_ms <= ?")
+		args = append(args, *f.MaxDurationMs)
+	}
+	// Sorted so the generated SQL is stable
+	keys := make([]string, 0, len(f.Metadata))
+	for key := range f.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		conds = append(conds, "("+metadataText+") = ?")
+		args = append(args, jsonPath(key), jsonPath(key), f.Metadata[key])
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+// groupExpression returns the SQL expression for the query's group_by
+// dimension and its arguments, or an empty string literal when results
+// aren't grouped
+func groupExpression(q models.MetricsQuery) (string, []interface{}) {
+	if q.GroupBy == models.GroupByToolName {
+		return "tool_name", nil
+	}
+	if q.GroupBy == models.GroupByModel {
+		return "COALESCE(model, '')", nil
+	}
+	if key, ok := q.GroupByMetadataKey(); ok {
+		return "COALESCE(" + metadataText + ", '')", []interface{}{jsonPath(key), jsonPath(key)}
+	}
+	return "''", nil
+}
+
+// metricsTotalsColumns selects the overview aggregates scanned into MetricsTotals
+const metricsTotalsColumns = `
+	COUNT(*) as total_calls,
+	COALESCE(AVG(duration_ms), 0.0) as avg_latency_ms,
+	COALESCE(SUM(input_tokens + output_tokens), 0) as total_tokens,
+	CASE
+		WHEN COUNT(*) > 0 THEN SUM(status = 'failed') * 100.0 / COUNT(*)
+		ELSE 0.0
+	END as failure_rate
+`
+
+// GetMetricsOverview returns aggregated overview metrics for the query's
+// range, compared against the same-length window compareOffset earlier
+func (s *Store) GetMetricsOverview(ctx context.Context, q models.MetricsQuery, compareOffset time.Duration) (*models.MetricsOverview, error) {
+	return store.BuildOverview(ctx, q, compareOffset, s.GetMetricsTotals, s.getGroupTotals)
+}
+
+// GetMetricsTotals aggregates the overview metrics for the query's range
+// and filter, without the overview's comparison
+func (s *Store) GetMetricsTotals(ctx context.Context, q models.MetricsQuery) (*models.MetricsTotals, error) {
+	where, args := metricsWhere(q)
+
+	var totals models.MetricsTotals
+	err := s.db.QueryRowContext(ctx, `SELECT `+metricsTotalsColumns+` FROM tool_calls WHERE `+where, args...).Scan(
+		&totals.TotalCalls,
+		&totals.AvgLatencyMs,
+		&totals.TotalTokens,
+		&totals.FailureRate,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &totals, nil
+}
+
+// getGroupTotals aggregates the overview metrics per group_by value,
+// busiest groups first
+func (s *Store) getGroupTotals(ctx context.Context, q models.MetricsQuery) ([]models.GroupTotals, error) {
+	group, groupArgs := groupExpression(q)
+	where, args := metricsWhere(q)
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+group+` as grp, `+metricsTotalsColumns+`
+		FROM tool_calls
+		WHERE `+where+`
+		GROUP BY 1
+		ORDER BY 2 DESC, 1
+	`, append(groupArgs, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []models.GroupTotals{}
+	for rows.Next() {
+		var g models.GroupTotals
+		if err := rows.Scan(&g.Group, &g.TotalCalls, &g.AvgLatencyMs, &g.TotalTokens, &g.FailureRate); err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+
+	return groups, rows.Err()
+}
+
+// matchingCalls loads the fields metrics are computed from for the tool
+// calls matching the query. Metadata is only loaded when results are
+// grouped by a metadata key.
+func (s *Store) matchingCalls(ctx context.Context, q models.MetricsQuery) ([]models.ToolCall, error) {
+	where, args := metricsWhere(q)
+	_, byMetadata := q.GroupByMetadataKey()
+
+	metadataColumn := "''"
+	if byMetadata {
+		metadataColumn = "metadata"
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT tool_name, model, duration_ms, status, input_tokens, output_tokens, cost_usd, created_at, `+metadataColumn+`
+		FROM tool_calls
+		WHERE `+where+`
+		ORDER BY created_at
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	var calls []models.ToolCall
+	for rows.Next() {
+		call := models.ToolCall{ProjectID: q.ProjectID}
+		var createdAt int64
+		var model sql.NullString
+		var cost sql.NullFloat64
+		var metadata sql.RawBytes
+		if err := rows.Scan(&call.ToolName, &model, &call.DurationMs, &call.Status, &call.InputTokens, &call.OutputTokens, &cost, &createdAt, &metadata); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		call.CreatedAt = fromMicros(createdAt)
+		if model.Valid {
+			call.Model = &model.String
+		}
+		if cost.Valid {
+			call.CostUSD = &cost.Float64
+		}
+		if byMetadata {
+			if err := json.Unmarshal(metadata, &call.Metadata); err != nil {
+				return nil, fmt.Errorf("invalid metadata: %w", err)
+			}
+		}
+		calls = append(calls, call)
+	}
+
+	return calls, rows.Err()
+}
+
+// GetToolCallsMetrics returns success and failure counts per time bucket
+func (s *Store) GetToolCallsMetrics(ctx context.Context, q models.MetricsQuery) ([]models.ToolCallDataPoint, error) {
+	calls, err := s.matchingCalls(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return store.ToolCallsSeries(q, calls), nil
+}
+
+// GetLatencyMetrics returns latency percentiles per tool. Unless the query
+// filters on status, only successful calls are measured.
+func (s *Store) GetLatencyMetrics(ctx context.Context, q models.MetricsQuery) ([]models.LatencyDataPoint, error) {
+	calls, err := s.matchingCalls(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return store.LatencyPercentiles(q, calls), nil
+}
+
+// GetLatencyTotals returns latency percentiles across all matching calls,
+// or nil when no call matched
+func (s *Store) GetLatencyTotals(ctx context.Context, q models.MetricsQuery) (*models.LatencyDataPoint, error) {
+	calls, err := s.matchingCalls(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return store.LatencyTotals(q, calls), nil
+}
+
+// GetWindowCounts counts the matching calls of trailing windows ending at
+// q.To in a single scan, one result per start
+func (s *Store) GetWindowCounts(ctx context.Context, q models.MetricsQuery, starts []time.Time, fastMs int) ([]models.WindowCounts, error) {
+	if len(starts) == 0 {
+		return nil, nil
+	}
+	q.From = slices.MinFunc(starts, time.Time.Compare)
+	where, whereArgs := metricsWhere(q)
+
+	columns := make([]string, 0, 3*len(starts))
+	var args []interface{}
+	for _, start := range starts {
+		since := toMicros(start)
+		fast := "0"
+		if fastMs > 0 {
+			fast = "duration_ms <= ?"
+		}
+		columns = append(columns,
+			"COALESCE(SUM(created_at >= ? AND status = 'success'), 0)",
+			"COALESCE(SUM(created_at >= ? AND status = 'failed'), 0)",
+			"COALESCE(SUM(created_at >= ? AND status = 'success' AND "+fast+"), 0)",
+		)
+		args = append(args, since, since, since)
+		if fastMs > 0 {
+			args = append(args, fastMs)
+		}
+	}
+
+	counts := make([]models.WindowCounts, len(starts))
+	dest := make([]interface{}, 0, 3*len(starts))
+	for i := range counts {
+		dest = append(dest, &counts[i].Success, &counts[i].Failures, &counts[i].FastSuccess)
+	}
+	query := `SELECT ` + strings.Join(columns, ", ") + ` FROM tool_calls WHERE ` + where
+	if err := s.db.QueryRowContext(ctx, query, append(args, whereArgs...)...).Scan(dest...); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// GetTokenUsageMetrics returns token usage per time bucket
+func (s *Store) GetTokenUsageMetrics(ctx context.Context, q models.MetricsQuery) ([]models.TokenUsageDataPoint, error) {
+	calls, err := s.matchingCalls(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return store.TokenUsageSeries(q, calls), nil
+}
+
+// GetFailureRateMetrics returns the failure percentage per time bucket
+func (s *Store) GetFailureRateMetrics(ctx context.Context, q models.MetricsQuery) ([]models.FailureRateDataPoint, error) {
+	calls, err := s.matchingCalls(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return store.FailureRateSeries(q, calls), nil
+}
+
+// costTotalsColumns selects the cost aggregates scanned into CostTotals
+const costTotalsColumns = `
+	COUNT(*),
+	COALESCE(SUM(model IS NOT NULL AND cost_usd IS NULL), 0),
+	COALESCE(SUM(input_tokens), 0),
+	COALESCE(SUM(output_tokens), 0),
+	COALESCE(SUM(cached_tokens), 0),
+	COALESCE(SUM(cost_usd), 0.0)
+`
+
+// GetCostMetrics returns the cost of tool calls by tool, model and time
+// bucket
+func (s *Store) GetCostMetrics(ctx context.Context, q models.MetricsQuery) (*models.CostReport, error) {
+	return store.BuildCostReport(ctx, q, s.GetCostTotals,
+		func(ctx context.Context, q models.MetricsQuery) ([]models.CostDataPoint, error) {
+			calls, err := s.matchingCalls(ctx, q)
+			if err != nil {
+				return nil, err
+			}
+			return store.CostSeries(q, calls), nil
+		},
+	)
+}
+
+// GetCostTotals aggregates tokens and cost per group_by value
+func (s *Store) GetCostTotals(ctx context.Context, q models.MetricsQuery) ([]models.CostGroup, error) {
+	group, groupArgs := groupExpression(q)
+	where, args := metricsWhere(q)
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+group+` as grp, `+costTotalsColumns+`
+		FROM tool_calls
+		WHERE `+where+`
+		GROUP BY 1
+	`, append(groupArgs, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []models.CostGroup{}
+	for rows.Next() {
+		var g models.CostGroup
+		if err := rows.Scan(&g.Group, &g.Calls, &g.UnpricedCalls, &g.InputTokens, &g.OutputTokens, &g.CachedTokens, &g.CostUSD); err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+
+	return groups, rows.Err()
+}
@@ -0,0 +1,91 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+)
+
+// ListModelPrices returns all prices, by model and then effective time
+func (s *Store) ListModelPrices(ctx context.Context) ([]models.ModelPrice, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, model, input_per_million, output_per_million, cached_input_per_million, effective_from, created_at
+		FROM model_prices
+		ORDER BY model, effective_from
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := []models.ModelPrice{}
+	for rows.Next() {
+		var p models.ModelPrice
+		var id string
+		var cached sql.NullFloat64
+		var effectiveFrom, createdAt int64
+		if err := rows.Scan(&id, &p.Model, &p.InputPerMillion, &p.OutputPerMillion, &cached, &effectiveFrom, &createdAt); err != nil {
+			return nil, err
+		}
+		if p.ID, err = uuid.Parse(id); err != nil {
+			return nil, err
+		}
+		if cached.Valid {
+			p.CachedInputPerMillion = &cached.Float64
+		}
+		p.EffectiveFrom = fromMicros(effectiveFrom)
+		p.CreatedAt = fromMicros(createdAt)
+		prices = append(prices, p)
+	}
+
+	return prices, rows.Err()
+}
+
+// SetModelPrice stores a price, replacing the model's price with the same
+// effective time
+func (s *Store) SetModelPrice(ctx context.Context, price *models.ModelPrice) error {
+	var id string
+	var createdAt int64
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO model_prices (id, model, input_per_million, output_per_million, cached_input_per_million, effective_from, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (model, effective_from) DO UPDATE SET
+			input_per_million = excluded.input_per_million,
+			output_per_million = excluded.output_per_million,
+			cached_input_per_million = excluded.cached_input_per_million
+		RETURNING id, created_at
+	`, price.ID.String(), price.Model, price.InputPerMillion, price.OutputPerMillion, price.CachedInputPerMillion,
+		toMicros(price.EffectiveFrom), toMicros(price.CreatedAt),
+	).Scan(&id, &createdAt)
+	if err != nil {
+		return fmt.Errorf("failed to set model price: %w", err)
+	}
+
+	if price.ID, err = uuid.Parse(id); err != nil {
+		return err
+	}
+	price.CreatedAt = fromMicros(createdAt)
+	return nil
+}
+
+// DeleteModelPrice deletes a price, or returns store.ErrNotFound
+func (s *Store) DeleteModelPrice(ctx context.Context, id uuid.UUID) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM model_prices WHERE id = ?`, id.String())
+	if err != nil {
+		return fmt.Errorf("failed to delete model price: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete model price: %w", err)
+	}
+	if deleted == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+)
+
+// GetDataPolicies returns the retention policies
+func (s *Store) GetDataPolicies(ctx context.Context) (*models.DataPolicies, error) {
+	var policies models.DataPolicies
+	err := s.db.QueryRowContext(ctx, `SELECT raw_retention_days FROM data_policies WHERE id = 1`).Scan(&policies.RawRetentionDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data policies: %w", err)
+	}
+
+	return &policies, nil
+}
+
+// SetDataPolicies replaces the retention policies. Only raw retention is
+// supported.
+func (s *Store) SetDataPolicies(ctx context.Context, policies models.DataPolicies) error {
+	if err := store.RawRetentionOnly("sqlite", policies); err != nil {
+		return err
+	}
+
+	_, err := s.db.ExecContext(ctx, `UPDATE data_policies SET raw_retention_days = ? WHERE id = 1`, policies.RawRetentionDays)
+	if err != nil {
+		return fmt.Errorf("failed to update data policies: %w", err)
+	}
+
+	return nil
+}
+
+// EnforceRetention deletes tool calls older than the raw retention
+func (s *Store) EnforceRetention(ctx context.Context) (int64, error) {
+	policies, err := s.GetDataPolicies(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if policies.RawRetentionDays == 0 {
+		return 0, nil
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -policies.RawRetentionDays)
+	result, err := s.db.ExecContext(ctx, `DELETE FROM tool_calls WHERE created_at < ?`, toMicros(cutoff))
+	if err != nil {
+		return 0, fmt.Errorf("failed to enforce retention: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// DeleteToolCalls deletes a project's tool calls matching the filter and
+// their claimed event IDs
+func (s *Store) DeleteToolCalls(ctx context.Context, projectID uuid.UUID, filter models.DeleteFilter) (int64, error) {
+	conds := []string{"project_id = ?"}
+	args := []interface{}{projectID.String()}
+	if filter.RequestID != nil {
+		conds = append(conds, "request_id = ?")
+		args = append(args, filter.RequestID.String())
+	}
+	if filter.ToolName != "" {
+		conds = append(conds, "tool_name = ?")
+		args = append(args, filter.ToolName)
+	}
+	if !filter.From.IsZero() {
+		conds = append(conds, "created_at >= ?")
+		args = append(args, toMicros(filter.From))
+	}
+	if !filter.To.IsZero() {
+		conds = append(conds, "created_at < ?")
+		args = append(args, toMicros(filter.To))
+	}
+	keys := make([]string, 0, len(filter.Metadata))
+	for key := range filter.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		conds = append(conds, "("+metadataText+") = ?")
+		args = append(args, jsonPath(key), jsonPath(key), filter.Metadata[key])
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete tool calls: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `DELETE FROM tool_calls WHERE `+strings.Join(conds, " AND ")+` RETURNING event_id`, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete tool calls: %w", err)
+	}
+	var deleted int64
+	var eventIDs []interface{}
+	for rows.Next() {
+		var eventID sql.NullString
+		if err := rows.Scan(&eventID); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to delete tool calls: %w", err)
+		}
+		deleted++
+		if eventID.Valid {
+			eventIDs = append(eventIDs, eventID.String)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to delete tool calls: %w", err)
+	}
+
+	// Deduplication records hold a copy of the event
+	for len(eventIDs) > 0 {
+		batch := eventIDs[:min(len(eventIDs), 500)]
+		eventIDs = eventIDs[len(batch):]
+		_, err := tx.ExecContext(ctx,
+			`DELETE FROM ingest_keys WHERE project_id = ? AND event_id IN (`+placeholders(len(batch))+`)`,
+			append([]interface{}{projectID.String()}, batch...)...,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to delete event IDs: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to delete tool calls: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+)
+
+// sloColumns lists the columns scanned by scanSLO
+const sloColumns = `id, project_id, name, type, objective, COALESCE(latency_threshold_ms, 0), window_days, filter, created_at`
+
+// scanSLO reads a row selected with sloColumns
+func scanSLO(row interface{ Scan(...interface{}) error }) (*models.SLO, error) {
+	var slo models.SLO
+	var id, projectID, filter string
+	var createdAt int64
+	err := row.Scan(&id, &projectID, &slo.Name, &slo.Type, &slo.Objective,
+		&slo.LatencyThresholdMs, &slo.WindowDays, &filter, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if slo.ID, err = uuid.Parse(id); err != nil {
+		return nil, err
+	}
+	if slo.ProjectID, err = uuid.Parse(projectID); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(filter), &slo.Filter); err != nil {
+		return nil, fmt.Errorf("invalid SLO filter: %w", err)
+	}
+	slo.CreatedAt = fromMicros(createdAt)
+	return &slo, nil
+}
+
+// CreateSLO stores a new SLO
+func (s *Store) CreateSLO(ctx context.Context, slo models.SLO) error {
+	filter, err := json.Marshal(slo.Filter)
+	if err != nil {
+		return fmt.Errorf("failed to encode SLO filter: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO slos (id, project_id, name, type, objective, latency_threshold_ms, window_days, filter, created_at)
+		VALUES (?, ?, ?, ?, ?, NULLIF(?, 0), ?, ?, ?)
+	`, slo.ID.String(), slo.ProjectID.String(), slo.Name, slo.Type, slo.Objective,
+		slo.LatencyThresholdMs, slo.WindowDays, string(filter), toMicros(slo.CreatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create SLO: %w", err)
+	}
+
+	return nil
+}
+
+// ListSLOs returns a project's SLOs, oldest first
+func (s *Store) ListSLOs(ctx context.Context, projectID uuid.UUID) ([]models.SLO, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+sloColumns+` FROM slos WHERE project_id = ? ORDER BY created_at ASC`, projectID.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slos := []models.SLO{}
+	for rows.Next() {
+		slo, err := scanSLO(rows)
+		if err != nil {
+			return nil, err
+		}
+		slos = append(slos, *slo)
+	}
+
+	return slos, rows.Err()
+}
+
+// GetSLO returns one of a project's SLOs, or store.ErrNotFound
+func (s *Store) GetSLO(ctx context.Context, projectID, id uuid.UUID) (*models.SLO, error) {
+	slo, err := scanSLO(s.db.QueryRowContext(ctx,
+		`SELECT `+sloColumns+` FROM slos WHERE project_id = ? AND id = ?`, projectID.String(), id.String(),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrNotFound
+	}
+	return slo, err
+}
+
+// UpdateSLO replaces an SLO's definition, or returns store.ErrNotFound
+func (s *Store) UpdateSLO(ctx context.Context, slo models.SLO) error {
+	filter, err := json.Marshal(slo.Filter)
+	if err != nil {
+		return fmt.Errorf("failed to encode SLO filter: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE slos
+		SET name = ?, type = ?, objective = ?, latency_threshold_ms = NULLIF(?, 0), window_days = ?, filter = ?
+		WHERE project_id = ? AND id = ?
+	`, slo.Name, slo.Type, slo.Objective, slo.LatencyThresholdMs, slo.WindowDays, string(filter),
+		slo.ProjectID.String(), slo.ID.String(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update SLO: %w", err)
+	}
+	return requireAffected(result, "update SLO")
+}
+
+// DeleteSLO deletes an SLO, or returns store.ErrNotFound
+func (s *Store) DeleteSLO(ctx context.Context, projectID, id uuid.UUID) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM slos WHERE project_id = ? AND id = ?`, projectID.String(), id.String())
+	if err != nil {
+		return fmt.Errorf("failed to delete SLO: %w", err)
+	}
+	return requireAffected(result, "delete SLO")
+}
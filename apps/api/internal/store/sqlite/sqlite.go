@@ -0,0 +1,382 @@
+// Package sqlite is a store backed by an embedded SQLite database, for
+// single-binary deployments without TimescaleDB. Aggregates SQLite can't
+// compute (timezone-aligned buckets, percentiles) are computed in Go.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3" // registers the sqlite3 driver
+
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+)
+
+// URLScheme prefixes DATABASE_URLs pointing at a SQLite file, e.g.
+// sqlite://./nous.db or sqlite:///var/lib/nous/nous.db
+const URLScheme = "sqlite://"
+
+var _ store.Store = (*Store)(nil)
+
+// Store is the SQLite store
+type Store struct {
+	db *sql.DB
+
+	// How long a claimed event ID blocks duplicates
+	dedupWindow time.Duration
+}
+
+// Open opens (creating if needed) the SQLite database at path. Writes take
+// the database lock up front and wait for other writers instead of failing.
+func Open(path string) (*sql.DB, error) {
+	if path == "" {
+		return nil, fmt.Errorf("sqlite database path is empty")
+	}
+
+	params := url.Values{}
+	params.Set("_foreign_keys", "on")
+	params.Set("_journal_mode", "WAL")
+	params.Set("_busy_timeout", "5000")
+	params.Set("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// PathFromURL returns the file path of a sqlite:// DATABASE_URL
+func PathFromURL(databaseURL string) (string, bool) {
+	return strings.CutPrefix(databaseURL, URLScheme)
+}
+
+// New creates a store on a migrated SQLite database
+func New(db *sql.DB) *Store {
+	return &Store{db: db, dedupWindow: store.DefaultDedupWindow}
+}
+
+// SetDedupWindow configures how long client event IDs are deduplicated
+func (s *Store) SetDedupWindow(window time.Duration) {
+	if window > 0 {
+		s.dedupWindow = window
+	}
+}
+
+// Ping checks the database is reachable
+func (s *Store) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// toMicros encodes a timestamp as stored: Unix microseconds
+func toMicros(t time.Time) int64 {
+	return t.UnixMicro()
+}
+
+// fromMicros decodes a stored timestamp
+func fromMicros(us int64) time.Time {
+	return time.UnixMicro(us).UTC()
+}
+
+// nullMicros decodes a nullable stored timestamp
+func nullMicros(us sql.NullInt64) *time.Time {
+	if !us.Valid {
+		return nil
+	}
+	t := fromMicros(us.Int64)
+	return &t
+}
+
+// insertToolCall writes one tool call row
+const insertToolCall = `
+	INSERT INTO tool_calls (
+		id, request_id, tool_name, duration_ms, status,
+		input_tokens, output_tokens, error_message, metadata, created_at,
+		span_id, parent_span_id, event_id, project_id,
+		model, cached_tokens, cost_usd
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+// toolCallRow returns the values of insertToolCall for a tool call
+func toolCallRow(call models.ToolCall) ([]interface{}, error) {
+	metadata := call.Metadata
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+
+	return []interface{}{
+		call.ID.String(), call.RequestID.String(), call.ToolName, call.DurationMs, call.Status,
+		call.InputTokens, call.OutputTokens, call.ErrorMessage, string(encoded), toMicros(call.CreatedAt),
+		call.SpanID, call.ParentSpanID, call.EventID, call.ProjectID.String(),
+		call.Model, call.CachedTokens, call.CostUSD,
+	}, nil
+}
+
+// IngestToolCall stores a tool call
+func (s *Store) IngestToolCall(ctx context.Context, call models.ToolCall) error {
+	return s.IngestToolCalls(ctx, []models.ToolCall{call})
+}
+
+// IngestToolCalls stores a batch of tool calls in one transaction
+func (s *Store) IngestToolCalls(ctx context.Context, calls []models.ToolCall) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, insertToolCall)
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, call := range calls {
+		row, err := toolCallRow(call)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return fmt.Errorf("failed to insert tool call: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ClaimEventIDs records the event IDs of a project's calls that carry one.
+// The result is aligned with calls: nil for new calls, otherwise the
+// originally ingested record. An event ID repeated within calls is a
+// duplicate of its first occurrence, so of the original record when the
+// first occurrence is a replay.
+func (s *Store) ClaimEventIDs(ctx context.Context, projectID uuid.UUID, calls []models.ToolCall) ([]*models.ToolCall, error) {
+	originals := make([]*models.ToolCall, len(calls))
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim event IDs: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	expired := toMicros(now.Add(-s.dedupWindow))
+	first := make(map[string]int)
+	for i, call := range calls {
+		if call.EventID == nil {
+			continue
+		}
+		if j, ok := first[*call.EventID]; ok {
+			// The first occurrence is resolved: a replay or new
+			if originals[j] != nil {
+				originals[i] = originals[j]
+			} else {
+				originals[i] = &calls[j]
+			}
+			continue
+		}
+		first[*call.EventID] = i
+
+		record, err := json.Marshal(call)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode record: %w", err)
+		}
+
+		// Expired keys are reclaimed; live keys are left untouched
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO ingest_keys (project_id, event_id, record, received_at)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT (project_id, event_id) DO UPDATE
+				SET record = excluded.record, received_at = excluded.received_at
+				WHERE ingest_keys.received_at < ?
+		`, projectID.String(), *call.EventID, string(record), toMicros(now), expired)
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim event IDs: %w", err)
+		}
+		claimed, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim event IDs: %w", err)
+		}
+		if claimed > 0 {
+			continue
+		}
+
+		var encoded []byte
+		err = tx.QueryRowContext(ctx,
+			`SELECT record FROM ingest_keys WHERE project_id = ? AND event_id = ?`,
+			projectID.String(), *call.EventID,
+		).Scan(&encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load original record: %w", err)
+		}
+		var original models.ToolCall
+		if err := json.Unmarshal(encoded, &original); err != nil {
+			return nil, fmt.Errorf("failed to decode original record: %w", err)
+		}
+		originals[i] = &original
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to claim event IDs: %w", err)
+	}
+	return originals, nil
+}
+
+// ReleaseEventIDs forgets a project's claimed event IDs, e.g. when the
+// events could not be stored, so a retry is not mistaken for a duplicate
+func (s *Store) ReleaseEventIDs(ctx context.Context, projectID uuid.UUID, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	args := []interface{}{projectID.String()}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	query := `DELETE FROM ingest_keys WHERE project_id = ? AND event_id IN (` + placeholders(len(ids)) + `)`
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to release event IDs: %w", err)
+	}
+
+	return nil
+}
+
+// PruneEventIDs deletes event IDs older than the dedup window
+func (s *Store) PruneEventIDs(ctx context.Context) (int64, error) {
+	result, err := s.db.ExecContext(ctx,
+		`DELETE FROM ingest_keys WHERE received_at < ?`,
+		toMicros(time.Now().Add(-s.dedupWindow)),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune event IDs: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// toolCallSelectColumns lists the columns scanned by scanToolCalls
+const toolCallSelectColumns = `
+	id, request_id, tool_name, duration_ms, status,
+	input_tokens, output_tokens, error_message, metadata, created_at,
+	span_id, parent_span_id, event_id, project_id,
+	model, cached_tokens, cost_usd
+`
+
+// scanToolCalls reads tool call rows selected with toolCallSelectColumns
+func scanToolCalls(rows *sql.Rows) ([]models.ToolCall, error) {
+	defer rows.Close()
+
+	var results []models.ToolCall
+	for rows.Next() {
+		var tc models.ToolCall
+		var id, requestID, projectID, metadata string
+		var createdAt int64
+		var errorMsg, spanID, parentSpanID, eventID, model sql.NullString
+		var cost sql.NullFloat64
+		if err := rows.Scan(
+			&id, &requestID, &tc.ToolName, &tc.DurationMs, &tc.Status,
+			&tc.InputTokens, &tc.OutputTokens, &errorMsg, &metadata, &createdAt,
+			&spanID, &parentSpanID, &eventID, &projectID,
+			&model, &tc.CachedTokens, &cost,
+		); err != nil {
+			return nil, err
+		}
+
+		var err error
+		if tc.ID, err = uuid.Parse(id); err != nil {
+			return nil, err
+		}
+		if tc.RequestID, err = uuid.Parse(requestID); err != nil {
+			return nil, err
+		}
+		if tc.ProjectID, err = uuid.Parse(projectID); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(metadata), &tc.Metadata); err != nil {
+			return nil, fmt.Errorf("invalid metadata: %w", err)
+		}
+		tc.CreatedAt = fromMicros(createdAt)
+		if errorMsg.Valid {
+			tc.ErrorMessage = &errorMsg.String
+		}
+		if spanID.Valid {
+			tc.SpanID = &spanID.String
+		}
+		if parentSpanID.Valid {
+			tc.ParentSpanID = &parentSpanID.String
+		}
+		if eventID.Valid {
+			tc.EventID = &eventID.String
+		}
+		if model.Valid {
+			tc.Model = &model.String
+		}
+		if cost.Valid {
+			tc.CostUSD = &cost.Float64
+		}
+		results = append(results, tc)
+	}
+
+	return results, rows.Err()
+}
+
+// GetRecentToolCalls returns the most recent tool calls in a project
+func (s *Store) GetRecentToolCalls(ctx context.Context, projectID uuid.UUID, limit int) ([]models.ToolCall, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+toolCallSelectColumns+`
+		FROM tool_calls
+		WHERE project_id = ?
+		ORDER BY created_at DESC
+		LIMIT ?
+	`, projectID.String(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanToolCalls(rows)
+}
+
+// GetToolCallChain returns all tool calls in a project for a specific request ID
+func (s *Store) GetToolCallChain(ctx context.Context, projectID, requestID uuid.UUID) ([]models.ToolCall, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+toolCallSelectColumns+`
+		FROM tool_calls
+		WHERE project_id = ? AND request_id = ?
+		ORDER BY created_at ASC
+	`, projectID.String(), requestID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return scanToolCalls(rows)
+}
+
+// GetToolCallTree returns the tool calls for a request ID arranged by
+// their span parent/child relationships
+func (s *Store) GetToolCallTree(ctx context.Context, projectID, requestID uuid.UUID) (*models.ToolCallTree, error) {
+	calls, err := s.GetToolCallChain(ctx, projectID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.BuildToolCallTree(requestID, calls), nil
+}
+
+// placeholders returns n comma-separated ? placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
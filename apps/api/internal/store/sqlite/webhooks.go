@@ -0,0 +1,188 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+	"github.com/yourorg/nous/internal/store"
+)
+
+// webhookColumns lists the columns scanned by scanWebhook
+const webhookColumns = `id, project_id, name, url, events, secret, enabled, created_at`
+
+// scanWebhook reads a row selected with webhookColumns
+func scanWebhook(row interface{ Scan(...interface{}) error }) (*models.Webhook, error) {
+	var w models.Webhook
+	var id, projectID, events string
+	var createdAt int64
+	if err := row.Scan(&id, &projectID, &w.Name, &w.URL, &events, &w.Secret, &w.Enabled, &createdAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if w.ID, err = uuid.Parse(id); err != nil {
+		return nil, err
+	}
+	if w.ProjectID, err = uuid.Parse(projectID); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(events), &w.Events); err != nil {
+		return nil, fmt.Errorf("invalid webhook events: %w", err)
+	}
+	w.CreatedAt = fromMicros(createdAt)
+	return &w, nil
+}
+
+// CreateWebhook stores a new webhook
+func (s *Store) CreateWebhook(ctx context.Context, webhook models.Webhook) error {
+	events := webhook.Events
+	if events == nil {
+		events = []string{}
+	}
+	encoded, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook events: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO webhooks (id, project_id, name, url, events, secret, enabled, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, webhook.ID.String(), webhook.ProjectID.String(), webhook.Name, webhook.URL, string(encoded),
+		webhook.Secret, webhook.Enabled, toMicros(webhook.CreatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return nil
+}
+
+// ListWebhooks returns a project's webhooks, oldest first
+func (s *Store) ListWebhooks(ctx context.Context, projectID uuid.UUID) ([]models.Webhook, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+webhookColumns+` FROM webhooks WHERE project_id = ? ORDER BY created_at ASC`, projectID.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, *w)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// GetWebhook returns one of a project's webhooks, or store.ErrNotFound
+func (s *Store) GetWebhook(ctx context.Context, projectID, id uuid.UUID) (*models.Webhook, error) {
+	w, err := scanWebhook(s.db.QueryRowContext(ctx,
+		`SELECT `+webhookColumns+` FROM webhooks WHERE project_id = ? AND id = ?`, projectID.String(), id.String(),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrNotFound
+	}
+	return w, err
+}
+
+// DeleteWebhook deletes a webhook with its delivery log, or returns
+// store.ErrNotFound
+func (s *Store) DeleteWebhook(ctx context.Context, projectID, id uuid.UUID) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM webhooks WHERE project_id = ? AND id = ?`, projectID.String(), id.String())
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return requireAffected(result, "delete webhook")
+}
+
+// RecordWebhookDelivery stores a delivery attempt
+func (s *Store) RecordWebhookDelivery(ctx context.Context, d models.WebhookDelivery) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (id, webhook_id, project_id, delivery_id, event, attempt, status_code, error, success, duration_ms, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), ?, ?, ?)
+	`, d.ID.String(), d.WebhookID.String(), d.ProjectID.String(), d.DeliveryID.String(), d.Event, d.Attempt,
+		d.StatusCode, d.Error, d.Success, d.DurationMs, toMicros(d.CreatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// ListWebhookDeliveries returns a webhook's most recent delivery attempts,
+// newest first
+func (s *Store) ListWebhookDeliveries(ctx context.Context, projectID, webhookID uuid.UUID, limit int) ([]models.WebhookDelivery, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, webhook_id, project_id, delivery_id, event, attempt, status_code, COALESCE(error, ''), success, duration_ms, created_at
+		FROM webhook_deliveries
+		WHERE project_id = ? AND webhook_id = ?
+		ORDER BY created_at DESC
+		LIMIT ?
+	`, projectID.String(), webhookID.String(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		var id, hookID, deliveryProjectID, deliveryID string
+		var statusCode sql.NullInt64
+		var createdAt int64
+		err := rows.Scan(&id, &hookID, &deliveryProjectID, &deliveryID, &d.Event, &d.Attempt,
+			&statusCode, &d.Error, &d.Success, &d.DurationMs, &createdAt)
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range []struct {
+			value string
+			dest  *uuid.UUID
+		}{
+			{id, &d.ID}, {hookID, &d.WebhookID}, {deliveryProjectID, &d.ProjectID}, {deliveryID, &d.DeliveryID},
+		} {
+			if *field.dest, err = uuid.Parse(field.value); err != nil {
+				return nil, err
+			}
+		}
+		if statusCode.Valid {
+			code := int(statusCode.Int64)
+			d.StatusCode = &code
+		}
+		d.CreatedAt = fromMicros(createdAt)
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// RecordErrorType stores an error type unless the project already has it,
+// reporting whether it is new
+func (s *Store) RecordErrorType(ctx context.Context, e models.ErrorType) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO error_types (project_id, tool_name, fingerprint, message, first_seen_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (project_id, tool_name, fingerprint) DO NOTHING
+	`, e.ProjectID.String(), e.ToolName, e.Fingerprint, e.Message, toMicros(e.FirstSeenAt))
+	if err != nil {
+		return false, fmt.Errorf("failed to record error type: %w", err)
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to record error type: %w", err)
+	}
+
+	return inserted > 0, nil
+}
@@ -0,0 +1,225 @@
+// Package store defines the storage backend the API runs on, implemented by
+// repository (PostgreSQL/TimescaleDB), store/sqlite and store/memory.
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+// ErrNotFound is returned when a requested record does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrUnsupported is returned (wrapped with details) for features the
+// backend lacks, e.g. compression outside TimescaleDB
+var ErrUnsupported = errors.New("not supported")
+
+// DefaultDedupWindow is how long event IDs are remembered unless configured
+const DefaultDedupWindow = 24 * time.Hour
+
+// Store is everything the API needs from a storage backend
+type Store interface {
+	IngestStore
+	MetricsStore
+	ToolCallStore
+	ProjectStore
+	APIKeyStore
+	RetentionStore
+	PricingStore
+	BudgetStore
+	AlertStore
+	WebhookStore
+	SLOStore
+
+	// Ping checks the backend is reachable
+	Ping(ctx context.Context) error
+}
+
+// IngestStore stores tool calls and deduplicates client event IDs
+type IngestStore interface {
+	// IngestToolCall stores a single tool call
+	IngestToolCall(ctx context.Context, call models.ToolCall) error
+
+	// IngestToolCalls stores a batch of tool calls
+	IngestToolCalls(ctx context.Context, calls []models.ToolCall) error
+
+	// ClaimEventIDs records the event IDs of a project's calls. The result
+	// is aligned with calls: nil for new calls (or calls without an event
+	// ID), otherwise the originally ingested record for that event ID.
+	ClaimEventIDs(ctx context.Context, projectID uuid.UUID, calls []models.ToolCall) ([]*models.ToolCall, error)
+
+	// ReleaseEventIDs forgets claimed event IDs whose calls weren't stored
+	ReleaseEventIDs(ctx context.Context, projectID uuid.UUID, ids []string) error
+
+	// PruneEventIDs forgets event IDs older than the dedup window,
+	// returning how many were removed
+	PruneEventIDs(ctx context.Context) (int64, error)
+
+	// SetDedupWindow configures how long event IDs are remembered
+	SetDedupWindow(window time.Duration)
+}
+
+// MetricsStore aggregates a project's tool calls
+type MetricsStore interface {
+	GetMetricsOverview(ctx context.Context, q models.MetricsQuery, compareOffset time.Duration) (*models.MetricsOverview, error)
+	GetToolCallsMetrics(ctx context.Context, q models.MetricsQuery) ([]models.ToolCallDataPoint, error)
+	GetLatencyMetrics(ctx context.Context, q models.MetricsQuery) ([]models.LatencyDataPoint, error)
+	GetTokenUsageMetrics(ctx context.Context, q models.MetricsQuery) ([]models.TokenUsageDataPoint, error)
+	GetFailureRateMetrics(ctx context.Context, q models.MetricsQuery) ([]models.FailureRateDataPoint, error)
+	GetCostMetrics(ctx context.Context, q models.MetricsQuery) (*models.CostReport, error)
+
+	// GetCostTotals aggregates tokens and cost per value of the query's
+	// group_by dimension (a single "" group when ungrouped), in no order
+	GetCostTotals(ctx context.Context, q models.MetricsQuery) ([]models.CostGroup, error)
+
+	// GetMetricsTotals aggregates the overview metrics of the query's range
+	// alone, without comparing against an earlier window
+	GetMetricsTotals(ctx context.Context, q models.MetricsQuery) (*models.MetricsTotals, error)
+
+	// GetLatencyTotals returns latency percentiles across all matching
+	// calls rather than per tool, nil when no call matched
+	GetLatencyTotals(ctx context.Context, q models.MetricsQuery) (*models.LatencyDataPoint, error)
+
+	// GetWindowCounts counts the matching calls of trailing windows ending
+	// at q.To in a single scan, one result per start (q.From is ignored).
+	// Successful calls lasting at most fastMs are also counted when it's
+	// positive.
+	GetWindowCounts(ctx context.Context, q models.MetricsQuery, starts []time.Time, fastMs int) ([]models.WindowCounts, error)
+}
+
+// ToolCallStore reads individual tool calls
+type ToolCallStore interface {
+	GetRecentToolCalls(ctx context.Context, projectID uuid.UUID, limit int) ([]models.ToolCall, error)
+	GetToolCallChain(ctx context.Context, projectID, requestID uuid.UUID) ([]models.ToolCall, error)
+	GetToolCallTree(ctx context.Context, projectID, requestID uuid.UUID) (*models.ToolCallTree, error)
+}
+
+// ProjectStore manages projects
+type ProjectStore interface {
+	CreateProject(ctx context.Context, project models.Project) error
+	ListProjects(ctx context.Context) ([]models.Project, error)
+	GetProject(ctx context.Context, id uuid.UUID) (*models.Project, error)
+}
+
+// APIKeyStore manages API keys, stored by the hash of their secret
+type APIKeyStore interface {
+	CreateAPIKey(ctx context.Context, key *models.APIKey, keyHash string) error
+	ListAPIKeys(ctx context.Context, projectID uuid.UUID) ([]models.APIKey, error)
+	LookupAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, projectID, id uuid.UUID) (*models.APIKey, error)
+}
+
+// RetentionStore manages how long tool calls are kept and deletes them on
+// request
+type RetentionStore interface {
+	// GetDataPolicies returns the current retention and compression policies
+	GetDataPolicies(ctx context.Context) (*models.DataPolicies, error)
+
+	// SetDataPolicies replaces the policies. Policies the backend can't
+	// enforce return an error wrapping ErrUnsupported.
+	SetDataPolicies(ctx context.Context, policies models.DataPolicies) error
+
+	// EnforceRetention deletes tool calls older than the raw retention,
+	// returning how many were removed. Backends with scheduled policies
+	// (TimescaleDB) do nothing.
+	EnforceRetention(ctx context.Context) (int64, error)
+
+	// DeleteToolCalls deletes a project's tool calls matching the filter,
+	// and their deduplication records, returning how many were removed
+	DeleteToolCalls(ctx context.Context, projectID uuid.UUID, filter models.DeleteFilter) (int64, error)
+}
+
+// PricingStore manages the model price catalog tool call costs are
+// computed from
+type PricingStore interface {
+	// ListModelPrices returns all prices, by model and then effective time
+	ListModelPrices(ctx context.Context) ([]models.ModelPrice, error)
+
+	// SetModelPrice stores a price, replacing the model's price with the
+	// same effective time. The price's ID is set to that of the stored row.
+	SetModelPrice(ctx context.Context, price *models.ModelPrice) error
+
+	// DeleteModelPrice deletes a price, or returns ErrNotFound
+	DeleteModelPrice(ctx context.Context, id uuid.UUID) error
+}
+
+// BudgetStore manages token and cost budgets
+type BudgetStore interface {
+	CreateBudget(ctx context.Context, budget models.Budget) error
+	ListBudgets(ctx context.Context, projectID uuid.UUID) ([]models.Budget, error)
+	GetBudget(ctx context.Context, projectID, id uuid.UUID) (*models.Budget, error)
+	DeleteBudget(ctx context.Context, projectID, id uuid.UUID) error
+
+	// ListAllBudgets returns the budgets of every project
+	ListAllBudgets(ctx context.Context) ([]models.Budget, error)
+}
+
+// AlertStore manages alert rules, their evaluation state and history
+type AlertStore interface {
+	CreateAlertRule(ctx context.Context, rule models.AlertRule) error
+	ListAlertRules(ctx context.Context, projectID uuid.UUID) ([]models.AlertRule, error)
+	GetAlertRule(ctx context.Context, projectID, id uuid.UUID) (*models.AlertRule, error)
+
+	// UpdateAlertRule replaces a rule's definition, keeping its state, or
+	// returns ErrNotFound
+	UpdateAlertRule(ctx context.Context, rule models.AlertRule) error
+
+	// DeleteAlertRule deletes a rule with its history, or returns
+	// ErrNotFound
+	DeleteAlertRule(ctx context.Context, projectID, id uuid.UUID) error
+
+	// ListAllAlertRules returns the rules of every project
+	ListAllAlertRules(ctx context.Context) ([]models.AlertRule, error)
+
+	// SetAlertState stores a rule's evaluation state, and the event of its
+	// state change when not nil, provided the stored state is still
+	// previous. It reports whether it did, so concurrent evaluators
+	// record each change once.
+	SetAlertState(ctx context.Context, rule models.AlertRule, previous string, event *models.AlertEvent) (bool, error)
+
+	// ListAlertEvents returns a project's most recent alert events, newest
+	// first, optionally only those of one rule
+	ListAlertEvents(ctx context.Context, projectID uuid.UUID, ruleID *uuid.UUID, limit int) ([]models.AlertEvent, error)
+}
+
+// WebhookStore manages outbound webhooks and their delivery log
+type WebhookStore interface {
+	CreateWebhook(ctx context.Context, webhook models.Webhook) error
+
+	// ListWebhooks and GetWebhook return webhooks with their secret, which
+	// handlers must clear from responses
+	ListWebhooks(ctx context.Context, projectID uuid.UUID) ([]models.Webhook, error)
+	GetWebhook(ctx context.Context, projectID, id uuid.UUID) (*models.Webhook, error)
+
+	// DeleteWebhook deletes a webhook with its delivery log, or returns
+	// ErrNotFound
+	DeleteWebhook(ctx context.Context, projectID, id uuid.UUID) error
+
+	RecordWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error
+
+	// ListWebhookDeliveries returns a webhook's most recent delivery
+	// attempts, newest first
+	ListWebhookDeliveries(ctx context.Context, projectID, webhookID uuid.UUID, limit int) ([]models.WebhookDelivery, error)
+
+	// RecordErrorType stores an error type unless the project already has
+	// it, reporting whether it is new
+	RecordErrorType(ctx context.Context, errorType models.ErrorType) (bool, error)
+}
+
+// SLOStore manages service level objectives
+type SLOStore interface {
+	CreateSLO(ctx context.Context, slo models.SLO) error
+	ListSLOs(ctx context.Context, projectID uuid.UUID) ([]models.SLO, error)
+	GetSLO(ctx context.Context, projectID, id uuid.UUID) (*models.SLO, error)
+
+	// UpdateSLO replaces an SLO's definition, or returns ErrNotFound
+	UpdateSLO(ctx context.Context, slo models.SLO) error
+
+	// DeleteSLO deletes an SLO, or returns ErrNotFound
+	DeleteSLO(ctx context.Context, projectID, id uuid.UUID) error
+}
@@ -0,0 +1,373 @@
+// Package webhook delivers a project's events to its webhooks as signed
+// JSON POST requests, retrying failed deliveries with exponential backoff
+// and recording every attempt.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+// Request headers of deliveries
+const (
+	HeaderEvent     = "X-Nous-Event"
+	HeaderDelivery  = "X-Nous-Delivery"
+	HeaderTimestamp = "X-Nous-Timestamp"
+	HeaderSignature = "X-Nous-Signature"
+)
+
+// ErrClosed is returned when a delivery is queued after Close
+var ErrClosed = errors.New("webhook dispatcher is closed")
+
+// Store lists webhooks and records delivery attempts
+type Store interface {
+	ListWebhooks(ctx context.Context, projectID uuid.UUID) ([]models.Webhook, error)
+	RecordWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error
+}
+
+// Config controls delivery timeouts and retries
+type Config struct {
+	// Attempts per delivery, including the first
+	MaxAttempts int
+
+	// Wait before the first retry, doubled for each further retry
+	InitialBackoff time.Duration
+
+	// Longest wait between retries
+	MaxBackoff time.Duration
+
+	// Time allowed for a webhook to respond
+	Timeout time.Duration
+
+	// Maximum number of deliveries waiting to be sent
+	QueueSize int
+
+	// Number of deliveries sent concurrently
+	Workers int
+}
+
+// DefaultConfig returns the configuration used when nothing is overridden
+func DefaultConfig() Config {
+	return Config{
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Minute,
+		Timeout:        10 * time.Second,
+		QueueSize:      1000,
+		Workers:        4,
+	}
+}
+
+// job is one delivery attempt of a payload to a webhook
+type job struct {
+	hook    models.Webhook
+	payload models.WebhookPayload
+	attempt int
+}
+
+// Dispatcher sends events to webhooks from a pool of workers. Retries wait
+// in timers rather than workers, so a slow webhook doesn't hold up others.
+type Dispatcher struct {
+	store  Store
+	config Config
+	client *http.Client
+
+	queue chan job
+	done  chan struct{}
+
+	// Guards closing the queue against concurrent sends
+	mu     sync.RWMutex
+	closed bool
+}
+
+// New creates a dispatcher recording deliveries in store. Call Run to start
+// sending.
+func New(store Store, config Config) *Dispatcher {
+	return NewWithClient(store, config, nil)
+}
+
+// NewWithClient creates a dispatcher sending requests with client, which
+// defaults to a client with the configured timeout that doesn't follow
+// redirects
+func NewWithClient(store Store, config Config, client *http.Client) *Dispatcher {
+	defaults := DefaultConfig()
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = defaults.InitialBackoff
+	}
+	if config.MaxBackoff < config.InitialBackoff {
+		config.MaxBackoff = max(defaults.MaxBackoff, config.InitialBackoff)
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaults.QueueSize
+	}
+	if config.Workers <= 0 {
+		config.Workers = defaults.Workers
+	}
+	if client == nil {
+		client = &http.Client{
+			Timeout: config.Timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+
+	return &Dispatcher{
+		store:  store,
+		config: config,
+		client: client,
+		queue:  make(chan job, config.QueueSize),
+		done:   make(chan struct{}),
+	}
+}
+
+// Run starts the workers. It returns once Close has been called and the
+// queued deliveries have been sent.
+func (d *Dispatcher) Run() {
+	defer close(d.done)
+
+	var wg sync.WaitGroup
+	for i := 0; i < d.config.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range d.queue {
+				d.send(j)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Close stops accepting deliveries, dropping pending retries, and waits
+// for the queued deliveries to be sent
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Notify queues an event for every webhook of the project wanting it,
+// without blocking the caller
+func (d *Dispatcher) Notify(projectID uuid.UUID, event string, data interface{}) {
+	go d.notify(projectID, event, data)
+}
+
+// notify lists the project's webhooks and queues the event for them
+func (d *Dispatcher) notify(projectID uuid.UUID, event string, data interface{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.config.Timeout)
+	defer cancel()
+
+	hooks, err := d.store.ListWebhooks(ctx, projectID)
+	if err != nil {
+		log.Printf("Error listing webhooks of project %s: %v", projectID, err)
+		return
+	}
+
+	payload := NewPayload(projectID, event, data)
+	for _, hook := range hooks {
+		if !hook.Wants(event) {
+			continue
+		}
+		if err := d.enqueue(job{hook: hook, payload: payload, attempt: 1}); err != nil {
+			log.Printf("Dropping %s event %s for webhook %s: %v", event, payload.ID, hook.ID, err)
+		}
+	}
+}
+
+// NewPayload builds the body of a new event
+func NewPayload(projectID uuid.UUID, event string, data interface{}) models.WebhookPayload {
+	return models.WebhookPayload{
+		ID:        uuid.New(),
+		Event:     event,
+		ProjectID: projectID,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+}
+
+// enqueue queues a delivery attempt without blocking
+func (d *Dispatcher) enqueue(j job) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		return ErrClosed
+	}
+
+	select {
+	case d.queue <- j:
+		return nil
+	default:
+		return fmt.Errorf("delivery queue is full")
+	}
+}
+
+// send makes a queued delivery attempt, scheduling a retry when it failed
+// in a way worth retrying
+func (d *Dispatcher) send(j job) {
+	delivery := d.Deliver(context.Background(), j.hook, j.payload, j.attempt)
+	if delivery.Success {
+		return
+	}
+
+	if !Retryable(delivery) || j.attempt >= d.config.MaxAttempts {
+		log.Printf("Giving up delivering %s event %s to webhook %s after %d attempts", j.payload.Event, j.payload.ID, j.hook.ID, j.attempt)
+		return
+	}
+
+	backoff := d.Backoff(j.attempt)
+	next := job{hook: j.hook, payload: j.payload, attempt: j.attempt + 1}
+	time.AfterFunc(backoff, func() {
+		if err := d.enqueue(next); err != nil {
+			log.Printf("Dropping retry of %s event %s for webhook %s: %v", next.payload.Event, next.payload.ID, next.hook.ID, err)
+		}
+	})
+}
+
+// Backoff returns the wait before the retry following an attempt
+func (d *Dispatcher) Backoff(attempt int) time.Duration {
+	backoff := d.config.InitialBackoff
+	for i := 1; i < attempt && backoff < d.config.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, d.config.MaxBackoff)
+}
+
+// Deliver POSTs a payload to a webhook once and records the attempt. Any
+// 2xx response is a success.
+func (d *Dispatcher) Deliver(ctx context.Context, hook models.Webhook, payload models.WebhookPayload, attempt int) models.WebhookDelivery {
+	delivery := models.WebhookDelivery{
+		ID:         uuid.New(),
+		WebhookID:  hook.ID,
+		ProjectID:  hook.ProjectID,
+		DeliveryID: payload.ID,
+		Event:      payload.Event,
+		Attempt:    attempt,
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	start := time.Now()
+	status, err := d.post(ctx, hook, payload)
+	delivery.DurationMs = int(time.Since(start).Milliseconds())
+	if status != 0 {
+		delivery.StatusCode = &status
+	}
+	switch {
+	case err != nil:
+		delivery.Error = err.Error()
+	case status < 200 || status > 299:
+		delivery.Error = fmt.Sprintf("unexpected response status %d", status)
+	default:
+		delivery.Success = true
+	}
+
+	if !delivery.Success {
+		log.Printf("Webhook %s delivery of %s event %s failed (attempt %d): %s", hook.ID, payload.Event, payload.ID, attempt, delivery.Error)
+	}
+
+	// Recorded even if the request's context ended
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := d.store.RecordWebhookDelivery(recordCtx, delivery); err != nil {
+		log.Printf("Error recording webhook delivery %s: %v", delivery.ID, err)
+	}
+	return delivery
+}
+
+// post sends a signed payload, returning the response status
+func (d *Dispatcher) post(ctx context.Context, hook models.Webhook, payload models.WebhookPayload) (int, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Nous-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, payload.Event)
+	req.Header.Set(HeaderDelivery, payload.ID.String())
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(hook.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Drain a little of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	return resp.StatusCode, nil
+}
+
+// Sign returns the X-Nous-Signature of a body sent at timestamp (unix
+// seconds): "sha256=" and the hex HMAC-SHA256 of "<timestamp>.<body>"
+// keyed with the webhook's secret
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateSecret returns a new random signing secret
+func GenerateSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// Retryable reports whether a failed delivery may succeed later: the
+// webhook didn't respond, failed on its side or asked to slow down
+func Retryable(delivery models.WebhookDelivery) bool {
+	if delivery.StatusCode == nil {
+		return true
+	}
+	status := *delivery.StatusCode
+	return status >= 500 || status == http.StatusTooManyRequests || status == http.StatusRequestTimeout
+}
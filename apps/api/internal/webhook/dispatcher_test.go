@@ -0,0 +1,303 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+// fakeStore serves one webhook and keeps the recorded deliveries
+type fakeStore struct {
+	hook models.Webhook
+
+	mu         sync.Mutex
+	deliveries []models.WebhookDelivery
+}
+
+func (s *fakeStore) ListWebhooks(ctx context.Context, projectID uuid.UUID) ([]models.Webhook, error) {
+	if projectID != s.hook.ProjectID {
+		return nil, nil
+	}
+	return []models.Webhook{s.hook}, nil
+}
+
+func (s *fakeStore) RecordWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries = append(s.deliveries, delivery)
+	return nil
+}
+
+// recorded returns a copy of the recorded deliveries
+func (s *fakeStore) recorded() []models.WebhookDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.WebhookDelivery(nil), s.deliveries...)
+}
+
+// waitForDeliveries waits until at least n deliveries were recorded
+func (s *fakeStore) waitForDeliveries(t *testing.T, n int) []models.WebhookDelivery {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if deliveries := s.recorded(); len(deliveries) >= n {
+			return deliveries
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("recorded %d deliveries, want %d", len(s.recorded()), n)
+	return nil
+}
+
+// newHook returns an enabled webhook posting to url
+func newHook(url string) models.Webhook {
+	return models.Webhook{
+		ID:        uuid.New(),
+		ProjectID: uuid.New(),
+		Name:      "Test",
+		URL:       url,
+		Secret:    "whsec_test",
+		Enabled:   true,
+	}
+}
+
+func TestSign(t *testing.T) {
+	got := Sign("whsec_test", "1700000000", []byte(`{"event":"webhook.test"}`))
+	want := "sha256=1d83e338c8b0156315e4b02e94f96d83e4260098aa05dead2a856d36245f342f"
+	if got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+
+	if Sign("whsec_other", "1700000000", []byte(`{"event":"webhook.test"}`)) == want {
+		t.Error("signature doesn't depend on the secret")
+	}
+	if Sign("whsec_test", "1700000001", []byte(`{"event":"webhook.test"}`)) == want {
+		t.Error("signature doesn't depend on the timestamp")
+	}
+}
+
+func TestDeliverSendsSignedRequest(t *testing.T) {
+	var (
+		header http.Header
+		body   []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	store := &fakeStore{}
+	hook := newHook(server.URL)
+	payload := NewPayload(hook.ProjectID, models.WebhookEventTest, map[string]string{"message": "hello"})
+
+	delivery := New(store, DefaultConfig()).Deliver(context.Background(), hook, payload, 1)
+	if !delivery.Success {
+		t.Fatalf("delivery failed: %s", delivery.Error)
+	}
+
+	if got := header.Get(HeaderEvent); got != models.WebhookEventTest {
+		t.Errorf("%s = %q, want %q", HeaderEvent, got, models.WebhookEventTest)
+	}
+	if got := header.Get(HeaderDelivery); got != payload.ID.String() {
+		t.Errorf("%s = %q, want %q", HeaderDelivery, got, payload.ID)
+	}
+	timestamp := header.Get(HeaderTimestamp)
+	if sent, err := strconv.ParseInt(timestamp, 10, 64); err != nil || time.Since(time.Unix(sent, 0)) > time.Minute {
+		t.Errorf("%s = %q, want the current unix time", HeaderTimestamp, timestamp)
+	}
+	if got, want := header.Get(HeaderSignature), Sign(hook.Secret, timestamp, body); got != want {
+		t.Errorf("%s = %q, want %q", HeaderSignature, got, want)
+	}
+	if got := header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+
+	var sent models.WebhookPayload
+	if err := json.Unmarshal(body, &sent); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+	if sent.ID != payload.ID || sent.Event != payload.Event || sent.ProjectID != hook.ProjectID {
+		t.Errorf("body = %+v, want payload %+v", sent, payload)
+	}
+}
+
+func TestDeliverRecordsAttempts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	store := &fakeStore{}
+	hook := newHook(server.URL)
+	payload := NewPayload(hook.ProjectID, models.WebhookEventTest, nil)
+	dispatcher := New(store, DefaultConfig())
+
+	dispatcher.Deliver(context.Background(), hook, payload, 2)
+
+	// A webhook that can't be reached is recorded without a status
+	unreachable := newHook("http://127.0.0.1:1")
+	dispatcher.Deliver(context.Background(), unreachable, payload, 1)
+
+	deliveries := store.recorded()
+	if len(deliveries) != 2 {
+		t.Fatalf("recorded %d deliveries, want 2", len(deliveries))
+	}
+
+	failed := deliveries[0]
+	if failed.WebhookID != hook.ID || failed.ProjectID != hook.ProjectID || failed.DeliveryID != payload.ID {
+		t.Errorf("delivery = %+v, want one of payload %s to webhook %s", failed, payload.ID, hook.ID)
+	}
+	if failed.Attempt != 2 || failed.Success || failed.StatusCode == nil || *failed.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("delivery = %+v, want a failed second attempt with status 503", failed)
+	}
+	if failed.Error == "" {
+		t.Error("failed delivery has no error")
+	}
+
+	if deliveries[1].StatusCode != nil || deliveries[1].Error == "" || deliveries[1].Success {
+		t.Errorf("delivery = %+v, want a failure without status", deliveries[1])
+	}
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		status   int
+		attempts int
+	}{
+		{http.StatusOK, 1},
+		{http.StatusInternalServerError, 3},
+		{http.StatusBadGateway, 3},
+		{http.StatusTooManyRequests, 3},
+		{http.StatusBadRequest, 1},
+		{http.StatusUnauthorized, 1},
+		{http.StatusNotFound, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.status), func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				requests int
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				requests++
+				mu.Unlock()
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			store := &fakeStore{hook: newHook(server.URL)}
+			dispatcher := New(store, Config{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond})
+			go dispatcher.Run()
+
+			dispatcher.Notify(store.hook.ProjectID, models.WebhookEventAlertFiring, nil)
+			deliveries := store.waitForDeliveries(t, tt.attempts)
+
+			// Leave time for a retry that shouldn't happen
+			time.Sleep(50 * time.Millisecond)
+			if err := dispatcher.Close(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			deliveries = store.recorded()
+			if len(deliveries) != tt.attempts {
+				t.Fatalf("recorded %d deliveries, want %d", len(deliveries), tt.attempts)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if requests != tt.attempts {
+				t.Errorf("webhook received %d requests, want %d", requests, tt.attempts)
+			}
+			for i, delivery := range deliveries {
+				if delivery.Attempt != i+1 {
+					t.Errorf("deliveries[%d].attempt = %d, want %d", i, delivery.Attempt, i+1)
+				}
+				if delivery.DeliveryID != deliveries[0].DeliveryID {
+					t.Errorf("deliveries[%d] is of payload %s, want retries of %s", i, delivery.DeliveryID, deliveries[0].DeliveryID)
+				}
+			}
+		})
+	}
+}
+
+func TestNotifySkipsUnwantedEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	hook := newHook(server.URL)
+	hook.Events = []string{models.WebhookEventBudgetExceeded}
+	store := &fakeStore{hook: hook}
+	dispatcher := New(store, DefaultConfig())
+	go dispatcher.Run()
+
+	dispatcher.Notify(hook.ProjectID, models.WebhookEventAlertFiring, nil)
+	dispatcher.Notify(hook.ProjectID, models.WebhookEventBudgetExceeded, nil)
+	deliveries := store.waitForDeliveries(t, 1)
+
+	time.Sleep(50 * time.Millisecond)
+	dispatcher.Close(context.Background())
+	if deliveries = store.recorded(); len(deliveries) != 1 || deliveries[0].Event != models.WebhookEventBudgetExceeded {
+		t.Errorf("deliveries = %+v, want only the budget.exceeded event", deliveries)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	dispatcher := New(&fakeStore{}, Config{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second})
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, backoff := range want {
+		if got := dispatcher.Backoff(i + 1); got != backoff {
+			t.Errorf("Backoff(%d) = %s, want %s", i+1, got, backoff)
+		}
+	}
+
+	// Many attempts stay at the cap rather than overflowing
+	if got := dispatcher.Backoff(100); got != 5*time.Second {
+		t.Errorf("Backoff(100) = %s, want 5s", got)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		status *int
+		want   bool
+	}{
+		{nil, true},
+		{statusPtr(http.StatusInternalServerError), true},
+		{statusPtr(http.StatusServiceUnavailable), true},
+		{statusPtr(http.StatusTooManyRequests), true},
+		{statusPtr(http.StatusRequestTimeout), true},
+		{statusPtr(http.StatusBadRequest), false},
+		{statusPtr(http.StatusForbidden), false},
+		{statusPtr(http.StatusGone), false},
+		{statusPtr(http.StatusMovedPermanently), false},
+	}
+
+	for _, tt := range tests {
+		if got := Retryable(models.WebhookDelivery{StatusCode: tt.status}); got != tt.want {
+			status := "none"
+			if tt.status != nil {
+				status = strconv.Itoa(*tt.status)
+			}
+			t.Errorf("Retryable(status %s) = %v, want %v", status, got, tt.want)
+		}
+	}
+}
+
+func statusPtr(status int) *int {
+	return &status
+}
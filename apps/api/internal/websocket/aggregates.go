@@ -0,0 +1,281 @@
+package websocket
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+const (
+	// MessageTypeAggregates is the type of the periodic messages carrying a
+	// project's rolling aggregates
+	MessageTypeAggregates = "aggregates"
+
+	// DefaultAggregateInterval is how often aggregates are pushed unless
+	// configured
+	DefaultAggregateInterval = 5 * time.Second
+
+	// Width of the slots calls are counted in. Windows advance in steps of
+	// one slot.
+	slotWidth = 10 * time.Second
+
+	// Slots kept per tool, covering the longest window
+	slotCount = int(15 * time.Minute / slotWidth)
+
+	// Latency histogram resolution: bucket bounds grow by a factor of
+	// 2^(1/4) (about 19%), up to 2^24ms
+	binsPerDoubling = 4
+	latencyBins     = 24*binsPerDoubling + 1
+
+	// Tools tracked per project. Calls of further tools only count towards
+	// the project totals.
+	maxAggregateTools = 500
+)
+
+// aggregateWindows are the rolling windows aggregates are reported over
+var aggregateWindows = []struct {
+	name   string
+	length time.Duration
+}{
+	{"1m", time.Minute},
+	{"5m", 5 * time.Minute},
+	{"15m", 15 * time.Minute},
+}
+
+// AggregateStats summarizes the tool calls of a rolling window
+type AggregateStats struct {
+	Calls       int     `json:"calls"`
+	CallsPerSec float64 `json:"calls_per_sec"`
+	FailureRate float64 `json:"failure_rate"` // percent
+	P95Ms       float64 `json:"p95_ms"`       // successful calls, approximate
+}
+
+// WindowAggregates are a window's totals and per-tool stats
+type WindowAggregates struct {
+	AggregateStats
+	Tools map[string]AggregateStats `json:"tools"`
+}
+
+// Aggregates is the data of an aggregates message, keyed by window
+// ("1m", "5m", "15m")
+type Aggregates struct {
+	At      time.Time                   `json:"at"`
+	Windows map[string]WindowAggregates `json:"windows"`
+}
+
+// slot counts the calls of one slotWidth interval
+type slot struct {
+	n        int64 // slot number (Unix time / slotWidth) the counts belong to
+	calls    int
+	failures int
+	latency  *[latencyBins]uint32 // successful calls, allocated on first use
+}
+
+// rollingStats counts calls in a ring of slots covering the longest window
+type rollingStats struct {
+	slots [slotCount]slot
+	last  int64 // most recent slot number recorded
+}
+
+// record counts a call in slot n
+func (s *rollingStats) record(n int64, failed bool, durationMs int) {
+	sl := &s.slots[n%int64(slotCount)]
+	if sl.n != n {
+		*sl = slot{n: n, latency: sl.latency}
+		if sl.latency != nil {
+			clear(sl.latency[:])
+		}
+	}
+
+	sl.calls++
+	if failed {
+		sl.failures++
+	} else {
+		if sl.latency == nil {
+			sl.latency = new([latencyBins]uint32)
+		}
+		sl.latency[latencyBin(durationMs)]++
+	}
+
+	if n > s.last {
+		s.last = n
+	}
+}
+
+// window aggregates slots first through current, which cover seconds
+func (s *rollingStats) window(first, current int64, seconds float64) AggregateStats {
+	var stats AggregateStats
+	var failures int
+	var latency [latencyBins]uint32
+	for n := first; n <= current; n++ {
+		sl := &s.slots[n%int64(slotCount)]
+		if sl.n != n {
+			continue
+		}
+		stats.Calls += sl.calls
+		failures += sl.failures
+		if sl.latency != nil {
+			for i, count := range sl.latency {
+				latency[i] += count
+			}
+		}
+	}
+
+	if stats.Calls > 0 {
+		stats.CallsPerSec = round(float64(stats.Calls)/seconds, 3)
+		stats.FailureRate = round(float64(failures)/float64(stats.Calls)*100, 2)
+	}
+	stats.P95Ms = percentile(&latency, 0.95)
+	return stats
+}
+
+// projectAggregates are the rolling stats of one project
+type projectAggregates struct {
+	total rollingStats
+	tools map[string]*rollingStats
+}
+
+// aggregator computes rolling aggregates incrementally from broadcast tool
+// calls, so live dashboards don't have to poll the database
+type aggregator struct {
+	mu       sync.Mutex
+	projects map[uuid.UUID]*projectAggregates
+}
+
+func newAggregator() *aggregator {
+	return &aggregator{projects: make(map[uuid.UUID]*projectAggregates)}
+}
+
+// record counts a tool call at its timestamp, or now if it has none. Calls
+// older than the longest window are ignored.
+func (a *aggregator) record(project uuid.UUID, event *models.ToolCallEvent, now time.Time) {
+	at := now
+	if event.Timestamp != nil && event.Timestamp.Before(now) {
+		at = *event.Timestamp
+	}
+	n := slotNumber(at)
+	if n <= slotNumber(now)-int64(slotCount) {
+		return
+	}
+	failed := event.Status == "failed"
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	p, ok := a.projects[project]
+	if !ok {
+		p = &projectAggregates{tools: make(map[string]*rollingStats)}
+		a.projects[project] = p
+	}
+	p.total.record(n, failed, event.DurationMs)
+
+	tool, ok := p.tools[event.ToolName]
+	if !ok {
+		if len(p.tools) >= maxAggregateTools {
+			return
+		}
+		tool = new(rollingStats)
+		p.tools[event.ToolName] = tool
+	}
+	tool.record(n, failed, event.DurationMs)
+}
+
+// snapshot returns a project's aggregates as of now. Projects without
+// recent calls report zeros.
+func (a *aggregator) snapshot(project uuid.UUID, now time.Time) Aggregates {
+	snap := Aggregates{At: now.UTC(), Windows: make(map[string]WindowAggregates, len(aggregateWindows))}
+	current := slotNumber(now)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	p := a.projects[project]
+
+	for _, w := range aggregateWindows {
+		// The window starts at the slot boundary and includes the current,
+		// partial slot
+		first := current - int64(w.length/slotWidth) + 1
+		seconds := now.Sub(time.Unix(first*int64(slotWidth/time.Second), 0)).Seconds()
+
+		agg := WindowAggregates{Tools: make(map[string]AggregateStats)}
+		if p != nil {
+			agg.AggregateStats = p.total.window(first, current, seconds)
+			for name, tool := range p.tools {
+				if tool.last >= first {
+					agg.Tools[name] = tool.window(first, current, seconds)
+				}
+			}
+		}
+		snap.Windows[w.name] = agg
+	}
+	return snap
+}
+
+// prune forgets tools and projects without calls in the longest window
+func (a *aggregator) prune(now time.Time) {
+	oldest := slotNumber(now) - int64(slotCount) + 1
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for id, p := range a.projects {
+		if p.total.last < oldest {
+			delete(a.projects, id)
+			continue
+		}
+		for name, tool := range p.tools {
+			if tool.last < oldest {
+				delete(p.tools, name)
+			}
+		}
+	}
+}
+
+// slotNumber returns the number of the slot containing t
+func slotNumber(t time.Time) int64 {
+	return t.Unix() / int64(slotWidth/time.Second)
+}
+
+// latencyBin returns the histogram bin of a duration. Bin i holds durations
+// up to 2^(i/binsPerDoubling) ms.
+func latencyBin(durationMs int) int {
+	if durationMs <= 1 {
+		return 0
+	}
+	bin := int(math.Ceil(math.Log2(float64(durationMs)) * binsPerDoubling))
+	return min(bin, latencyBins-1)
+}
+
+// percentile estimates the p-th percentile of a latency histogram as the
+// geometric middle of the bin it falls in
+func percentile(latency *[latencyBins]uint32, p float64) float64 {
+	var total uint64
+	for _, count := range latency {
+		total += uint64(count)
+	}
+	if total == 0 {
+		return 0
+	}
+
+	rank := uint64(math.Ceil(p * float64(total)))
+	var seen uint64
+	for i, count := range latency {
+		seen += uint64(count)
+		if seen >= rank {
+			if i == 0 {
+				return 1
+			}
+			return round(math.Exp2((float64(i)-0.5)/binsPerDoubling), 1)
+		}
+	}
+	return 0
+}
+
+// round rounds x to the given number of decimal places
+func round(x float64, places int) float64 {
+	scale := math.Pow10(places)
+	return math.Round(x*scale) / scale
+}
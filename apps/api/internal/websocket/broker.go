@@ -0,0 +1,72 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Envelope is a broadcast message as it travels between API replicas
+type Envelope struct {
+	ProjectID uuid.UUID       `json:"project_id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Broker distributes broadcast messages to every hub subscribed to it, so
+// an event ingested by one API replica reaches the clients of all replicas
+type Broker interface {
+	// Publish sends a message to all subscribed hubs, including the
+	// publisher's own
+	Publish(ctx context.Context, env Envelope) error
+
+	// Subscribe registers handle to receive every published message. It
+	// returns once the subscription is active.
+	Subscribe(ctx context.Context, handle func(Envelope)) error
+
+	// Close stops delivering messages
+	Close() error
+}
+
+// LocalBroker delivers messages within the process. It's the default for
+// single-instance deployments.
+type LocalBroker struct {
+	mu       sync.RWMutex
+	handlers []func(Envelope)
+}
+
+// NewLocalBroker creates an in-process broker
+func NewLocalBroker() *LocalBroker {
+	return &LocalBroker{}
+}
+
+// Publish hands the message to every subscriber synchronously
+func (b *LocalBroker) Publish(ctx context.Context, env Envelope) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, handle := range b.handlers {
+		handle(env)
+	}
+	return nil
+}
+
+// Subscribe registers a handler
+func (b *LocalBroker) Subscribe(ctx context.Context, handle func(Envelope)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers = append(b.handlers, handle)
+	return nil
+}
+
+// Close removes all subscribers
+func (b *LocalBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers = nil
+	return nil
+}
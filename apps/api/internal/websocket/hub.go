@@ -1,13 +1,18 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+
+	"github.com/yourorg/nous/internal/models"
 )
 
 const (
@@ -58,7 +63,22 @@ type Client struct {
 	hub  *Hub
 	conn *websocket.Conn
 	send chan []byte
-	mu   sync.Mutex
+
+	// Guards send against being closed while replies are queued, and the
+	// client's subscriptions
+	mu     sync.Mutex
+	closed bool
+
+	// Project whose messages the client receives
+	project uuid.UUID
+
+	// Active subscriptions by ID. Without subscriptions the client
+	// receives every tool call.
+	subscriptions map[string]Filter
+
+	// Sequence number after which missed messages are replayed on
+	// registration, if the client is resuming
+	resumeFrom *uint64
 }
 
 // Hub maintains the set of active clients and broadcasts messages to clients
@@ -66,8 +86,18 @@ type Hub struct {
 	// Registered clients
 	clients map[*Client]bool
 
-	// Inbound messages from clients
-	broadcast chan []byte
+	// Recent messages, delivered to clients by Run and replayed to
+	// resuming clients
+	replay *replayBuffer
+
+	// Signals Run that messages were appended to replay
+	notify chan struct{}
+
+	// Distributes broadcasts to the hubs of all API replicas
+	broker Broker
+
+	// Sequence number of the last message delivered by Run
+	delivered uint64
 
 	// Register requests from clients
 	register chan *Client
@@ -75,27 +105,110 @@ type Hub struct {
 	// Unregister requests from clients
 	unregister chan *Client
 
+	// Checks connection requests before they are upgraded
+	authorize Authorizer
+
+	// Rolling aggregates of broadcast tool calls, pushed to clients every
+	// aggregateInterval
+	aggregates        *aggregator
+	aggregateInterval time.Duration
+
 	mu sync.RWMutex
 }
 
-// Message represents a WebSocket message
+// Authorizer decides whether a connection request may be upgraded and
+// which project's messages the client receives. A non-nil error rejects
+// the request; the authorizer writes the response.
+type Authorizer func(w http.ResponseWriter, r *http.Request) (uuid.UUID, error)
+
+// MessageTypeToolCall is the type of messages carrying an ingested tool
+// call, which are filtered by client subscriptions
+const MessageTypeToolCall = "tool_call"
+
+// MessageTypeBudgetExceeded is the type of messages signalling that a
+// budget's usage reached its limit
+const MessageTypeBudgetExceeded = "budget_exceeded"
+
+// MessageTypeAlert is the type of messages signalling a state change of an
+// alert rule
+const MessageTypeAlert = "alert"
+
+// projectMessage is an encoded message addressed to one project's clients
+type projectMessage struct {
+	seq     uint64
+	project uuid.UUID
+	data    []byte
+
+	// Tool call the message carries, matched against client subscriptions
+	event *models.ToolCallEvent
+}
+
+// Message represents a WebSocket message. Broadcast messages carry an
+// increasing sequence number clients can resume from after reconnecting;
+// replies to a single client have none.
 type Message struct {
+	Seq  uint64      `json:"seq,omitempty"`
 	Type string      `json:"type"`
 	Data interface{} `json:"data"`
 }
 
-// NewHub creates a new WebSocket hub
+// NewHub creates a new WebSocket hub keeping DefaultReplaySize messages
+// for resuming clients
 func NewHub() *Hub {
-	return &Hub{
+	return NewHubWithReplay(DefaultReplaySize)
+}
+
+// NewHubWithReplay creates a new WebSocket hub keeping the last replaySize
+// messages for resuming clients
+func NewHubWithReplay(replaySize int) *Hub {
+	// Sequence numbers start at the current time, so cursors handed out
+	// before a restart are recognized as out of range rather than reused
+	first := uint64(time.Now().UnixMicro())
+
+	h := &Hub{
 		clients:    make(map[*Client]bool),
-		broadcast:  make(chan []byte),
+		replay:     newReplayBuffer(replaySize, first),
+		notify:     make(chan struct{}, 1),
+		delivered:  first - 1,
+		broker:     NewLocalBroker(),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
+
+		aggregates:        newAggregator(),
+		aggregateInterval: DefaultAggregateInterval,
+	}
+	h.broker.Subscribe(context.Background(), h.dispatch)
+
+	return h
+}
+
+// SetBroker replaces the in-process broker, e.g. with a RedisBroker so
+// broadcasts reach clients connected to other replicas. Call it before
+// broadcasting.
+func (h *Hub) SetBroker(ctx context.Context, broker Broker) error {
+	if err := broker.Subscribe(ctx, h.dispatch); err != nil {
+		return err
 	}
+	h.broker.Close()
+	h.broker = broker
+	return nil
+}
+
+// SetAggregateInterval sets how often clients receive aggregates messages;
+// zero disables them. Call it before Run.
+func (h *Hub) SetAggregateInterval(interval time.Duration) {
+	h.aggregateInterval = interval
 }
 
 // Run starts the hub's main loop
 func (h *Hub) Run() {
+	var aggregateTick <-chan time.Time
+	if h.aggregateInterval > 0 {
+		ticker := time.NewTicker(h.aggregateInterval)
+		defer ticker.Stop()
+		aggregateTick = ticker.C
+	}
+
 	for {
 		select {
 		case client := <-h.register:
@@ -104,50 +217,175 @@ func (h *Hub) Run() {
 			h.mu.Unlock()
 			log.Printf("WebSocket client connected. Total clients: %d", len(h.clients))
 
+			if client.resumeFrom != nil {
+				h.resume(client, *client.resumeFrom)
+			}
+
 		case client := <-h.unregister:
 			h.mu.Lock()
 			if _, ok := h.clients[client]; ok {
 				delete(h.clients, client)
-				close(client.send)
+				client.close()
 			}
 			h.mu.Unlock()
 			log.Printf("WebSocket client disconnected. Total clients: %d", len(h.clients))
 
-		case message := <-h.broadcast:
-			h.mu.RLock()
-			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					close(client.send)
-					delete(h.clients, client)
-				}
+		case <-h.notify:
+			messages, complete := h.replay.since(h.delivered, h.replay.latest())
+			if !complete && len(messages) > 0 {
+				log.Printf("WebSocket hub fell behind, %d messages were not delivered", messages[0].seq-h.delivered-1)
 			}
-			h.mu.RUnlock()
+
+			h.mu.Lock()
+			for _, message := range messages {
+				h.deliver(message)
+			}
+			h.mu.Unlock()
+
+			if n := len(messages); n > 0 {
+				h.delivered = messages[n-1].seq
+			}
+
+		case now := <-aggregateTick:
+			h.pushAggregates(now)
 		}
 	}
 }
 
-// BroadcastMessage sends a message to all connected clients
-func (h *Hub) BroadcastMessage(eventType string, data interface{}) {
-	message := Message{
-		Type: eventType,
-		Data: data,
+// deliver sends a message to the matching clients, dropping clients that
+// can't keep up. h.mu must be held.
+func (h *Hub) deliver(message projectMessage) {
+	for client := range h.clients {
+		if client.project != message.project || !client.wants(message.event) {
+			continue
+		}
+		if !client.trySend(message.data) {
+			client.close()
+			delete(h.clients, client)
+		}
 	}
+}
 
-	jsonData, err := json.Marshal(message)
+// pushAggregates sends every client its project's current aggregates.
+// Aggregates messages carry no seq and aren't replayed.
+func (h *Hub) pushAggregates(now time.Time) {
+	h.aggregates.prune(now)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	encoded := make(map[uuid.UUID][]byte)
+	for client := range h.clients {
+		data, ok := encoded[client.project]
+		if !ok {
+			var err error
+			data, err = json.Marshal(Message{Type: MessageTypeAggregates, Data: h.aggregates.snapshot(client.project, now)})
+			if err != nil {
+				log.Printf("Error marshaling WebSocket aggregates: %v", err)
+				return
+			}
+			encoded[client.project] = data
+		}
+		if !client.trySend(data) {
+			client.close()
+			delete(h.clients, client)
+		}
+	}
+}
+
+// resume replays the messages a reconnecting client missed after seq and
+// that were already delivered live, before it receives anything new. A
+// replay_truncated message warns when some of them are no longer buffered.
+// Nothing is replayed for cursors this hub didn't issue (another replica's,
+// or from before a restart): their position in the buffer is unknown, so
+// replaying it would duplicate messages the client already has.
+func (h *Hub) resume(client *Client, seq uint64) {
+	var messages []projectMessage
+	complete := false
+	if h.replay.issued(seq) {
+		messages, complete = h.replay.since(seq, h.delivered)
+	}
+	if !complete {
+		client.reply("replay_truncated", map[string]uint64{
+			"resume_from": seq,
+			"oldest_seq":  h.replay.oldest(),
+		})
+	}
+
+	replayed := 0
+	for _, message := range messages {
+		if message.project != client.project || !client.wants(message.event) {
+			continue
+		}
+		if !client.trySend(message.data) {
+			break
+		}
+		replayed++
+	}
+
+	client.reply("replay_complete", map[string]interface{}{
+		"replayed": replayed,
+		"seq":      h.delivered,
+	})
+}
+
+// BroadcastMessage publishes a message for the clients connected to a
+// project on every replica. Tool call events only reach clients whose
+// subscriptions match them.
+func (h *Hub) BroadcastMessage(projectID uuid.UUID, eventType string, data interface{}) {
+	encoded, err := json.Marshal(data)
 	if err != nil {
 		log.Printf("Error marshaling WebSocket message: %v", err)
 		return
 	}
 
+	env := Envelope{ProjectID: projectID, Type: eventType, Data: encoded}
+	if err := h.broker.Publish(context.Background(), env); err != nil {
+		log.Printf("Error publishing WebSocket message: %v", err)
+	}
+}
+
+// dispatch queues a message received from the broker for delivery to this
+// hub's clients
+func (h *Hub) dispatch(env Envelope) {
+	pm := projectMessage{project: env.ProjectID}
+	if env.Type == MessageTypeToolCall {
+		var event models.ToolCallEvent
+		if err := json.Unmarshal(env.Data, &event); err == nil {
+			pm.event = &event
+			h.aggregates.record(env.ProjectID, &event, time.Now())
+		}
+	}
+
+	err := h.replay.append(pm, func(seq uint64) ([]byte, error) {
+		return json.Marshal(Message{Seq: seq, Type: env.Type, Data: env.Data})
+	})
+	if err != nil {
+		log.Printf("Error marshaling WebSocket message: %v", err)
+		return
+	}
+
+	// Run picks up everything appended since its last delivery
 	select {
-	case h.broadcast <- jsonData:
+	case h.notify <- struct{}{}:
 	default:
-		log.Printf("WebSocket broadcast channel full, dropping message")
 	}
 }
 
+// PingBroker checks the broker's connection, for brokers that have one
+func (h *Hub) PingBroker(ctx context.Context) error {
+	if pinger, ok := h.broker.(interface{ Ping(context.Context) error }); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// SetAuthorizer requires connection requests to pass authorize before they
+// are upgraded
+func (h *Hub) SetAuthorizer(authorize Authorizer) {
+	h.authorize = authorize
+}
+
 // GetClientCount returns the number of connected clients
 func (h *Hub) GetClientCount() int {
 	h.mu.RLock()
@@ -155,20 +393,33 @@ func (h *Hub) GetClientCount() int {
 	return len(h.clients)
 }
 
-// ServeWS handles WebSocket requests from clients
+// ServeWS handles WebSocket requests from clients. A resume_from query
+// parameter replays the buffered messages after that seq before the client
+// goes live.
 func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.authorizeClient(w, r)
+	if !ok {
+		return
+	}
+
+	// Clients reconnecting pass the seq of the last message they received
+	var resumeFrom *uint64
+	if value := r.URL.Query().Get("resume_from"); value != "" {
+		seq, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			http.Error(w, "resume_from must be a message seq", http.StatusBadRequest)
+			return
+		}
+		resumeFrom = &seq
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("WebSocket upgrade error: %v", err)
 		return
 	}
 
-	client := &Client{
-		hub:  h,
-		conn: conn,
-		send: make(chan []byte, 256),
-	}
-
+	client := h.newClient(conn, project, resumeFrom)
 	client.hub.register <- client
 
 	// Start goroutines for reading and writing
@@ -176,7 +427,42 @@ func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request) {
 	go client.readPump()
 }
 
-// readPump pumps messages from the WebSocket connection to the hub
+// authorizeClient runs the authorizer on a connection request, returning
+// the project the client receives messages for. On failure the response
+// has been written.
+func (h *Hub) authorizeClient(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	if h.authorize == nil {
+		return models.DefaultProjectID, true
+	}
+
+	project, err := h.authorize(w, r)
+	if err != nil {
+		log.Printf("Live stream connection rejected: %v", err)
+		return uuid.Nil, false
+	}
+	return project, true
+}
+
+// newClient creates a client for a project. conn is nil for clients not
+// connected over WebSocket.
+func (h *Hub) newClient(conn *websocket.Conn, project uuid.UUID, resumeFrom *uint64) *Client {
+	sendBuffer := 256
+	if resumeFrom != nil {
+		// Room for the replay on top of live traffic
+		sendBuffer += h.replay.capacity()
+	}
+
+	return &Client{
+		hub:           h,
+		conn:          conn,
+		send:          make(chan []byte, sendBuffer),
+		project:       project,
+		subscriptions: make(map[string]Filter),
+		resumeFrom:    resumeFrom,
+	}
+}
+
+// readPump reads subscription messages from the WebSocket connection
 func (c *Client) readPump() {
 	defer func() {
 		c.hub.unregister <- c
@@ -191,13 +477,14 @@ func (c *Client) readPump() {
 	})
 
 	for {
-		_, _, err := c.conn.ReadMessage()
+		_, data, err := c.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("WebSocket error: %v", err)
 			}
 			break
 		}
+		c.handleMessage(data)
 	}
 }
 
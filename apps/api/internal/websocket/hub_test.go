@@ -0,0 +1,168 @@
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// newDeliveredHub returns a hub keeping size messages to which n messages
+// of project were broadcast and delivered, with the seq of the first one
+func newDeliveredHub(t *testing.T, size, n int, project uuid.UUID) (*Hub, uint64) {
+	t.Helper()
+
+	h := NewHubWithReplay(size)
+	first := h.replay.latest() + 1
+	for i := 0; i < n; i++ {
+		h.dispatch(Envelope{ProjectID: project, Type: MessageTypeBudgetExceeded, Data: json.RawMessage(`{}`)})
+	}
+	h.delivered = h.replay.latest()
+	return h, first
+}
+
+// received decodes the messages queued for a client
+func received(t *testing.T, client *Client) []Message {
+	t.Helper()
+
+	var messages []Message
+	for {
+		select {
+		case data := <-client.send:
+			var message Message
+			if err := json.Unmarshal(data, &message); err != nil {
+				t.Fatalf("decoding message: %v", err)
+			}
+			messages = append(messages, message)
+		default:
+			return messages
+		}
+	}
+}
+
+func TestResume(t *testing.T) {
+	project := uuid.New()
+
+	// 8 messages through a buffer of 5 evict the first 3
+	const size, sent = 5, 8
+
+	tests := []struct {
+		name string
+
+		// Cursor to resume from, given the seq of the first message
+		cursor func(first uint64) uint64
+
+		truncated bool
+		replayed  []uint64 // relative to the first message
+	}{
+		{
+			name:   "at head",
+			cursor: func(first uint64) uint64 { return first + sent - 1 },
+		},
+		{
+			name:     "inside the buffer",
+			cursor:   func(first uint64) uint64 { return first + 5 },
+			replayed: []uint64{6, 7},
+		},
+		{
+			name:     "just before the oldest buffered",
+			cursor:   func(first uint64) uint64 { return first + 2 },
+			replayed: []uint64{3, 4, 5, 6, 7},
+		},
+		{
+			name:      "past eviction",
+			cursor:    func(first uint64) uint64 { return first },
+			truncated: true,
+			replayed:  []uint64{3, 4, 5, 6, 7},
+		},
+		{
+			name:      "before any message",
+			cursor:    func(first uint64) uint64 { return first - 1 },
+			truncated: true,
+			replayed:  []uint64{3, 4, 5, 6, 7},
+		},
+		{
+			name:      "in the future",
+			cursor:    func(first uint64) uint64 { return first + sent + 10 },
+			truncated: true,
+		},
+		{
+			name:      "from another hub",
+			cursor:    func(first uint64) uint64 { return 42 },
+			truncated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, first := newDeliveredHub(t, size, sent, project)
+			cursor := tt.cursor(first)
+			client := h.newClient(nil, project, &cursor)
+
+			h.resume(client, cursor)
+			messages := received(t, client)
+
+			if tt.truncated {
+				if len(messages) == 0 || messages[0].Type != "replay_truncated" {
+					t.Fatalf("messages = %+v, want replay_truncated first", messages)
+				}
+				data := messages[0].Data.(map[string]interface{})
+				if uint64(data["resume_from"].(float64)) != cursor {
+					t.Errorf("replay_truncated = %v, want resume_from %d", data, cursor)
+				}
+				messages = messages[1:]
+			}
+
+			if len(messages) != len(tt.replayed)+1 {
+				t.Fatalf("received %d messages, want %d replayed and replay_complete: %+v", len(messages), len(tt.replayed), messages)
+			}
+			for i, offset := range tt.replayed {
+				if messages[i].Seq != first+offset {
+					t.Errorf("replayed[%d].seq = %d, want %d", i, messages[i].Seq, first+offset)
+				}
+			}
+
+			complete := messages[len(messages)-1]
+			if complete.Type != "replay_complete" {
+				t.Fatalf("last message = %+v, want replay_complete", complete)
+			}
+			data := complete.Data.(map[string]interface{})
+			if int(data["replayed"].(float64)) != len(tt.replayed) {
+				t.Errorf("replay_complete = %v, want %d replayed", data, len(tt.replayed))
+			}
+		})
+	}
+}
+
+func TestResumeSkipsOtherProjects(t *testing.T) {
+	project := uuid.New()
+	h, first := newDeliveredHub(t, 10, 2, project)
+	h.dispatch(Envelope{ProjectID: uuid.New(), Type: MessageTypeBudgetExceeded, Data: json.RawMessage(`{}`)})
+	h.delivered = h.replay.latest()
+
+	cursor := first - 1
+	client := h.newClient(nil, project, &cursor)
+	h.resume(client, cursor)
+
+	messages := received(t, client)
+	if len(messages) != 3 || messages[0].Seq != first || messages[1].Seq != first+1 {
+		t.Errorf("messages = %+v, want the project's 2 messages and replay_complete", messages)
+	}
+}
+
+func TestResumeOnlyReplaysDelivered(t *testing.T) {
+	project := uuid.New()
+	h, first := newDeliveredHub(t, 10, 2, project)
+
+	// Appended but not delivered yet: the client receives it live
+	h.dispatch(Envelope{ProjectID: project, Type: MessageTypeBudgetExceeded, Data: json.RawMessage(`{}`)})
+
+	cursor := first
+	client := h.newClient(nil, project, &cursor)
+	h.resume(client, cursor)
+
+	messages := received(t, client)
+	if len(messages) != 2 || messages[0].Seq != first+1 || messages[1].Type != "replay_complete" {
+		t.Errorf("messages = %+v, want only the delivered message after the cursor", messages)
+	}
+}
@@ -0,0 +1,137 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Redis pub/sub channel broadcast messages are published on
+	redisChannel = "nous:broadcast"
+
+	// Messages waiting to be published before new ones are dropped
+	redisPublishBuffer = 1024
+
+	// Time allowed for a single publish
+	redisPublishTimeout = 2 * time.Second
+)
+
+// RedisBroker distributes messages between API replicas over Redis pub/sub.
+// Publishing is asynchronous so a slow Redis doesn't delay ingestion.
+type RedisBroker struct {
+	client *redis.Client
+	outbox chan []byte
+	done   chan struct{}
+
+	mu      sync.Mutex
+	pubsubs []*redis.PubSub
+}
+
+// NewRedisBroker connects to the Redis server at url (redis://host:port/db)
+func NewRedisBroker(ctx context.Context, url string) (*RedisBroker, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	b := &RedisBroker{
+		client: client,
+		outbox: make(chan []byte, redisPublishBuffer),
+		done:   make(chan struct{}),
+	}
+	go b.publishLoop()
+
+	return b, nil
+}
+
+// Publish queues a message for publishing to all replicas
+func (b *RedisBroker) Publish(ctx context.Context, env Envelope) error {
+	payload, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case b.outbox <- payload:
+		return nil
+	default:
+		return fmt.Errorf("redis publish queue is full")
+	}
+}
+
+// Subscribe starts delivering messages published by any replica to handle
+func (b *RedisBroker) Subscribe(ctx context.Context, handle func(Envelope)) error {
+	pubsub := b.client.Subscribe(ctx, redisChannel)
+
+	// Wait for the subscription to be confirmed so no message is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return fmt.Errorf("failed to subscribe to Redis: %w", err)
+	}
+
+	b.mu.Lock()
+	b.pubsubs = append(b.pubsubs, pubsub)
+	b.mu.Unlock()
+
+	go func() {
+		// The channel survives reconnects and is closed by pubsub.Close
+		for msg := range pubsub.Channel() {
+			var env Envelope
+			if err := json.Unmarshal([]byte(msg.Payload), &env); err != nil {
+				log.Printf("Ignoring malformed broadcast from Redis: %v", err)
+				continue
+			}
+			handle(env)
+		}
+	}()
+
+	return nil
+}
+
+// Ping checks Redis connectivity
+func (b *RedisBroker) Ping(ctx context.Context) error {
+	return b.client.Ping(ctx).Err()
+}
+
+// Close stops publishing and subscriptions and closes the connection
+func (b *RedisBroker) Close() error {
+	close(b.done)
+
+	b.mu.Lock()
+	for _, pubsub := range b.pubsubs {
+		pubsub.Close()
+	}
+	b.pubsubs = nil
+	b.mu.Unlock()
+
+	return b.client.Close()
+}
+
+// publishLoop publishes queued messages in order
+func (b *RedisBroker) publishLoop() {
+	for {
+		select {
+		case payload := <-b.outbox:
+			ctx, cancel := context.WithTimeout(context.Background(), redisPublishTimeout)
+			err := b.client.Publish(ctx, redisChannel, payload).Err()
+			cancel()
+			if err != nil {
+				log.Printf("Error publishing broadcast to Redis: %v", err)
+			}
+		case <-b.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package websocket
+
+import "sync"
+
+// DefaultReplaySize is the number of recent messages kept for resuming
+// clients unless configured
+const DefaultReplaySize = 1000
+
+// replayBuffer is a bounded log of recent broadcast messages ordered by
+// sequence number. It doubles as the hub's delivery queue, so a burst of
+// broadcasts is queued rather than dropped.
+type replayBuffer struct {
+	mu      sync.Mutex
+	entries []projectMessage // ring buffer
+	start   int              // index of the oldest entry
+	count   int
+	first   uint64 // sequence number of the first message ever appended
+	next    uint64 // sequence number of the next message
+}
+
+// newReplayBuffer creates a buffer holding up to size messages whose
+// sequence numbers start at first
+func newReplayBuffer(size int, first uint64) *replayBuffer {
+	if size <= 0 {
+		size = DefaultReplaySize
+	}
+	return &replayBuffer{
+		entries: make([]projectMessage, size),
+		first:   first,
+		next:    first,
+	}
+}
+
+// append assigns the next sequence number to a message, encodes it with
+// encode and stores it, evicting the oldest message when full
+func (b *replayBuffer) append(pm projectMessage, encode func(seq uint64) ([]byte, error)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	data, err := encode(b.next)
+	if err != nil {
+		return err
+	}
+	pm.seq = b.next
+	pm.data = data
+	b.next++
+
+	if b.count < len(b.entries) {
+		b.entries[(b.start+b.count)%len(b.entries)] = pm
+		b.count++
+		return nil
+	}
+	b.entries[b.start] = pm
+	b.start = (b.start + 1) % len(b.entries)
+	return nil
+}
+
+// since returns the buffered messages with sequence numbers in (after,
+// until], oldest first. complete is false when messages after `after` have
+// already been evicted.
+func (b *replayBuffer) since(after, until uint64) (messages []projectMessage, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	oldest := b.next - uint64(b.count)
+	complete = after+1 >= oldest && after < b.next
+
+	for i := 0; i < b.count; i++ {
+		pm := b.entries[(b.start+i)%len(b.entries)]
+		if pm.seq > after && pm.seq <= until {
+			messages = append(messages, pm)
+		}
+	}
+	return messages, complete
+}
+
+// issued reports whether seq was assigned by this buffer, or is the cursor
+// of a client that hasn't received any of its messages yet. Cursors from
+// other replicas or from before a restart weren't.
+func (b *replayBuffer) issued(seq uint64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return seq+1 >= b.first && seq < b.next
+}
+
+// latest returns the sequence number of the newest message
+func (b *replayBuffer) latest() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.next - 1
+}
+
+// oldest returns the sequence number of the oldest buffered message
+func (b *replayBuffer) oldest() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.next - uint64(b.count)
+}
+
+// capacity returns the maximum number of buffered messages
+func (b *replayBuffer) capacity() int {
+	return len(b.entries)
+}
@@ -0,0 +1,123 @@
+package websocket
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ServeSSE streams the same messages as ServeWS as Server-Sent Events, for
+// clients and proxies that can't upgrade to WebSocket. Each event's id is
+// the message seq, so a reconnecting EventSource resumes through the
+// Last-Event-ID header (or the last_event_id query parameter). Filters are
+// given as query parameters (see FilterFromQuery) since the stream is
+// one-way. A heartbeat comment is sent every pingPeriod.
+func (h *Hub) ServeSSE(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.authorizeClient(w, r)
+	if !ok {
+		return
+	}
+
+	filter, filtered, err := FilterFromQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var resumeFrom *uint64
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	if lastEventID != "" {
+		seq, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			http.Error(w, "Last-Event-ID must be a message seq", http.StatusBadRequest)
+			return
+		}
+		resumeFrom = &seq
+	}
+
+	rc := http.NewResponseController(w)
+
+	client := h.newClient(nil, project, resumeFrom)
+	if filtered {
+		client.subscriptions[""] = filter
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // disable proxy buffering (nginx)
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		log.Printf("SSE streaming unsupported: %v", err)
+		return
+	}
+
+	h.register <- client
+	defer func() { h.unregister <- client }()
+
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case message, ok := <-client.send:
+			if !ok {
+				// Hub closed the client, e.g. because it couldn't keep up
+				return
+			}
+			rc.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := writeEvent(w, message); err != nil {
+				return
+			}
+			// Add queued messages to the same flush
+			for n := len(client.send); n > 0; n-- {
+				if err := writeEvent(w, <-client.send); err != nil {
+					return
+				}
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			rc.SetWriteDeadline(time.Now().Add(writeWait))
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// writeEvent writes an encoded Message as an SSE event named after its
+// type, with its seq as the event ID when it has one
+func writeEvent(w http.ResponseWriter, message []byte) error {
+	var header struct {
+		Seq  uint64 `json:"seq"`
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(message, &header); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if header.Seq != 0 {
+		fmt.Fprintf(&buf, "id: %d\n", header.Seq)
+	}
+	fmt.Fprintf(&buf, "event: %s\ndata: %s\n\n", header.Type, message)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,254 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/yourorg/nous/internal/models"
+)
+
+// Maximum number of concurrent subscriptions per client
+const maxSubscriptions = 32
+
+// Filter selects the tool calls a subscription receives. Zero-valued
+// fields match everything; all set fields must match.
+type Filter struct {
+	ToolNames    []string          `json:"tool_names,omitempty"`
+	Status       string            `json:"status,omitempty"`
+	RequestID    string            `json:"request_id,omitempty"`
+	MetadataKeys []string          `json:"metadata_keys,omitempty"` // keys that must be present
+	Metadata     map[string]string `json:"metadata,omitempty"`      // key/value pairs that must match
+	FailuresOnly bool              `json:"failures_only,omitempty"`
+}
+
+// Matches reports whether a tool call passes the filter
+func (f Filter) Matches(event *models.ToolCallEvent) bool {
+	if len(f.ToolNames) > 0 && !contains(f.ToolNames, event.ToolName) {
+		return false
+	}
+	if f.Status != "" && event.Status != f.Status {
+		return false
+	}
+	if f.FailuresOnly && event.Status != "failed" {
+		return false
+	}
+	if f.RequestID != "" && !strings.EqualFold(event.RequestID, f.RequestID) {
+		return false
+	}
+	for _, key := range f.MetadataKeys {
+		if _, ok := event.Metadata[key]; !ok {
+			return false
+		}
+	}
+	for key, want := range f.Metadata {
+		value, ok := event.Metadata[key]
+		if !ok {
+			return false
+		}
+		if str, isString := value.(string); isString {
+			if str != want {
+				return false
+			}
+		} else if encoded, _ := json.Marshal(value); string(encoded) != want {
+			// Non-string values compare by their JSON encoding, e.g. "42" or "true"
+			return false
+		}
+	}
+	return true
+}
+
+// validate checks the filter's values
+func (f Filter) validate() string {
+	if f.Status != "" && f.Status != "success" && f.Status != "failed" {
+		return "status must be 'success' or 'failed'"
+	}
+	return ""
+}
+
+// FilterFromQuery builds a filter from query parameters, for clients that
+// can't send subscribe messages: tool_name (comma-separated or repeated),
+// status, request_id, metadata_key, metadata.<key>=<value> and
+// failures_only. ok is false when no filter parameter was given.
+func FilterFromQuery(params url.Values) (filter Filter, ok bool, err error) {
+	filter.ToolNames = splitList(params["tool_name"])
+	filter.Status = params.Get("status")
+	filter.RequestID = params.Get("request_id")
+	filter.MetadataKeys = splitList(params["metadata_key"])
+
+	for name, values := range params {
+		if key, found := strings.CutPrefix(name, "metadata."); found && key != "" {
+			if filter.Metadata == nil {
+				filter.Metadata = make(map[string]string)
+			}
+			filter.Metadata[key] = values[0]
+		}
+	}
+
+	if value := params.Get("failures_only"); value != "" {
+		if filter.FailuresOnly, err = strconv.ParseBool(value); err != nil {
+			return filter, false, fmt.Errorf("failures_only must be true or false")
+		}
+	}
+
+	if problem := filter.validate(); problem != "" {
+		return filter, false, errors.New(problem)
+	}
+
+	ok = len(filter.ToolNames) > 0 || filter.Status != "" || filter.RequestID != "" ||
+		len(filter.MetadataKeys) > 0 || len(filter.Metadata) > 0 || filter.FailuresOnly
+	return filter, ok, nil
+}
+
+// clientMessage is a control message sent by a client:
+//
+//	{"type": "subscribe", "id": "checkout", "filter": {"tool_names": ["search"], "failures_only": true}}
+//	{"type": "unsubscribe", "id": "checkout"}
+//
+// Subscribing with an existing ID replaces that subscription; unsubscribing
+// without an ID removes all of them.
+type clientMessage struct {
+	Type   string `json:"type"`
+	ID     string `json:"id,omitempty"`
+	Filter Filter `json:"filter"`
+}
+
+// subscriptionReply acknowledges a control message
+type subscriptionReply struct {
+	ID            string  `json:"id,omitempty"`
+	Filter        *Filter `json:"filter,omitempty"`
+	Subscriptions int     `json:"subscriptions"`
+}
+
+// handleMessage applies a control message and replies to the client
+func (c *Client) handleMessage(data []byte) {
+	var msg clientMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		c.reply("error", map[string]string{"message": "invalid message"})
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch msg.Type {
+	case "subscribe":
+		if problem := msg.Filter.validate(); problem != "" {
+			c.replyLocked("error", map[string]string{"id": msg.ID, "message": problem})
+			return
+		}
+		if _, exists := c.subscriptions[msg.ID]; !exists && len(c.subscriptions) >= maxSubscriptions {
+			c.replyLocked("error", map[string]string{"id": msg.ID, "message": "too many subscriptions"})
+			return
+		}
+		c.subscriptions[msg.ID] = msg.Filter
+		c.replyLocked("subscribed", subscriptionReply{ID: msg.ID, Filter: &msg.Filter, Subscriptions: len(c.subscriptions)})
+
+	case "unsubscribe":
+		if msg.ID == "" {
+			clear(c.subscriptions)
+		} else {
+			delete(c.subscriptions, msg.ID)
+		}
+		c.replyLocked("unsubscribed", subscriptionReply{ID: msg.ID, Subscriptions: len(c.subscriptions)})
+
+	default:
+		c.replyLocked("error", map[string]string{"message": "unknown message type " + msg.Type})
+	}
+}
+
+// wants reports whether a message should be delivered to the client.
+// Messages other than tool calls are always delivered.
+func (c *Client) wants(event *models.ToolCallEvent) bool {
+	if event == nil {
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.subscriptions) == 0 {
+		return true
+	}
+	for _, filter := range c.subscriptions {
+		if filter.Matches(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// reply queues a message for the client
+func (c *Client) reply(eventType string, data interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.replyLocked(eventType, data)
+}
+
+// replyLocked queues a message for the client; c.mu must be held
+func (c *Client) replyLocked(eventType string, data interface{}) {
+	encoded, err := json.Marshal(Message{Type: eventType, Data: data})
+	if err != nil {
+		log.Printf("Error marshaling WebSocket reply: %v", err)
+		return
+	}
+	c.sendLocked(encoded)
+}
+
+// trySend queues data without blocking, reporting false when the client's
+// buffer is full or the client is closed
+func (c *Client) trySend(data []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sendLocked(data)
+}
+
+// sendLocked is trySend with c.mu held
+func (c *Client) sendLocked(data []byte) bool {
+	if c.closed {
+		return false
+	}
+	select {
+	case c.send <- data:
+		return true
+	default:
+		return false
+	}
+}
+
+// close closes the client's send channel once, ending its writePump
+func (c *Client) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
+
+// splitList splits repeated and comma-separated values, dropping empty ones
+func splitList(values []string) []string {
+	var list []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+// contains reports whether values includes value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
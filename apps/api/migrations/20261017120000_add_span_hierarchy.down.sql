@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_tool_calls_request_span;
+
+ALTER TABLE tool_calls DROP COLUMN IF EXISTS parent_span_id;
+ALTER TABLE tool_calls DROP COLUMN IF EXISTS span_id;
@@ -0,0 +1,6 @@
+-- Span identifiers for reconstructing parent/child relationships within a request chain
+ALTER TABLE tool_calls ADD COLUMN IF NOT EXISTS span_id VARCHAR(64);
+ALTER TABLE tool_calls ADD COLUMN IF NOT EXISTS parent_span_id VARCHAR(64);
+
+-- Chains are always looked up by request, then linked by span
+CREATE INDEX IF NOT EXISTS idx_tool_calls_request_span ON tool_calls(request_id, span_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS ingest_keys;
+
+DROP INDEX IF EXISTS idx_tool_calls_event_id;
+
+ALTER TABLE tool_calls DROP COLUMN IF EXISTS event_id;
@@ -0,0 +1,16 @@
+-- Client-supplied event ID, kept on the row for traceability
+ALTER TABLE tool_calls ADD COLUMN IF NOT EXISTS event_id VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_tool_calls_event_id ON tool_calls(event_id) WHERE event_id IS NOT NULL;
+
+-- Event IDs claimed within the deduplication window.
+-- Hypertable unique indexes must include created_at, so uniqueness is
+-- enforced here instead of on tool_calls. The original record is kept so
+-- replays can be answered even before a buffered write is flushed.
+CREATE TABLE IF NOT EXISTS ingest_keys (
+    event_id VARCHAR(255) PRIMARY KEY,
+    record JSONB NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingest_keys_received_at ON ingest_keys(received_at);
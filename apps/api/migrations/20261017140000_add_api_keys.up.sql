@@ -0,0 +1,12 @@
+-- API keys. Only the SHA-256 hash of a key is stored; the key itself is
+-- shown once when it is created.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
@@ -0,0 +1,17 @@
+-- Event IDs from different projects may collide once the scope is removed
+DELETE FROM ingest_keys a
+USING ingest_keys b
+WHERE a.event_id = b.event_id AND a.project_id > b.project_id;
+
+ALTER TABLE ingest_keys DROP CONSTRAINT IF EXISTS ingest_keys_pkey;
+ALTER TABLE ingest_keys DROP COLUMN IF EXISTS project_id;
+ALTER TABLE ingest_keys ADD PRIMARY KEY (event_id);
+
+DROP INDEX IF EXISTS idx_api_keys_project_id;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS project_id;
+
+DROP INDEX IF EXISTS idx_tool_calls_project_request;
+DROP INDEX IF EXISTS idx_tool_calls_project_time;
+ALTER TABLE tool_calls DROP COLUMN IF EXISTS project_id;
+
+DROP TABLE IF EXISTS projects;
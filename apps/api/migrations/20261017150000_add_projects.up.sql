@@ -0,0 +1,31 @@
+-- Projects isolate the data of teams sharing a deployment. Data ingested
+-- before projects existed belongs to the default project.
+CREATE TABLE IF NOT EXISTS projects (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO projects (id, name)
+VALUES ('00000000-0000-0000-0000-000000000001', 'Default')
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE tool_calls
+    ADD COLUMN IF NOT EXISTS project_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001';
+
+CREATE INDEX IF NOT EXISTS idx_tool_calls_project_time ON tool_calls(project_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_tool_calls_project_request ON tool_calls(project_id, request_id);
+
+-- API keys grant access to a single project
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS project_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001'
+    REFERENCES projects(id);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_project_id ON api_keys(project_id);
+
+-- Event IDs only need to be unique within a project
+ALTER TABLE ingest_keys
+    ADD COLUMN IF NOT EXISTS project_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001';
+
+ALTER TABLE ingest_keys DROP CONSTRAINT IF EXISTS ingest_keys_pkey;
+ALTER TABLE ingest_keys ADD PRIMARY KEY (project_id, event_id);
@@ -0,0 +1,3 @@
+-- Dropping a continuous aggregate also removes its refresh policy
+DROP MATERIALIZED VIEW IF EXISTS tool_calls_daily;
+DROP MATERIALIZED VIEW IF EXISTS tool_calls_hourly;
@@ -0,0 +1,73 @@
+-- Hourly and daily rollups of tool calls per project, tool and status, so
+-- long-range metrics don't scan raw rows. Latency percentiles are kept as
+-- percentile_agg sketches, which need the timescaledb_toolkit extension;
+-- without it (or without TimescaleDB) the rollups are skipped and metrics
+-- keep reading tool_calls.
+--
+-- The rollups use real-time aggregation (materialized_only = false), so
+-- data newer than the last refresh is aggregated from tool_calls on read.
+-- Refreshes start from the beginning of the data (start_offset NULL) so
+-- late or backfilled events are picked up; only invalidated ranges are
+-- recomputed.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'timescaledb')
+        OR NOT EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'timescaledb_toolkit') THEN
+        RAISE NOTICE 'timescaledb_toolkit is not available, skipping metric rollups';
+        RETURN;
+    END IF;
+
+    CREATE EXTENSION IF NOT EXISTS timescaledb_toolkit;
+
+    EXECUTE $view$
+        CREATE MATERIALIZED VIEW IF NOT EXISTS tool_calls_hourly
+        WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+        SELECT
+            time_bucket(INTERVAL '1 hour', created_at) AS bucket,
+            project_id,
+            tool_name,
+            status,
+            COUNT(*) AS calls,
+            SUM(duration_ms) AS duration_sum,
+            SUM(input_tokens) AS input_tokens,
+            SUM(output_tokens) AS output_tokens,
+            percentile_agg(duration_ms::double precision) AS latency
+        FROM tool_calls
+        GROUP BY 1, 2, 3, 4
+        WITH NO DATA
+    $view$;
+
+    EXECUTE $view$
+        CREATE MATERIALIZED VIEW IF NOT EXISTS tool_calls_daily
+        WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+        SELECT
+            time_bucket(INTERVAL '1 day', created_at) AS bucket,
+            project_id,
+            tool_name,
+            status,
+            COUNT(*) AS calls,
+            SUM(duration_ms) AS duration_sum,
+            SUM(input_tokens) AS input_tokens,
+            SUM(output_tokens) AS output_tokens,
+            percentile_agg(duration_ms::double precision) AS latency
+        FROM tool_calls
+        GROUP BY 1, 2, 3, 4
+        WITH NO DATA
+    $view$;
+
+    CREATE INDEX IF NOT EXISTS idx_tool_calls_hourly_project_bucket ON tool_calls_hourly(project_id, bucket DESC);
+    CREATE INDEX IF NOT EXISTS idx_tool_calls_daily_project_bucket ON tool_calls_daily(project_id, bucket DESC);
+
+    PERFORM add_continuous_aggregate_policy('tool_calls_hourly',
+        start_offset => NULL,
+        end_offset => INTERVAL '1 hour',
+        schedule_interval => INTERVAL '30 minutes',
+        if_not_exists => TRUE);
+
+    PERFORM add_continuous_aggregate_policy('tool_calls_daily',
+        start_offset => NULL,
+        end_offset => INTERVAL '1 day',
+        schedule_interval => INTERVAL '1 hour',
+        if_not_exists => TRUE);
+END
+$$;
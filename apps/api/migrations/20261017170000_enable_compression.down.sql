@@ -0,0 +1,20 @@
+-- Removes the retention and compression policies and decompresses all
+-- chunks so compression can be disabled
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'timescaledb') THEN
+        RETURN;
+    END IF;
+
+    PERFORM remove_retention_policy('tool_calls', if_exists => TRUE);
+    PERFORM remove_compression_policy('tool_calls', if_exists => TRUE);
+    PERFORM decompress_chunk(chunk, if_compressed => TRUE) FROM show_chunks('tool_calls') chunk;
+
+    ALTER TABLE tool_calls SET (timescaledb.compress = false);
+
+    IF to_regclass('tool_calls_hourly') IS NOT NULL THEN
+        PERFORM remove_retention_policy('tool_calls_hourly', if_exists => TRUE);
+        PERFORM remove_retention_policy('tool_calls_daily', if_exists => TRUE);
+    END IF;
+END
+$$;
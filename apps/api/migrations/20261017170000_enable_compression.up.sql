@@ -0,0 +1,17 @@
+-- Allow compressing tool_calls chunks. Nothing is compressed until a
+-- compression policy is set through the admin API. Segmenting by project
+-- and tool keeps per-project and per-tool queries on compressed chunks
+-- cheap; the primary key columns must be part of the ordering.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'timescaledb') THEN
+        RETURN;
+    END IF;
+
+    ALTER TABLE tool_calls SET (
+        timescaledb.compress,
+        timescaledb.compress_segmentby = 'project_id, tool_name',
+        timescaledb.compress_orderby = 'created_at DESC, id'
+    );
+END
+$$;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS model_prices;
+
+ALTER TABLE tool_calls
+    DROP COLUMN IF EXISTS cost_usd,
+    DROP COLUMN IF EXISTS cached_tokens,
+    DROP COLUMN IF EXISTS model;
@@ -0,0 +1,20 @@
+-- The model behind a tool call and the cost of its tokens, computed at
+-- ingest from the price catalog. cost_usd is NULL when the call names no
+-- model or its model had no price at the time.
+ALTER TABLE tool_calls
+    ADD COLUMN IF NOT EXISTS model VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS cached_tokens INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS cost_usd DOUBLE PRECISION;
+
+-- Model price catalog in US dollars per million tokens. A price applies
+-- from effective_from until the model's next price.
+CREATE TABLE IF NOT EXISTS model_prices (
+    id UUID PRIMARY KEY,
+    model VARCHAR(255) NOT NULL,
+    input_per_million DOUBLE PRECISION NOT NULL,
+    output_per_million DOUBLE PRECISION NOT NULL,
+    cached_input_per_million DOUBLE PRECISION,
+    effective_from TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (model, effective_from)
+);
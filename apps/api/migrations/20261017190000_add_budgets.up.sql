@@ -0,0 +1,15 @@
+-- Token and cost budgets over rolling windows. With a metadata key, usage
+-- is tracked separately per value of that key (e.g. per agent_id).
+CREATE TABLE IF NOT EXISTS budgets (
+    id UUID PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id),
+    name VARCHAR(255) NOT NULL,
+    metric VARCHAR(20) NOT NULL CHECK (metric IN ('tokens', 'cost_usd')),
+    limit_value DOUBLE PRECISION NOT NULL,
+    window_hours INTEGER NOT NULL,
+    tool_name VARCHAR(255),
+    metadata_key VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_budgets_project_id ON budgets(project_id);
@@ -0,0 +1,36 @@
+-- Alert rules compare a metric over a trailing window against a threshold.
+-- The evaluation state is kept with the rule so it survives restarts and is
+-- shared by replicas.
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id UUID PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id),
+    name VARCHAR(255) NOT NULL,
+    metric VARCHAR(32) NOT NULL,
+    operator VARCHAR(3) NOT NULL CHECK (operator IN ('gt', 'gte', 'lt', 'lte')),
+    threshold DOUBLE PRECISION NOT NULL,
+    window_seconds INTEGER NOT NULL,
+    for_seconds INTEGER NOT NULL DEFAULT 0,
+    filter JSONB NOT NULL DEFAULT '{}',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    state VARCHAR(20) NOT NULL DEFAULT 'ok' CHECK (state IN ('ok', 'pending', 'firing')),
+    state_since TIMESTAMPTZ,
+    last_value DOUBLE PRECISION,
+    last_evaluated_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_rules_project_id ON alert_rules(project_id);
+
+-- State changes of alert rules
+CREATE TABLE IF NOT EXISTS alert_events (
+    id UUID PRIMARY KEY,
+    rule_id UUID NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL,
+    state VARCHAR(20) NOT NULL,
+    previous_state VARCHAR(20) NOT NULL,
+    value DOUBLE PRECISION,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_events_project_time ON alert_events(project_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_alert_events_rule_time ON alert_events(rule_id, created_at DESC);
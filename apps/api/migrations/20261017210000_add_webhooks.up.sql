@@ -0,0 +1,40 @@
+-- Outbound webhooks. The secret signs request bodies, so it is stored as is.
+CREATE TABLE IF NOT EXISTS webhooks (
+    id UUID PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id),
+    name VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    events TEXT[] NOT NULL DEFAULT '{}',
+    secret VARCHAR(255) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_project_id ON webhooks(project_id);
+
+-- One row per delivery attempt
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY,
+    webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL,
+    delivery_id UUID NOT NULL,
+    event VARCHAR(64) NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    error TEXT,
+    success BOOLEAN NOT NULL,
+    duration_ms INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_time ON webhook_deliveries(webhook_id, created_at DESC);
+
+-- Error types seen per project, so only new ones are notified
+CREATE TABLE IF NOT EXISTS error_types (
+    project_id UUID NOT NULL,
+    tool_name VARCHAR(255) NOT NULL,
+    fingerprint VARCHAR(64) NOT NULL,
+    message TEXT NOT NULL,
+    first_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (project_id, tool_name, fingerprint)
+);
@@ -0,0 +1,15 @@
+-- Service level objectives. Their status is computed from tool_calls on
+-- request, so nothing else is stored.
+CREATE TABLE IF NOT EXISTS slos (
+    id UUID PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id),
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('availability', 'latency')),
+    objective DOUBLE PRECISION NOT NULL,
+    latency_threshold_ms INTEGER,
+    window_days INTEGER NOT NULL,
+    filter JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_slos_project_id ON slos(project_id);
@@ -0,0 +1,17 @@
+// Package migrations embeds the SQL migrations so the binary runs them
+// regardless of its working directory.
+package migrations
+
+import "embed"
+
+// FS holds the PostgreSQL migrations at its root and the SQLite store's
+// migrations in sqlite/
+//
+//go:embed *.sql sqlite/*.sql
+var FS embed.FS
+
+// Directories of each backend's migrations in FS
+const (
+	PostgresDir = "."
+	SQLiteDir   = "sqlite"
+)
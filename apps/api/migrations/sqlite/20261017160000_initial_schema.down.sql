@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS ingest_keys;
+DROP TABLE IF EXISTS tool_calls;
+DROP TABLE IF EXISTS projects;
@@ -0,0 +1,56 @@
+-- SQLite schema, equivalent to the PostgreSQL migrations up to projects.
+-- UUIDs are stored as text, timestamps as Unix microseconds and JSON as text.
+CREATE TABLE IF NOT EXISTS projects (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_at INTEGER NOT NULL
+);
+
+INSERT OR IGNORE INTO projects (id, name, created_at)
+VALUES ('00000000-0000-0000-0000-000000000001', 'Default', CAST(strftime('%s', 'now') AS INTEGER) * 1000000);
+
+CREATE TABLE IF NOT EXISTS tool_calls (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001',
+    request_id TEXT NOT NULL,
+    tool_name TEXT NOT NULL,
+    duration_ms INTEGER NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('success', 'failed')),
+    input_tokens INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    metadata TEXT NOT NULL DEFAULT '{}',
+    created_at INTEGER NOT NULL,
+    span_id TEXT,
+    parent_span_id TEXT,
+    event_id TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_tool_calls_project_time ON tool_calls(project_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_tool_calls_project_request ON tool_calls(project_id, request_id);
+
+-- Event IDs claimed within the deduplication window, with the original record
+CREATE TABLE IF NOT EXISTS ingest_keys (
+    project_id TEXT NOT NULL,
+    event_id TEXT NOT NULL,
+    record TEXT NOT NULL,
+    received_at INTEGER NOT NULL,
+    PRIMARY KEY (project_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingest_keys_received_at ON ingest_keys(received_at);
+
+-- API keys. Only the SHA-256 hash of a key is stored; scopes are a JSON array.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL REFERENCES projects(id),
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT NOT NULL,
+    created_at INTEGER NOT NULL,
+    last_used_at INTEGER,
+    revoked_at INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_project_id ON api_keys(project_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_tool_calls_created_at;
+DROP TABLE IF EXISTS data_policies;
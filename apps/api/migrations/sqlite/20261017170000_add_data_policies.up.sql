@@ -0,0 +1,10 @@
+-- Retention policy, a single row. The SQLite store only supports raw
+-- retention, enforced by the server in the background.
+CREATE TABLE IF NOT EXISTS data_policies (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    raw_retention_days INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT OR IGNORE INTO data_policies (id) VALUES (1);
+
+CREATE INDEX IF NOT EXISTS idx_tool_calls_created_at ON tool_calls(created_at);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS model_prices;
+
+ALTER TABLE tool_calls DROP COLUMN cost_usd;
+ALTER TABLE tool_calls DROP COLUMN cached_tokens;
+ALTER TABLE tool_calls DROP COLUMN model;
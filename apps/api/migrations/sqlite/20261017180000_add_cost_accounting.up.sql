@@ -0,0 +1,17 @@
+-- The model behind a tool call and the cost of its tokens, computed at
+-- ingest from the price catalog
+ALTER TABLE tool_calls ADD COLUMN model TEXT;
+ALTER TABLE tool_calls ADD COLUMN cached_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tool_calls ADD COLUMN cost_usd REAL;
+
+-- Model price catalog in US dollars per million tokens
+CREATE TABLE IF NOT EXISTS model_prices (
+    id TEXT PRIMARY KEY,
+    model TEXT NOT NULL,
+    input_per_million REAL NOT NULL,
+    output_per_million REAL NOT NULL,
+    cached_input_per_million REAL,
+    effective_from INTEGER NOT NULL,
+    created_at INTEGER NOT NULL,
+    UNIQUE (model, effective_from)
+);
@@ -0,0 +1,14 @@
+-- Token and cost budgets over rolling windows
+CREATE TABLE IF NOT EXISTS budgets (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL REFERENCES projects(id),
+    name TEXT NOT NULL,
+    metric TEXT NOT NULL CHECK (metric IN ('tokens', 'cost_usd')),
+    limit_value REAL NOT NULL,
+    window_hours INTEGER NOT NULL,
+    tool_name TEXT,
+    metadata_key TEXT,
+    created_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_budgets_project_id ON budgets(project_id);
@@ -0,0 +1,33 @@
+-- Alert rules and the history of their state changes
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL REFERENCES projects(id),
+    name TEXT NOT NULL,
+    metric TEXT NOT NULL,
+    operator TEXT NOT NULL CHECK (operator IN ('gt', 'gte', 'lt', 'lte')),
+    threshold REAL NOT NULL,
+    window_seconds INTEGER NOT NULL,
+    for_seconds INTEGER NOT NULL DEFAULT 0,
+    filter TEXT NOT NULL DEFAULT '{}',
+    enabled INTEGER NOT NULL DEFAULT 1,
+    state TEXT NOT NULL DEFAULT 'ok' CHECK (state IN ('ok', 'pending', 'firing')),
+    state_since INTEGER,
+    last_value REAL,
+    last_evaluated_at INTEGER,
+    created_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_rules_project_id ON alert_rules(project_id);
+
+CREATE TABLE IF NOT EXISTS alert_events (
+    id TEXT PRIMARY KEY,
+    rule_id TEXT NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+    project_id TEXT NOT NULL,
+    state TEXT NOT NULL,
+    previous_state TEXT NOT NULL,
+    value REAL,
+    created_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_events_project_time ON alert_events(project_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_alert_events_rule_time ON alert_events(rule_id, created_at DESC);
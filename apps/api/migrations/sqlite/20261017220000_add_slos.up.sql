@@ -0,0 +1,14 @@
+-- Service level objectives, computed from tool_calls on request
+CREATE TABLE IF NOT EXISTS slos (
+    id TEXT PRIMARY KEY,
+    project_id TEXT NOT NULL REFERENCES projects(id),
+    name TEXT NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('availability', 'latency')),
+    objective REAL NOT NULL,
+    latency_threshold_ms INTEGER,
+    window_days INTEGER NOT NULL,
+    filter TEXT NOT NULL DEFAULT '{}',
+    created_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_slos_project_id ON slos(project_id);
@@ -6,6 +6,7 @@ import {
 	type ToolCall as ApiToolCall,
 	type ToolCallDataPoint as ApiToolCallDataPoint,
 	apiClient,
+	type LiveAggregates,
 	type MetricsOverview,
 } from '@/lib/api';
 import {
@@ -100,3 +101,13 @@ export function useToolCallChain(requestId: string | null) {
 		refetchInterval: 30000,
 	});
 }
+
+// Latest rolling aggregates pushed over the WebSocket (see useRealtimeToolCalls)
+export function useLiveAggregates() {
+	return useQuery<LiveAggregates | null>({
+		queryKey: ['live-aggregates'],
+		queryFn: () => null,
+		enabled: false,
+		staleTime: Infinity,
+	});
+}
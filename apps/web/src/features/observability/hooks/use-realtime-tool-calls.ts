@@ -1,6 +1,7 @@
 import { useQueryClient } from '@tanstack/react-query';
 import { useCallback, useEffect, useRef } from 'react';
 import { useWebSocket } from '@/hooks/use-websocket';
+import type { LiveAggregates } from '@/lib/api';
 
 export function useRealtimeToolCalls() {
 	const queryClient = useQueryClient();
@@ -35,6 +36,12 @@ export function useRealtimeToolCalls() {
 				queryClientRef.current.invalidateQueries({
 					queryKey: ['failure-rate-metrics'],
 				});
+			} else if (message.type === 'aggregates') {
+				// Rolling 1/5/15 minute stats, read with useLiveAggregates
+				queryClientRef.current.setQueryData(
+					['live-aggregates'],
+					message.data as LiveAggregates,
+				);
 			}
 		},
 		[], // Empty deps - callback never changes
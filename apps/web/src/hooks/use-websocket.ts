@@ -1,8 +1,16 @@
 import { useEffect, useRef, useState } from 'react';
 
-const WS_URL = import.meta.env.VITE_WS_URL || 'ws://localhost:8080/ws';
+const WS_BASE_URL = import.meta.env.VITE_WS_URL || 'ws://localhost:8080/ws';
+
+// Browsers can't set headers on WebSocket connections, so the read key is
+// passed as a query parameter
+const API_KEY: string | undefined = import.meta.env.VITE_API_KEY;
+const WS_URL = API_KEY
+	? `${WS_BASE_URL}${WS_BASE_URL.includes('?') ? '&' : '?'}api_key=${encodeURIComponent(API_KEY)}`
+	: WS_BASE_URL;
 
 export interface WebSocketMessage {
+	seq?: number;
 	type: string;
 	data: unknown;
 	timestamp: number;
@@ -16,6 +24,8 @@ export function useWebSocket<T = unknown>(
 	const wsRef = useRef<WebSocket | null>(null);
 	const reconnectTimeoutRef = useRef<number | null>(null);
 	const reconnectAttempts = useRef(0);
+	// Seq of the last broadcast received, so reconnects replay missed events
+	const lastSeqRef = useRef<number | null>(null);
 	const maxReconnectAttempts = 5;
 	const reconnectDelay = 1000; // Start with 1 second
 
@@ -28,7 +38,11 @@ export function useWebSocket<T = unknown>(
 			}
 
 			try {
-				const ws = new WebSocket(WS_URL);
+				const url =
+					lastSeqRef.current === null
+						? WS_URL
+						: `${WS_URL}${WS_URL.includes('?') ? '&' : '?'}resume_from=${lastSeqRef.current}`;
+				const ws = new WebSocket(url);
 
 				ws.onopen = () => {
 					if (!mounted) return;
@@ -40,13 +54,20 @@ export function useWebSocket<T = unknown>(
 
 				ws.onmessage = (event) => {
 					if (!mounted) return;
-					try {
-						const message: WebSocketMessage = JSON.parse(event.data);
-						if (onMessage) {
-							onMessage(message as WebSocketMessage & { data: T });
+					// The server may batch several newline-separated messages in one frame
+					for (const line of String(event.data).split('\n')) {
+						if (!line) continue;
+						try {
+							const message: WebSocketMessage = JSON.parse(line);
+							if (typeof message.seq === 'number') {
+								lastSeqRef.current = message.seq;
+							}
+							if (onMessage) {
+								onMessage(message as WebSocketMessage & { data: T });
+							}
+						} catch (err) {
+							console.error('Error parsing WebSocket message:', err);
 						}
-					} catch (err) {
-						console.error('Error parsing WebSocket message:', err);
 					}
 				};
 
@@ -1,7 +1,11 @@
 const API_BASE_URL =
 	import.meta.env.VITE_API_URL || 'http://localhost:8080/api/v1';
 
+// Read-scoped API key, required when the API runs with AUTH_ENABLED=true
+const API_KEY: string | undefined = import.meta.env.VITE_API_KEY;
+
 export interface ToolCallDataPoint {
+	bucket: string;
 	hour: string;
 	success: number;
 	failures: number;
@@ -15,18 +19,21 @@ export interface LatencyDataPoint {
 }
 
 export interface TokenUsageDataPoint {
+	bucket: string;
 	hour: string;
 	input: number;
 	output: number;
 }
 
 export interface FailureRateDataPoint {
+	bucket: string;
 	hour: string;
 	failurePercent: number;
 }
 
 export interface ToolCall {
 	id: string;
+	project_id?: string;
 	request_id: string;
 	tool_name: string;
 	duration_ms: number;
@@ -36,6 +43,8 @@ export interface ToolCall {
 	error_message?: string;
 	metadata?: Record<string, unknown>;
 	created_at: string;
+	span_id?: string;
+	parent_span_id?: string;
 }
 
 export interface ToolCallEvent {
@@ -48,6 +57,22 @@ export interface ToolCallEvent {
 	error_message?: string;
 	metadata?: Record<string, unknown>;
 	timestamp?: string;
+	span_id?: string;
+	parent_span_id?: string;
+}
+
+export interface ToolCallNode extends ToolCall {
+	offset_ms: number;
+	orphan?: boolean;
+	children: ToolCallNode[];
+}
+
+export interface ToolCallTree {
+	request_id: string;
+	started_at?: string;
+	total_calls: number;
+	orphans: number;
+	roots: ToolCallNode[];
 }
 
 export interface ToolCallChain {
@@ -56,12 +81,44 @@ export interface ToolCallChain {
 	totalDuration: number;
 }
 
-export interface MetricsOverview {
+export interface MetricsTotals {
 	total_calls: number;
 	avg_latency_ms: number;
 	total_tokens: number;
 	failure_rate: number;
+}
+
+export interface MetricsComparison {
+	from: string;
+	to: string;
+	previous: MetricsTotals;
+	change_percent: {
+		[K in keyof MetricsTotals]: number | null;
+	};
+}
+
+export interface MetricsOverview extends MetricsTotals {
 	change_percent: number;
+	from: string;
+	to: string;
+	comparison?: MetricsComparison;
+}
+
+// Rolling stats pushed over the WebSocket in 'aggregates' messages
+export interface AggregateStats {
+	calls: number;
+	calls_per_sec: number;
+	failure_rate: number;
+	p95_ms: number;
+}
+
+export interface WindowAggregates extends AggregateStats {
+	tools: Record<string, AggregateStats>;
+}
+
+export interface LiveAggregates {
+	at: string;
+	windows: Record<'1m' | '5m' | '15m', WindowAggregates>;
 }
 
 class ApiClient {
@@ -76,6 +133,7 @@ class ApiClient {
 			...options,
 			headers: {
 				'Content-Type': 'application/json',
+				...(API_KEY ? { Authorization: `Bearer ${API_KEY}` } : {}),
 				...options?.headers,
 			},
 		});
@@ -121,6 +179,10 @@ class ApiClient {
 		return this.fetch<ToolCall[]>(`/tool-calls/chains/${requestId}`);
 	}
 
+	async getToolCallTree(requestId: string): Promise<ToolCallTree> {
+		return this.fetch<ToolCallTree>(`/tool-calls/chains/${requestId}/tree`);
+	}
+
 	async getMetricsOverview(hours: number = 24): Promise<MetricsOverview> {
 		return this.fetch<MetricsOverview>(`/metrics/overview?hours=${hours}`);
 	}
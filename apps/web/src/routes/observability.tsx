@@ -26,6 +26,11 @@ export const Route = createFileRoute('/observability')({
 	component: ObservabilityPage,
 });
 
+// Percent changes are shown with one decimal place
+function roundChange(percent: number): number {
+	return Math.round(percent * 10) / 10;
+}
+
 function ObservabilityPage() {
 	const hours = 24; // TODO: Get from header selector
 
@@ -60,10 +65,10 @@ function ObservabilityPage() {
 							? metricsOverview.total_calls.toLocaleString()
 							: METRIC_DISPLAY.TOTAL_CALLS.VALUE.toLocaleString()
 					}
-					changePercent={
-						metricsOverview?.change_percent ??
-						METRIC_DISPLAY.TOTAL_CALLS.CHANGE_PERCENT
-					}
+					changePercent={roundChange(
+						metricsOverview?.comparison?.change_percent.total_calls ??
+							METRIC_DISPLAY.TOTAL_CALLS.CHANGE_PERCENT,
+					)}
 					changeType={METRIC_DISPLAY.TOTAL_CALLS.CHANGE_TYPE}
 					icon={<Activity className='h-4 w-4 text-muted-foreground' />}
 				/>
@@ -74,10 +79,10 @@ function ObservabilityPage() {
 							? `${Math.round(metricsOverview.avg_latency_ms)}ms`
 							: `${METRIC_DISPLAY.AVG_LATENCY.VALUE_MS}ms`
 					}
-					changePercent={
-						metricsOverview?.change_percent ??
-						METRIC_DISPLAY.AVG_LATENCY.CHANGE_PERCENT
-					}
+					changePercent={roundChange(
+						metricsOverview?.comparison?.change_percent.avg_latency_ms ??
+							METRIC_DISPLAY.AVG_LATENCY.CHANGE_PERCENT,
+					)}
 					changeType={METRIC_DISPLAY.AVG_LATENCY.CHANGE_TYPE}
 					icon={<Clock className='h-4 w-4 text-muted-foreground' />}
 				/>
@@ -88,10 +93,10 @@ function ObservabilityPage() {
 							? `${(metricsOverview.total_tokens / 1_000_000).toFixed(1)}M`
 							: `${METRIC_DISPLAY.TOKEN_USAGE.VALUE_MILLIONS}M`
 					}
-					changePercent={
-						metricsOverview?.change_percent ??
-						METRIC_DISPLAY.TOKEN_USAGE.CHANGE_PERCENT
-					}
+					changePercent={roundChange(
+						metricsOverview?.comparison?.change_percent.total_tokens ??
+							METRIC_DISPLAY.TOKEN_USAGE.CHANGE_PERCENT,
+					)}
 					changeType={METRIC_DISPLAY.TOKEN_USAGE.CHANGE_TYPE}
 					icon={<Zap className='h-4 w-4 text-muted-foreground' />}
 				/>
@@ -102,10 +107,10 @@ function ObservabilityPage() {
 							? `${metricsOverview.failure_rate.toFixed(2)}%`
 							: `${METRIC_DISPLAY.FAILURE_RATE.VALUE_PERCENT}%`
 					}
-					changePercent={
-						metricsOverview?.change_percent ??
-						METRIC_DISPLAY.FAILURE_RATE.CHANGE_PERCENT
-					}
+					changePercent={roundChange(
+						metricsOverview?.comparison?.change_percent.failure_rate ??
+							METRIC_DISPLAY.FAILURE_RATE.CHANGE_PERCENT,
+					)}
 					changeType={METRIC_DISPLAY.FAILURE_RATE.CHANGE_TYPE}
 					icon={<AlertTriangle className='h-4 w-4 text-muted-foreground' />}
 				/>